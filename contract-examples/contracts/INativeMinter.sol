@@ -3,6 +3,9 @@ pragma solidity >=0.6.2;
 import "./IAllowList.sol";
 
 interface INativeMinter is IAllowList {
+  // Grant the admin role to [newAdmin] and remove it from the caller in one call
+  function transferAdmin(address newAdmin) external;
+
   // Mint [amount] number of native coins and send to [addr]
   function mintNativeCoin(address addr, uint256 amount) external;
 }
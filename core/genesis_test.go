@@ -223,3 +223,57 @@ func TestStatefulPrecompilesConfigure(t *testing.T) {
 		})
 	}
 }
+
+func TestGenesisRejectsUnknownForfeitDestination(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RandomPartyConfig = precompile.RandomPartyConfig{
+		BlockTimestamp:     big.NewInt(0),
+		PhaseSeconds:       big.NewInt(3),
+		CommitStake:        big.NewInt(1000),
+		ForfeitDestination: precompile.ForfeitToRecipient + 1,
+	}
+	genesis := &Genesis{Config: &config}
+
+	_, err := SetupGenesisBlock(rawdb.NewMemoryDatabase(), genesis)
+	assert.ErrorIs(t, err, precompile.ErrUnknownForfeitDest)
+
+	config.RandomPartyConfig.ForfeitDestination = precompile.ForfeitToBurn
+	_, err = SetupGenesisBlock(rawdb.NewMemoryDatabase(), genesis)
+	assert.NoError(t, err)
+}
+
+func TestGenesisRejectsUnknownCommitHashAlgo(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RandomPartyConfig = precompile.RandomPartyConfig{
+		BlockTimestamp: big.NewInt(0),
+		PhaseSeconds:   big.NewInt(3),
+		CommitStake:    big.NewInt(1000),
+		CommitHashAlgo: precompile.CommitHashSHA256 + 1,
+	}
+	genesis := &Genesis{Config: &config}
+
+	_, err := SetupGenesisBlock(rawdb.NewMemoryDatabase(), genesis)
+	assert.ErrorIs(t, err, precompile.ErrUnknownCommitHash)
+
+	config.RandomPartyConfig.CommitHashAlgo = precompile.CommitHashSHA256
+	_, err = SetupGenesisBlock(rawdb.NewMemoryDatabase(), genesis)
+	assert.NoError(t, err)
+}
+
+func TestGenesisRejectsCommitFeeBelowMin(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RandomPartyConfig = precompile.RandomPartyConfig{
+		BlockTimestamp: big.NewInt(0),
+		PhaseSeconds:   big.NewInt(3),
+		CommitStake:    big.NewInt(999),
+		MinCommitFee:   big.NewInt(1000),
+	}
+	genesis := &Genesis{Config: &config}
+
+	_, err := SetupGenesisBlock(rawdb.NewMemoryDatabase(), genesis)
+	assert.ErrorIs(t, err, precompile.ErrCommitFeeBelowMin)
+
+	config.RandomPartyConfig.CommitStake = big.NewInt(1000)
+	_, err = SetupGenesisBlock(rawdb.NewMemoryDatabase(), genesis)
+	assert.NoError(t, err)
+}
@@ -217,6 +217,19 @@ func (s *StateDB) AddLog(log *types.Log) {
 	s.logSize++
 }
 
+// AddPrecompileLog adds a log emitted by the stateful precompile at [addr] to
+// the statedb
+// Note: blockNumber is a required argument because StateDB does not
+// know the current block number.
+func (s *StateDB) AddPrecompileLog(addr common.Address, topics []common.Hash, data []byte, blockNumber uint64) {
+	s.AddLog(&types.Log{
+		Address:     addr,
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: blockNumber,
+	})
+}
+
 func (s *StateDB) GetLogs(hash common.Hash, blockHash common.Hash) []*types.Log {
 	logs := s.logs[hash]
 	for _, l := range logs {
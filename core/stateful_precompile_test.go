@@ -25,6 +25,12 @@ type mockAccessibleState struct {
 
 func (m *mockAccessibleState) GetStateDB() precompile.StateDB { return m.state }
 func (m *mockAccessibleState) BlockTime() *big.Int            { return m.blockTime }
+func (m *mockAccessibleState) BlockNumber() *big.Int          { return common.Big0 }
+func (m *mockAccessibleState) BlockHash(uint64) common.Hash   { return common.Hash{} }
+
+func (m *mockAccessibleState) CallFromPrecompile(_, _ common.Address, _ []byte, gas uint64) ([]byte, uint64, error) {
+	return nil, gas, nil
+}
 
 // This test is added within the core package so that it can import all of the required code
 // without creating any import cycles
@@ -44,6 +50,7 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 
 	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
 	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	blockedCodeHash := crypto.Keccak256Hash([]byte{0x00})
 
 	for name, test := range map[string]test{
 		"set admin": {
@@ -228,6 +235,53 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 			readOnly:    true,
 			expectedErr: vmerrs.ErrOutOfGas.Error(),
 		},
+		"block bytecode": {
+			caller:         adminAddr,
+			precompileAddr: precompile.ContractDeployerAllowListAddress,
+			input: func() []byte {
+				return precompile.PackBlockBytecode(blockedCodeHash, true)
+			},
+			suppliedGas: precompile.ModifyAllowListGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.True(t, precompile.IsContractDeployerBytecodeBlocked(state, blockedCodeHash))
+				assert.False(t, precompile.IsContractDeployerBytecodeBlocked(state, common.Hash{}))
+			},
+		},
+		"unblock bytecode": {
+			caller:         adminAddr,
+			precompileAddr: precompile.ContractDeployerAllowListAddress,
+			input: func() []byte {
+				return precompile.PackBlockBytecode(blockedCodeHash, false)
+			},
+			suppliedGas: precompile.ModifyAllowListGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.False(t, precompile.IsContractDeployerBytecodeBlocked(state, blockedCodeHash))
+			},
+		},
+		"block bytecode from non-admin": {
+			caller:         noRoleAddr,
+			precompileAddr: precompile.ContractDeployerAllowListAddress,
+			input: func() []byte {
+				return precompile.PackBlockBytecode(blockedCodeHash, true)
+			},
+			suppliedGas: precompile.ModifyAllowListGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrCannotModifyAllowList.Error(),
+		},
+		"block bytecode with readOnly enabled": {
+			caller:         adminAddr,
+			precompileAddr: precompile.ContractDeployerAllowListAddress,
+			input: func() []byte {
+				return precompile.PackBlockBytecode(blockedCodeHash, true)
+			},
+			suppliedGas: precompile.ModifyAllowListGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			db := rawdb.NewMemoryDatabase()
@@ -313,7 +367,27 @@ func TestContractNativeMinterRun(t *testing.T) {
 				assert.Equal(t, precompile.AllowListEnabled, res)
 
 				assert.Equal(t, common.Big1, state.GetBalance(allowAddr), "expected minted funds")
+
+				logs := state.Logs()
+				assert.Equal(t, 1, len(logs))
+				assert.Equal(t, precompile.ContractNativeMinterAddress, logs[0].Address)
+				assert.Equal(t, []common.Hash{precompile.NativeCoinMintedTopic, allowAddr.Hash()}, logs[0].Topics)
+				assert.Equal(t, common.BigToHash(common.Big1).Bytes(), logs[0].Data)
+			},
+		},
+		"insufficient gas mint from allow address": {
+			caller:         allowAddr,
+			precompileAddr: precompile.ContractNativeMinterAddress,
+			input: func() []byte {
+				input, err := precompile.PackMintInput(allowAddr, common.Big1)
+				if err != nil {
+					panic(err)
+				}
+				return input
 			},
+			suppliedGas: precompile.MintGasCost - 1,
+			readOnly:    false,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
 		},
 		"mint funds from admin address": {
 			caller:         adminAddr,
@@ -512,7 +586,12 @@ func TestContractNativeMinterRun(t *testing.T) {
 	}
 }
 
-func createNewRandomState(t *testing.T) *state.StateDB {
+// This test uses a real StateDB because the reward recipient self-destructs
+// before the round is computed.
+func TestRandomPartyDeletedRecipient(t *testing.T) {
+	sponsor := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	recipient := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	preimage := common.Hash{0x1}
 	db := rawdb.NewMemoryDatabase()
 	state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
 	if err != nil {
@@ -520,295 +599,39 @@ func createNewRandomState(t *testing.T) *state.StateDB {
 	}
 	precompile.SetPhaseSeconds(state, big.NewInt(3))
 	precompile.SetCommitStake(state, big.NewInt(1000))
-	return state
-}
-
-func TestRandomParty(t *testing.T) {
-	anyAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
-	s := createNewRandomState(t)
-	s.AddBalance(anyAddr, big.NewInt(100000))
-
-	for _, test := range []struct {
-		name  string
-		btime *big.Int
-
-		input       func() []byte
-		suppliedGas uint64
-		value       *big.Int
-
-		expectedRes []byte
-		expectedErr string
-	}{
-		{
-			name:  "next",
-			btime: common.Big0,
-			input: func() []byte {
-				return precompile.NextSignature
-			},
-			suppliedGas: precompile.NextCost,
-			expectedRes: precompile.HBigBytes(common.Big0),
-		},
-		{
-			name:  "start party",
-			btime: big.NewInt(10),
-			input: func() []byte {
-				return precompile.StartSignature
-			},
-			suppliedGas: precompile.StartGasCost,
-			expectedRes: []byte{},
-		},
-		{
-			name:  "start party again",
-			btime: big.NewInt(10),
-			input: func() []byte {
-				return precompile.StartSignature
-			},
-			suppliedGas: precompile.StartGasCost,
-			expectedErr: precompile.ErrRandomPartyUnderway.Error(),
-		},
-		{
-			name:  "commit",
-			btime: big.NewInt(10),
-			value: big.NewInt(1000),
-			input: func() []byte {
-				preimage := common.BytesToHash([]byte{0x1}).Bytes()
-				return precompile.PackCommit(crypto.Keccak256Hash(preimage))
-			},
-			suppliedGas: precompile.CommitGasCost,
-			expectedRes: precompile.HBigBytes(common.Big0),
-		},
-		{
-			name:  "commit insufficient",
-			btime: big.NewInt(10),
-			value: big.NewInt(999),
-			input: func() []byte {
-				preimage := common.BytesToHash([]byte{0x2}).Bytes()
-				return precompile.PackCommit(crypto.Keccak256Hash(preimage))
-			},
-			suppliedGas: precompile.CommitGasCost,
-			expectedErr: precompile.ErrInsufficientFunds.Error(),
-		},
-		{
-			name:  "commit 2",
-			btime: big.NewInt(10),
-			value: big.NewInt(1001),
-			input: func() []byte {
-				preimage := common.BytesToHash([]byte{0x2}).Bytes()
-				return precompile.PackCommit(crypto.Keccak256Hash(preimage))
-			},
-			suppliedGas: precompile.CommitGasCost,
-			expectedRes: precompile.HBigBytes(common.Big1),
-		},
-		{
-			name:  "check reward before",
-			btime: big.NewInt(10),
-			input: func() []byte {
-				return precompile.RewardSignature
-			},
-			suppliedGas: precompile.RewardGasCost,
-			expectedRes: precompile.HBigBytes(common.Big0),
-		},
-		{
-			name:  "reveal",
-			btime: big.NewInt(10),
-			input: func() []byte {
-				return precompile.PackReveal(common.Big0, crypto.Keccak256Hash([]byte{0x1}))
-			},
-			suppliedGas: precompile.RevealGasCost,
-			expectedErr: precompile.ErrTooEarly.Error(),
-		},
-		{
-			name:  "sponsor",
-			btime: big.NewInt(11),
-			value: big.NewInt(10),
-			input: func() []byte {
-				return precompile.SponsorSignature
-			},
-			suppliedGas: precompile.SponsorGasCost,
-			expectedRes: []byte{},
-		},
-		{
-			name:  "check reward after",
-			btime: big.NewInt(10),
-			input: func() []byte {
-				return precompile.RewardSignature
-			},
-			suppliedGas: precompile.RewardGasCost,
-			expectedRes: precompile.HBigBytes(big.NewInt(10)),
-		},
-		{
-			name:  "commit later",
-			btime: big.NewInt(14),
-			input: func() []byte {
-				return precompile.PackCommit(crypto.Keccak256Hash([]byte{0x1}))
-			},
-			suppliedGas: precompile.CommitGasCost,
-			expectedErr: precompile.ErrTooLate.Error(),
-		},
-		{
-			name:  "reveal later",
-			btime: big.NewInt(14),
-			input: func() []byte {
-				return precompile.PackReveal(common.Big0, common.BytesToHash([]byte{0x1}))
-			},
-			suppliedGas: precompile.RevealGasCost,
-			expectedRes: []byte{},
-		},
-		{
-			name:  "duplicate reveal",
-			btime: big.NewInt(14),
-			input: func() []byte {
-				return precompile.PackReveal(common.Big0, common.BytesToHash([]byte{0x1}))
-			},
-			suppliedGas: precompile.RevealGasCost,
-			expectedErr: precompile.ErrDuplicateReveal.Error(),
-		},
-		{
-			name:  "compute early",
-			btime: big.NewInt(10),
-			input: func() []byte {
-				return precompile.ComputeSignature
-			},
-			suppliedGas: precompile.ComputeGasCost,
-			expectedErr: precompile.ErrTooEarly.Error(),
-		},
-		{
-			name:  "compute still early",
-			btime: big.NewInt(14),
-			input: func() []byte {
-				return precompile.ComputeSignature
-			},
-			suppliedGas: precompile.ComputeGasCost,
-			expectedErr: precompile.ErrTooEarly.Error(),
-		},
-		{
-			name:  "compute",
-			btime: big.NewInt(20),
-			input: func() []byte {
-				return precompile.ComputeSignature
-			},
-			suppliedGas: precompile.ComputeGasCost + precompile.ComputeItemCost + precompile.ComputeRewardCost,
-			expectedRes: []byte{},
-		},
-		{
-			name:  "result",
-			btime: big.NewInt(20),
-			input: func() []byte {
-				return precompile.PackResult(common.Big0)
-			},
-			suppliedGas: precompile.ResultCost,
-			expectedRes: crypto.Keccak256(common.BytesToHash([]byte{0x1}).Bytes()),
-		},
-		{
-			name:  "next",
-			btime: big.NewInt(20),
-			input: func() []byte {
-				return precompile.NextSignature
-			},
-			suppliedGas: precompile.NextCost,
-			expectedRes: common.BigToHash(big.NewInt(1)).Bytes(),
-		},
-		{
-			name:  "compute again",
-			btime: big.NewInt(20),
-			input: func() []byte {
-				return precompile.ComputeSignature
-			},
-			suppliedGas: precompile.ComputeGasCost + precompile.ComputeItemCost,
-			expectedErr: precompile.ErrNoRandomPartyStarted.Error(),
-		},
-		{
-			name:  "check reward before next party",
-			btime: big.NewInt(20),
-			input: func() []byte {
-				return precompile.RewardSignature
-			},
-			suppliedGas: precompile.RewardGasCost,
-			expectedErr: precompile.ErrNoRandomPartyStarted.Error(),
-		},
-		{
-			name:  "start second party",
-			btime: big.NewInt(20),
-			input: func() []byte {
-				return precompile.StartSignature
-			},
-			suppliedGas: precompile.StartGasCost + precompile.DeleteGasCost*3,
-			expectedRes: []byte{},
-		},
-		{
-			name:  "commit second party",
-			btime: big.NewInt(20),
-			value: big.NewInt(1001),
-			input: func() []byte {
-				preimage := common.BytesToHash([]byte{0x1}).Bytes()
-				return precompile.PackCommit(crypto.Keccak256Hash(preimage))
-			},
-			suppliedGas: precompile.CommitGasCost,
-			expectedRes: common.BigToHash(common.Big0).Bytes(),
-		},
-		{
-			name:  "check reward later",
-			btime: big.NewInt(21),
-			input: func() []byte {
-				return precompile.RewardSignature
-			},
-			suppliedGas: precompile.RewardGasCost,
-			expectedRes: precompile.HBigBytes(common.Big0),
-		},
-		{
-			name:  "reveal old key",
-			btime: big.NewInt(24),
-			input: func() []byte {
-				return precompile.PackReveal(big.NewInt(1), common.BytesToHash([]byte{0x2}))
-			},
-			suppliedGas: precompile.RevealGasCost,
-			expectedErr: "no hash with index 1",
-		},
-		{
-			name:  "start third party",
-			btime: big.NewInt(30),
-			input: func() []byte {
-				return precompile.StartSignature
-			},
-			suppliedGas: precompile.StartGasCost + precompile.DeleteGasCost,
-			expectedErr: precompile.ErrRandomPartyUnderway.Error(),
-		},
-		{
-			name:  "compute old party",
-			btime: big.NewInt(40),
-			input: func() []byte {
-				return precompile.ComputeSignature
-			},
-			suppliedGas: precompile.ComputeGasCost,
-			expectedRes: []byte{},
-		},
-		{
-			name:  "next after reset",
-			btime: big.NewInt(100),
-			input: func() []byte {
-				return precompile.NextSignature
-			},
-			suppliedGas: precompile.NextCost,
-			expectedRes: precompile.HBigBytes(big.NewInt(2)),
-		},
-	} {
-		t.Run(test.name, func(t *testing.T) {
-			ret, remainingGas, err := precompile.RandomPartyPrecompile.Run(&mockAccessibleState{blockTime: test.btime, state: s}, anyAddr, precompile.RandomPartyAddress, test.input(), test.suppliedGas, test.value, false)
-			if len(test.expectedErr) != 0 {
-				if err == nil {
-					assert.Failf(t, "run unexpectedly passed without error", "expected error %q", test.expectedErr)
-				} else {
-					assert.True(t, strings.Contains(err.Error(), test.expectedErr), "expected error (%s) to contain substring (%s)", err, test.expectedErr)
-				}
-				return
-			}
-
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			assert.Equal(t, uint64(0), remainingGas)
-			assert.Equal(t, test.expectedRes, ret)
-		})
+	state.AddBalance(sponsor, big.NewInt(100))
+	state.AddBalance(recipient, big.NewInt(1000))
+	state.SetCode(recipient, []byte{0x1})
+
+	run := func(caller common.Address, btime int64, input []byte, suppliedGas uint64, value *big.Int) {
+		state.SubBalance(caller, value)
+		state.AddBalance(precompile.RandomPartyAddress, value)
+		_, remainingGas, err := precompile.RandomPartyPrecompile.Run(&mockAccessibleState{state: state, blockTime: big.NewInt(btime)}, caller, precompile.RandomPartyAddress, input, suppliedGas, value, false)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), remainingGas)
 	}
+
+	run(recipient, 10, precompile.StartSignature, precompile.StartGasCost, common.Big0)
+	run(sponsor, 11, precompile.SponsorSignature, precompile.SponsorGasCost, big.NewInt(100))
+	run(recipient, 11, precompile.PackCommit(crypto.Keccak256Hash(preimage.Bytes())), precompile.CommitGasCost, big.NewInt(1000))
+	run(recipient, 14, precompile.PackReveal(common.Big0, preimage), precompile.RevealGasCost, common.Big0)
+	assert.Equal(t, big.NewInt(1000), state.GetBalance(recipient))
+
+	// The recipient self-destructs (sending its balance elsewhere) before the
+	// round is computed
+	state.Suicide(recipient)
+	state.Finalise(true)
+	assert.False(t, state.Exist(recipient))
+
+	logGasCost := 2 * precompile.LogGasCost(2, common.HashLength)
+	run(sponsor, 16, precompile.ComputeSignature, precompile.ComputeGasCost+precompile.ComputeItemCost+precompile.ComputeRewardCost+logGasCost, common.Big0)
+
+	// The account is re-created with the reward
+	assert.True(t, state.Exist(recipient))
+	assert.Equal(t, big.NewInt(100), state.GetBalance(recipient))
+	assert.Zero(t, state.GetBalance(precompile.RandomPartyAddress).Sign())
+
+	// The re-created account survives the end of the transaction
+	state.Finalise(true)
+	assert.Equal(t, big.NewInt(100), state.GetBalance(recipient))
 }
@@ -27,6 +27,12 @@ func (w *wrappedPrecompiledContract) Run(accessibleState precompile.PrecompileAc
 	return RunPrecompiledContract(w.p, input, suppliedGas)
 }
 
+// Selectors implements the StatefulPrecompiledContract interface (native precompiled contracts
+// do not use function selectors).
+func (w *wrappedPrecompiledContract) Selectors() [][]byte {
+	return nil
+}
+
 // RunStatefulPrecompiledContract confirms runs [precompile] with the specified parameters.
 func RunStatefulPrecompiledContract(precompile precompile.StatefulPrecompiledContract, accessibleState precompile.PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
 	return precompile.Run(accessibleState, caller, addr, input, suppliedGas, value, readOnly)
@@ -206,6 +206,23 @@ func (evm *EVM) BlockTime() *big.Int {
 	return evm.Context.Time
 }
 
+// BlockNumber returns the evm's context block number
+func (evm *EVM) BlockNumber() *big.Int {
+	return evm.Context.BlockNumber
+}
+
+// BlockHash returns the hash of block [number] (only the 256 most recent
+// ancestors of the current block are available)
+func (evm *EVM) BlockHash(number uint64) common.Hash {
+	return evm.Context.GetHash(number)
+}
+
+// CallFromPrecompile executes the contract at [addr] with [input] on behalf of
+// the stateful precompile at [caller] (without transferring any value)
+func (evm *EVM) CallFromPrecompile(caller, addr common.Address, input []byte, gas uint64) (ret []byte, leftOverGas uint64, err error) {
+	return evm.Call(AccountRef(caller), addr, input, gas, new(big.Int))
+}
+
 // Interpreter returns the current interpreter
 func (evm *EVM) Interpreter() *EVMInterpreter {
 	return evm.interpreter
@@ -497,6 +514,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		if !allowListRole.IsEnabled() {
 			return nil, common.Address{}, 0, fmt.Errorf("tx.origin %s is not authorized to deploy a contract", evm.TxContext.Origin)
 		}
+		// Reject init code that has been denylisted (the deployed code is checked once
+		// it is known below)
+		if codeHash := codeAndHash.Hash(); precompile.IsContractDeployerBytecodeBlocked(evm.StateDB, codeHash) {
+			return nil, common.Address{}, 0, fmt.Errorf("%w: init code hash %s", precompile.ErrBytecodeBlocked, codeHash)
+		}
 	}
 
 	// Create a new account on the state
@@ -534,6 +556,13 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		err = vmerrs.ErrInvalidCode
 	}
 
+	// Reject code that has been denylisted if the allow list is enabled.
+	if err == nil && evm.chainRules.IsContractDeployerAllowListEnabled {
+		if codeHash := crypto.Keccak256Hash(ret); precompile.IsContractDeployerBytecodeBlocked(evm.StateDB, codeHash) {
+			err = fmt.Errorf("%w: code hash %s", precompile.ErrBytecodeBlocked, codeHash)
+		}
+	}
+
 	// if the contract creation ran successfully and no errors were returned
 	// calculate the gas required to store the code. If the code could not
 	// be stored due to not enough gas set an error and let it be handled
@@ -0,0 +1,72 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestContractDeployerBytecodeDenylist(t *testing.T) {
+	deployer := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	// Init code that deploys the single byte runtime code 0x00 (STOP)
+	initCode := hexutil.MustDecode("0x600060005360016000f3")
+	runtimeCodeHash := crypto.Keccak256Hash([]byte{0x00})
+
+	config := *params.TestChainConfig
+	config.ContractDeployerAllowListConfig = precompile.ContractDeployerAllowListConfig{
+		AllowListConfig: precompile.AllowListConfig{BlockTimestamp: big.NewInt(0)},
+	}
+
+	for name, test := range map[string]struct {
+		blocked     []common.Hash
+		expectedErr error
+	}{
+		"not blocked": {},
+		"unrelated hash blocked": {
+			blocked: []common.Hash{{0x1}},
+		},
+		"init code blocked": {
+			blocked:     []common.Hash{crypto.Keccak256Hash(initCode)},
+			expectedErr: precompile.ErrBytecodeBlocked,
+		},
+		"runtime code blocked": {
+			blocked:     []common.Hash{runtimeCodeHash},
+			expectedErr: precompile.ErrBytecodeBlocked,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			precompile.SetContractDeployerAllowListStatus(statedb, deployer, precompile.AllowListEnabled)
+			for _, codeHash := range test.blocked {
+				precompile.SetContractDeployerBytecodeBlocked(statedb, codeHash, true)
+			}
+
+			vmctx := BlockContext{
+				CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+				Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+				BlockNumber: big.NewInt(0),
+				Time:        big.NewInt(0),
+			}
+			vmenv := NewEVM(vmctx, TxContext{Origin: deployer}, statedb, &config, Config{})
+
+			_, addr, _, err := vmenv.Create(AccountRef(deployer), initCode, 100_000, new(big.Int))
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("expected error %v but got %v", test.expectedErr, err)
+			}
+			if test.expectedErr == nil && statedb.GetCodeHash(addr) != runtimeCodeHash {
+				t.Fatalf("expected code hash %s but got %s", runtimeCodeHash, statedb.GetCodeHash(addr))
+			}
+		})
+	}
+}
@@ -81,6 +81,7 @@ type StateDB interface {
 	Snapshot() int
 
 	AddLog(*types.Log)
+	AddPrecompileLog(addr common.Address, topics []common.Hash, data []byte, blockNumber uint64)
 	AddPreimage(common.Hash, []byte)
 
 	ForEachStorage(common.Address, func(common.Hash, common.Hash) bool) error
@@ -10,6 +10,8 @@ import (
 
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // Enum constants for valid AllowListRole
@@ -26,26 +28,49 @@ var (
 	setNoneSignature       = CalculateFunctionSelector("setNone(address)")
 	readAllowListSignature = CalculateFunctionSelector("readAllowList(address)")
 
+	EnabledAddressesSignature = CalculateFunctionSelector("enabledAddresses(uint256,uint256)")
+	TransferAdminSignature    = CalculateFunctionSelector("transferAdmin(address)")
+
 	// Error returned when an invalid write is attempted
 	ErrCannotModifyAllowList = errors.New("non-admin cannot modify allow list")
 
+	// ErrInvalidAdminTransfer is returned when an admin attempts to transfer
+	// the admin role to themselves or to the zero address (either of which
+	// would leave the allow list without the caller's admin)
+	ErrInvalidAdminTransfer = errors.New("invalid admin transfer")
+
 	allowListInputLen = common.HashLength
+
+	// namespacedRolePrefix namespaces the roles of precompiles that also store
+	// their own state (see [allowListRoleKey])
+	namespacedRolePrefix = []byte("allowListRole")
+
+	// enabledSetPrefix and enabledIndexPrefix namespace the enumerable set of
+	// addresses with a role (so it never collides with the roles, which are
+	// keyed by address)
+	enabledSetPrefix   = []byte("enabledSet")
+	enabledIndexPrefix = []byte("enabledIndex")
 )
 
 // AllowListConfig specifies the configuration of the allow list.
-// Specifies the block timestamp at which it goes into effect as well as the initial set of allow list admins.
+// Specifies the block timestamp at which it goes into effect as well as the initial set of allow list admins
+// and enabled addresses.
 type AllowListConfig struct {
 	BlockTimestamp *big.Int `json:"blockTimestamp"`
 
-	AllowListAdmins []common.Address `json:"adminAddresses"`
+	AllowListAdmins  []common.Address `json:"adminAddresses"`
+	EnabledAddresses []common.Address `json:"enabledAddresses,omitempty"`
 }
 
 // Timestamp returns the timestamp at which the allow list should be enabled
 func (c *AllowListConfig) Timestamp() *big.Int { return c.BlockTimestamp }
 
 // Configure initializes the address space of [precompileAddr] by initializing the role of each of
-// the addresses in [AllowListAdmins].
+// the addresses in [EnabledAddresses] and [AllowListAdmins] (an address in both is an admin).
 func (c *AllowListConfig) Configure(state StateDB, precompileAddr common.Address) {
+	for _, enabledAddr := range c.EnabledAddresses {
+		setAllowListRole(state, precompileAddr, enabledAddr, AllowListEnabled)
+	}
 	for _, adminAddr := range c.AllowListAdmins {
 		setAllowListRole(state, precompileAddr, adminAddr, AllowListAdmin)
 	}
@@ -95,18 +120,109 @@ func (s AllowListRole) IsEnabled() bool {
 // at [precompileAddr]
 func getAllowListStatus(state StateDB, precompileAddr common.Address, address common.Address) AllowListRole {
 	// Generate the state key for [address]
-	addressKey := address.Hash()
+	addressKey := allowListRoleKey(precompileAddr, address)
 	return AllowListRole(state.GetState(precompileAddr, addressKey))
 }
 
+// allowListRoleKey returns the storage key of the role of [address] on the
+// allow list of the precompile at [precompileAddr].
+//
+// Roles are stored at the hash of [address], except for the Random Party,
+// which stores its own state under short keys that equal the hashes of low
+// addresses (e.g. the key of its commit deadline is the hash of 0x…01). Its
+// roles are namespaced behind a hashed prefix so they can never overwrite
+// (or be read from) that state.
+func allowListRoleKey(precompileAddr, address common.Address) common.Hash {
+	if precompileAddr == RandomPartyAddress {
+		return crypto.Keccak256Hash(namespacedRolePrefix, address.Bytes())
+	}
+	return address.Hash()
+}
+
+// requireRole returns [errNoRole] (wrapped with [address]) unless [address] has
+// at least [minRole] on the allow list of the precompile at [precompileAddr]
+// (admins are also enabled). An invalid [minRole] is never satisfied.
+//
+// Note: there is no Manager role, so the only levels are [AllowListNoRole],
+// [AllowListEnabled], and [AllowListAdmin].
+func requireRole(state StateDB, precompileAddr, address common.Address, minRole AllowListRole, errNoRole error) error {
+	role := getAllowListStatus(state, precompileAddr, address)
+	switch {
+	case minRole == AllowListNoRole:
+		return nil
+	case minRole == AllowListEnabled && role.IsEnabled():
+		return nil
+	case minRole == AllowListAdmin && role.IsAdmin():
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", errNoRole, address)
+	}
+}
+
 // setAllowListRole sets the permissions of [address] to [role] for the precompile
-// at [precompileAddr].
+// at [precompileAddr] (adding [address] to or removing it from the set of
+// enabled addresses if necessary).
 // assumes [role] has already been verified as valid.
+//
+// Membership of the set is decided by the set itself rather than the previous
+// role, so addresses granted a role before the set existed are added once
+// they are granted a role again.
 func setAllowListRole(stateDB StateDB, precompileAddr, address common.Address, role AllowListRole) {
 	// Generate the state key for [address]
-	addressKey := address.Hash()
+	addressKey := allowListRoleKey(precompileAddr, address)
 	// Assign [role] to the address
 	stateDB.SetState(precompileAddr, addressKey, common.Hash(role))
+
+	inSet := stateDB.GetState(precompileAddr, enabledIndexKey(address)) != (common.Hash{})
+	switch {
+	case role.IsEnabled() && !inSet:
+		addEnabledAddress(stateDB, precompileAddr, address)
+	case !role.IsEnabled() && inSet:
+		removeEnabledAddress(stateDB, precompileAddr, address)
+	}
+}
+
+// enabledSetKey returns the storage key of entry [idx] of the set of enabled
+// addresses (the size of the set is stored at the key of a nil [idx]).
+func enabledSetKey(idx *big.Int) common.Hash {
+	if idx == nil {
+		return crypto.Keccak256Hash(enabledSetPrefix)
+	}
+	return crypto.Keccak256Hash(enabledSetPrefix, common.BigToHash(idx).Bytes())
+}
+
+// enabledIndexKey returns the storage key of the position of [address] in
+// the set of enabled addresses (offset by 1, so 0 means [address] is not in
+// the set).
+func enabledIndexKey(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(enabledIndexPrefix, address.Bytes())
+}
+
+func addEnabledAddress(stateDB StateDB, precompileAddr, address common.Address) {
+	size := stateDB.GetState(precompileAddr, enabledSetKey(nil)).Big()
+	stateDB.SetState(precompileAddr, enabledSetKey(size), address.Hash())
+	newSize := new(big.Int).Add(size, common.Big1)
+	stateDB.SetState(precompileAddr, enabledIndexKey(address), common.BigToHash(newSize))
+	stateDB.SetState(precompileAddr, enabledSetKey(nil), common.BigToHash(newSize))
+}
+
+// removeEnabledAddress removes [address] from the set of enabled addresses by
+// moving the last entry of the set into its position.
+func removeEnabledAddress(stateDB StateDB, precompileAddr, address common.Address) {
+	position := stateDB.GetState(precompileAddr, enabledIndexKey(address)).Big()
+	if position.Sign() == 0 {
+		return
+	}
+	idx := new(big.Int).Sub(position, common.Big1)
+	last := new(big.Int).Sub(stateDB.GetState(precompileAddr, enabledSetKey(nil)).Big(), common.Big1)
+	if idx.Cmp(last) != 0 {
+		lastAddress := stateDB.GetState(precompileAddr, enabledSetKey(last))
+		stateDB.SetState(precompileAddr, enabledSetKey(idx), lastAddress)
+		stateDB.SetState(precompileAddr, enabledIndexKey(common.BytesToAddress(lastAddress.Bytes())), common.BigToHash(position))
+	}
+	stateDB.SetState(precompileAddr, enabledSetKey(last), common.Hash{})
+	stateDB.SetState(precompileAddr, enabledIndexKey(address), common.Hash{})
+	stateDB.SetState(precompileAddr, enabledSetKey(nil), common.BigToHash(last))
 }
 
 // PackModifyAllowList packs [address] and [role] into the appropriate arguments for modifying the allow list.
@@ -139,16 +255,51 @@ func PackReadAllowList(address common.Address) []byte {
 	return input
 }
 
+// PackEnabledAddresses packs [offset] and [limit] into the input data to the
+// enabledAddresses function
+func PackEnabledAddresses(offset *big.Int, limit *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength*2)
+	input = append(input, EnabledAddressesSignature...)
+	input = append(input, common.BigToHash(offset).Bytes()...)
+	input = append(input, common.BigToHash(limit).Bytes()...)
+	return input
+}
+
+// PackTransferAdmin packs [address] into the input data to the transferAdmin
+// function
+func PackTransferAdmin(address common.Address) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, TransferAdminSignature...)
+	input = append(input, address.Hash().Bytes()...)
+	return input
+}
+
+// UnpackEnabledAddresses attempts to unpack [input] into the offset and limit
+// arguments of the enabledAddresses function
+func UnpackEnabledAddresses(input []byte) (*big.Int, *big.Int, error) {
+	if len(input) != common.HashLength*2 {
+		return nil, nil, invalidInputLength("enabledAddresses", common.HashLength*2, len(input))
+	}
+	return new(big.Int).SetBytes(input[:common.HashLength]), new(big.Int).SetBytes(input[common.HashLength:]), nil
+}
+
 // createAllowListRoleSetter returns an execution function for setting the allow list status of the input address argument to [role].
 // This execution function is speciifc to [precompileAddr].
 func createAllowListRoleSetter(precompileAddr common.Address, role AllowListRole) RunStatefulPrecompileFunc {
+	method := "setNone"
+	switch role {
+	case AllowListAdmin:
+		method = "setAdmin"
+	case AllowListEnabled:
+		method = "setEnabled"
+	}
 	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
 		if remainingGas, err = deductGas(suppliedGas, ModifyAllowListGasCost); err != nil {
 			return nil, 0, err
 		}
 
 		if len(input) != allowListInputLen {
-			return nil, remainingGas, fmt.Errorf("invalid input length for modifying allow list: %d", len(input))
+			return nil, remainingGas, invalidInputLength(method, allowListInputLen, len(input))
 		}
 
 		modifyAddress := common.BytesToAddress(input)
@@ -158,9 +309,8 @@ func createAllowListRoleSetter(precompileAddr common.Address, role AllowListRole
 		}
 
 		// Verify that the caller is in the allow list and therefore has the right to modify it
-		callerStatus := getAllowListStatus(evm.GetStateDB(), precompileAddr, callerAddr)
-		if !callerStatus.IsAdmin() {
-			return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotModifyAllowList, callerAddr)
+		if err := requireRole(evm.GetStateDB(), precompileAddr, callerAddr, AllowListAdmin, ErrCannotModifyAllowList); err != nil {
+			return nil, remainingGas, err
 		}
 
 		setAllowListRole(evm.GetStateDB(), precompileAddr, modifyAddress, role)
@@ -169,6 +319,40 @@ func createAllowListRoleSetter(precompileAddr common.Address, role AllowListRole
 	}
 }
 
+// createAllowListAdminTransfer returns an execution function that grants the admin role to the input address
+// argument and removes the role of the caller in a single call, so the allow list for [precompileAddr] is never
+// left without an admin part way through a hand over. The caller must be an admin and cannot transfer the role to
+// itself or to the zero address.
+func createAllowListAdminTransfer(precompileAddr common.Address) RunStatefulPrecompileFunc {
+	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, TransferAdminGasCost); err != nil {
+			return nil, 0, err
+		}
+
+		if len(input) != allowListInputLen {
+			return nil, remainingGas, invalidInputLength("transferAdmin", allowListInputLen, len(input))
+		}
+
+		newAdmin := common.BytesToAddress(input)
+
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+
+		stateDB := evm.GetStateDB()
+		if err := requireRole(stateDB, precompileAddr, callerAddr, AllowListAdmin, ErrCannotModifyAllowList); err != nil {
+			return nil, remainingGas, err
+		}
+		if newAdmin == callerAddr || newAdmin == (common.Address{}) {
+			return nil, remainingGas, fmt.Errorf("%w: %s", ErrInvalidAdminTransfer, newAdmin)
+		}
+
+		setAllowListRole(stateDB, precompileAddr, newAdmin, AllowListAdmin)
+		setAllowListRole(stateDB, precompileAddr, callerAddr, AllowListNoRole)
+		return []byte{}, remainingGas, nil
+	}
+}
+
 // createReadAllowList returns an execution function that reads the allow list for the given [precompileAddr].
 // The execution function parses the input into a single address and returns the 32 byte hash that specifies the
 // designated role of that address
@@ -179,7 +363,7 @@ func createReadAllowList(precompileAddr common.Address) RunStatefulPrecompileFun
 		}
 
 		if len(input) != allowListInputLen {
-			return nil, remainingGas, fmt.Errorf("invalid input length for read allow list: %d", len(input))
+			return nil, remainingGas, invalidInputLength("readAllowList", allowListInputLen, len(input))
 		}
 
 		readAddress := common.BytesToAddress(input)
@@ -189,14 +373,46 @@ func createReadAllowList(precompileAddr common.Address) RunStatefulPrecompileFun
 	}
 }
 
+// createEnabledAddresses returns an execution function that enumerates the addresses with a role (enabled or
+// admin) on the allow list for the given [precompileAddr]. The execution function returns up to [limit] addresses
+// starting at [offset] as an ABI encoded address[] (removing an address moves the last address into its position,
+// so the order of the set is not stable).
+//
+// Note: addresses granted a role before the set of enabled addresses was tracked are only listed once they are
+// granted a role again.
+func createEnabledAddresses(precompileAddr common.Address) RunStatefulPrecompileFunc {
+	return func(evm PrecompileAccessibleState, callerAddr common.Address, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, EnabledAddressesGasCost); err != nil {
+			return nil, 0, err
+		}
+
+		offset, limit, err := UnpackEnabledAddresses(input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		stateDB := evm.GetStateDB()
+		end := math.BigMin(new(big.Int).Add(offset, limit), stateDB.GetState(precompileAddr, enabledSetKey(nil)).Big())
+		var addrs []common.Address
+		for i := offset; i.Cmp(end) < 0; i = new(big.Int).Add(i, common.Big1) {
+			if remainingGas, err = deductGas(remainingGas, EnabledAddressesItemCost); err != nil {
+				return nil, 0, err
+			}
+			addrs = append(addrs, common.BytesToAddress(stateDB.GetState(precompileAddr, enabledSetKey(i)).Bytes()))
+		}
+		return packAddressArray(addrs), remainingGas, nil
+	}
+}
+
 // createAllowListPrecompile returns a StatefulPrecompiledContract with R/W control of an allow list at [precompileAddr]
 func createAllowListPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
 	setAdmin := newStatefulPrecompileFunction(setAdminSignature, createAllowListRoleSetter(precompileAddr, AllowListAdmin))
 	setEnabled := newStatefulPrecompileFunction(setEnabledSignature, createAllowListRoleSetter(precompileAddr, AllowListEnabled))
 	setNone := newStatefulPrecompileFunction(setNoneSignature, createAllowListRoleSetter(precompileAddr, AllowListNoRole))
 	read := newStatefulPrecompileFunction(readAllowListSignature, createReadAllowList(precompileAddr))
+	enabled := newStatefulPrecompileFunction(EnabledAddressesSignature, createEnabledAddresses(precompileAddr))
 
 	// Construct the contract with no fallback function.
-	contract := newStatefulPrecompileWithFunctionSelectors(nil, []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read})
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read, enabled})
 	return contract
 }
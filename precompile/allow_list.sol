@@ -17,4 +17,9 @@ interface AllowListInterface {
 
     // Read the status of [addr]
     function readAllowList(address addr) external view returns (uint256);
+
+    // Query up to [limit] addresses with a role (enabled or admin), starting
+    // at [offset] (addresses granted a role before this function was added
+    // are only listed once they are granted a role again)
+    function enabledAddresses(uint256 offset, uint256 limit) external view returns (address[] memory);
 }
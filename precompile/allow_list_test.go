@@ -0,0 +1,169 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/assert"
+)
+
+func TestAllowListConfigConfigure(t *testing.T) {
+	admin := common.Address{0x1}
+	enabled := common.Address{0x2}
+	both := common.Address{0x3}
+	none := common.Address{0x4}
+	config := AllowListConfig{
+		AllowListAdmins:  []common.Address{admin, both},
+		EnabledAddresses: []common.Address{enabled, both},
+	}
+
+	for _, precompileAddr := range []common.Address{ContractDeployerAllowListAddress, ContractNativeMinterAddress} {
+		state := newCountingStateDB()
+		config.Configure(state, precompileAddr)
+
+		assert.Equal(t, getAllowListStatus(state, precompileAddr, admin), AllowListAdmin)
+		assert.Equal(t, getAllowListStatus(state, precompileAddr, enabled), AllowListEnabled)
+		assert.Equal(t, getAllowListStatus(state, precompileAddr, both), AllowListAdmin)
+		assert.Equal(t, getAllowListStatus(state, precompileAddr, none), AllowListNoRole)
+	}
+
+	// Roles are applied by the configs that embed [AllowListConfig]
+	state := newCountingStateDB()
+	(&ContractDeployerAllowListConfig{AllowListConfig: config}).Configure(state)
+	assert.Equal(t, GetContractDeployerAllowListStatus(state, enabled), AllowListEnabled)
+	(&ContractNativeMinterConfig{AllowListConfig: config}).Configure(state)
+	assert.Equal(t, GetContractNativeMinterStatus(state, enabled), AllowListEnabled)
+	assert.Equal(t, GetContractNativeMinterStatus(state, admin), AllowListAdmin)
+	(&RandomPartyConfig{
+		PhaseSeconds:     big.NewInt(1),
+		CommitStake:      big.NewInt(1),
+		AllowListAdmins:  config.AllowListAdmins,
+		EnabledAddresses: config.EnabledAddresses,
+	}).Configure(state)
+	assert.Equal(t, GetRandomPartyStatus(state, enabled), AllowListEnabled)
+	assert.Equal(t, GetRandomPartyStatus(state, both), AllowListAdmin)
+}
+
+func TestRequireRole(t *testing.T) {
+	admin := common.Address{0x1}
+	enabled := common.Address{0x2}
+	none := common.Address{0x3}
+	errNoRole := errors.New("no role")
+	// There is no Manager role, so the value other allow lists use for it is
+	// invalid (it can neither be granted nor required)
+	invalidRole := AllowListRole(common.BigToHash(big.NewInt(3)))
+	assert.Assert(t, !invalidRole.Valid())
+	_, err := PackModifyAllowList(admin, invalidRole)
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "invalid role"), err)
+
+	for _, precompileAddr := range []common.Address{ContractDeployerAllowListAddress, ContractNativeMinterAddress, RandomPartyAddress} {
+		state := newCountingStateDB()
+		(&AllowListConfig{
+			AllowListAdmins:  []common.Address{admin},
+			EnabledAddresses: []common.Address{enabled},
+		}).Configure(state, precompileAddr)
+
+		for _, test := range []struct {
+			address common.Address
+			minRole AllowListRole
+			allowed bool
+		}{
+			{admin, AllowListNoRole, true},
+			{admin, AllowListEnabled, true},
+			{admin, AllowListAdmin, true},
+			{enabled, AllowListNoRole, true},
+			{enabled, AllowListEnabled, true},
+			{enabled, AllowListAdmin, false},
+			{none, AllowListNoRole, true},
+			{none, AllowListEnabled, false},
+			{none, AllowListAdmin, false},
+			// An invalid role is never satisfied
+			{admin, invalidRole, false},
+		} {
+			err := requireRole(state, precompileAddr, test.address, test.minRole, errNoRole)
+			if test.allowed {
+				assert.NilError(t, err)
+				continue
+			}
+			assert.Assert(t, errors.Is(err, errNoRole))
+			assert.Assert(t, strings.Contains(err.Error(), test.address.String()))
+		}
+	}
+}
+
+func TestAllowListEnabledAddresses(t *testing.T) {
+	admin := common.Address{0x1}
+	addrs := []common.Address{{0x2}, {0x3}, {0x4}}
+	state := newCountingStateDB()
+	(&AllowListConfig{AllowListAdmins: []common.Address{admin}}).Configure(state, ContractDeployerAllowListAddress)
+
+	run := func(input []byte, suppliedGas uint64) ([]byte, error) {
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		ret, _, err := ContractDeployerAllowListPrecompile.Run(accessibleState, admin, ContractDeployerAllowListAddress, input, suppliedGas, common.Big0, false)
+		return ret, err
+	}
+	setRole := func(addr common.Address, role AllowListRole) {
+		input, err := PackModifyAllowList(addr, role)
+		assert.NilError(t, err)
+		_, err = run(input, ModifyAllowListGasCost)
+		assert.NilError(t, err)
+	}
+	enabled := func(offset int64, limit int64, expected ...common.Address) {
+		t.Helper()
+		ret, err := run(PackEnabledAddresses(big.NewInt(offset), big.NewInt(limit)), EnabledAddressesGasCost+uint64(len(expected))*EnabledAddressesItemCost)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, ret, packAddressArray(expected))
+	}
+
+	enabled(0, 10, admin)
+	for _, addr := range addrs {
+		setRole(addr, AllowListEnabled)
+	}
+	enabled(0, 10, admin, addrs[0], addrs[1], addrs[2])
+	enabled(1, 2, addrs[0], addrs[1])
+	enabled(3, 2, addrs[2])
+	enabled(4, 2)
+
+	// Changing the role of an address with a role does not change the set
+	setRole(addrs[1], AllowListAdmin)
+	enabled(0, 10, admin, addrs[0], addrs[1], addrs[2])
+
+	// Revoking moves the last address into the position of the revoked one
+	setRole(addrs[0], AllowListNoRole)
+	enabled(0, 10, admin, addrs[2], addrs[1])
+	setRole(addrs[1], AllowListNoRole)
+	enabled(0, 10, admin, addrs[2])
+	// Revoking an address without a role is a no-op
+	setRole(addrs[1], AllowListNoRole)
+	enabled(0, 10, admin, addrs[2])
+
+	// Granting again appends to the set
+	setRole(addrs[0], AllowListEnabled)
+	enabled(0, 10, admin, addrs[2], addrs[0])
+
+	// A role granted before the set was tracked is only listed once it is
+	// granted again
+	legacy := common.Address{0x5}
+	state.SetState(ContractDeployerAllowListAddress, allowListRoleKey(ContractDeployerAllowListAddress, legacy), common.Hash(AllowListEnabled))
+	enabled(0, 10, admin, addrs[2], addrs[0])
+	setRole(legacy, AllowListEnabled)
+	enabled(0, 10, admin, addrs[2], addrs[0], legacy)
+	setRole(legacy, AllowListNoRole)
+	enabled(0, 10, admin, addrs[2], addrs[0])
+
+	// Admins are removed like any other address
+	setRole(admin, AllowListNoRole)
+	enabled(0, 10, addrs[0], addrs[2])
+
+	// Each returned address is charged for
+	_, err := run(PackEnabledAddresses(common.Big0, big.NewInt(10)), EnabledAddressesGasCost+EnabledAddressesItemCost)
+	assert.Assert(t, err != nil)
+	_, err = run(append(EnabledAddressesSignature[:selectorLen:selectorLen], 0x1), EnabledAddressesGasCost)
+	assert.Assert(t, err != nil)
+}
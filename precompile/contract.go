@@ -4,8 +4,10 @@
 package precompile
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -20,6 +22,12 @@ type RunStatefulPrecompileFunc func(accessibleState PrecompileAccessibleState, c
 type PrecompileAccessibleState interface {
 	GetStateDB() StateDB
 	BlockTime() *big.Int
+	BlockNumber() *big.Int
+	BlockHash(number uint64) common.Hash
+	// CallFromPrecompile calls [addr] with [input] and at most [gas] on behalf
+	// of the precompile at [caller] (without transferring value), reverting
+	// any changes made by the call if it fails
+	CallFromPrecompile(caller, addr common.Address, input []byte, gas uint64) (ret []byte, leftOverGas uint64, err error)
 }
 
 // StateDB is the interface for accessing EVM state
@@ -38,12 +46,17 @@ type StateDB interface {
 
 	CreateAccount(common.Address)
 	Exist(common.Address) bool
+
+	AddPrecompileLog(addr common.Address, topics []common.Hash, data []byte, blockNumber uint64)
 }
 
 // StatefulPrecompiledContract is the interface for executing a precompiled contract
 type StatefulPrecompiledContract interface {
 	// Run executes the precompiled contract.
 	Run(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error)
+	// Selectors returns the 4 byte function selectors supported by the precompiled contract
+	// in ascending order.
+	Selectors() [][]byte
 }
 
 // statefulPrecompileFunction defines a function implemented by a stateful precompile
@@ -118,3 +131,16 @@ func (s *statefulPrecompileWithFunctionSelectors) Run(accessibleState Precompile
 
 	return function.execute(accessibleState, caller, addr, functionInput, suppliedGas, value, readOnly)
 }
+
+// Selectors returns the 4 byte function selectors of [functions] in ascending order.
+// Note: the fallback function is not included because it does not have a selector.
+func (s *statefulPrecompileWithFunctionSelectors) Selectors() [][]byte {
+	selectors := make([][]byte, 0, len(s.functions))
+	for selector := range s.functions {
+		selectors = append(selectors, []byte(selector))
+	}
+	sort.Slice(selectors, func(i, j int) bool {
+		return bytes.Compare(selectors[i], selectors[j]) < 0
+	})
+	return selectors
+}
@@ -3,12 +3,30 @@
 
 package precompile
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
 
 var (
 	_ StatefulPrecompileConfig = &ContractDeployerAllowListConfig{}
 	// Singleton StatefulPrecompiledContract for W/R access to the contract deployer allow list.
-	ContractDeployerAllowListPrecompile StatefulPrecompiledContract = createAllowListPrecompile(ContractDeployerAllowListAddress)
+	ContractDeployerAllowListPrecompile StatefulPrecompiledContract = createContractDeployerAllowListPrecompile(ContractDeployerAllowListAddress)
+
+	// Bytecode denylist function signatures
+	blockBytecodeSignature   = CalculateFunctionSelector("blockBytecode(bytes32)")
+	unblockBytecodeSignature = CalculateFunctionSelector("unblockBytecode(bytes32)")
+
+	// Error returned when the code hash of a contract being deployed is denylisted
+	ErrBytecodeBlocked = errors.New("bytecode is blocked")
+
+	// blockedBytecodePrefix namespaces the bytecode denylist (so it never collides
+	// with the allow list, which is keyed by address)
+	blockedBytecodePrefix = []byte("blockedBytecode")
 )
 
 // ContractDeployerAllowListConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
@@ -44,3 +62,87 @@ func GetContractDeployerAllowListStatus(stateDB StateDB, address common.Address)
 func SetContractDeployerAllowListStatus(stateDB StateDB, address common.Address, role AllowListRole) {
 	setAllowListRole(stateDB, ContractDeployerAllowListAddress, address, role)
 }
+
+// blockedBytecodeKey returns the state key that marks [codeHash] as blocked.
+func blockedBytecodeKey(codeHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(blockedBytecodePrefix, codeHash.Bytes())
+}
+
+// IsContractDeployerBytecodeBlocked returns true if contracts with the code hash [codeHash]
+// cannot be deployed.
+func IsContractDeployerBytecodeBlocked(stateDB StateDB, codeHash common.Hash) bool {
+	return stateDB.GetState(ContractDeployerAllowListAddress, blockedBytecodeKey(codeHash)) != (common.Hash{})
+}
+
+// SetContractDeployerBytecodeBlocked sets whether contracts with the code hash [codeHash]
+// can be deployed.
+func SetContractDeployerBytecodeBlocked(stateDB StateDB, codeHash common.Hash, blocked bool) {
+	val := common.Hash{}
+	if blocked {
+		val = common.BigToHash(common.Big1)
+	}
+	stateDB.SetState(ContractDeployerAllowListAddress, blockedBytecodeKey(codeHash), val)
+}
+
+// PackBlockBytecode packs [codeHash] into the input data to the block or unblock bytecode
+// function (depending on [blocked]).
+func PackBlockBytecode(codeHash common.Hash, blocked bool) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	if blocked {
+		input = append(input, blockBytecodeSignature...)
+	} else {
+		input = append(input, unblockBytecodeSignature...)
+	}
+	input = append(input, codeHash.Bytes()...)
+	return input
+}
+
+// createBytecodeBlocker returns an execution function that sets whether the code hash in the
+// input can be deployed to [blocked]. Only admins of the allow list can modify the denylist.
+func createBytecodeBlocker(blocked bool) RunStatefulPrecompileFunc {
+	method := "unblockBytecode"
+	if blocked {
+		method = "blockBytecode"
+	}
+	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, ModifyAllowListGasCost); err != nil {
+			return nil, 0, err
+		}
+
+		if len(input) != common.HashLength {
+			return nil, remainingGas, invalidInputLength(method, common.HashLength, len(input))
+		}
+
+		codeHash := common.BytesToHash(input)
+
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+
+		// Verify that the caller is an admin and therefore has the right to modify the denylist
+		if err := requireRole(evm.GetStateDB(), ContractDeployerAllowListAddress, callerAddr, AllowListAdmin, ErrCannotModifyAllowList); err != nil {
+			return nil, remainingGas, err
+		}
+
+		SetContractDeployerBytecodeBlocked(evm.GetStateDB(), codeHash, blocked)
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
+
+// createContractDeployerAllowListPrecompile returns a StatefulPrecompiledContract with R/W control of an allow
+// list and a bytecode denylist at [precompileAddr].
+func createContractDeployerAllowListPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
+	setAdmin := newStatefulPrecompileFunction(setAdminSignature, createAllowListRoleSetter(precompileAddr, AllowListAdmin))
+	setEnabled := newStatefulPrecompileFunction(setEnabledSignature, createAllowListRoleSetter(precompileAddr, AllowListEnabled))
+	setNone := newStatefulPrecompileFunction(setNoneSignature, createAllowListRoleSetter(precompileAddr, AllowListNoRole))
+	read := newStatefulPrecompileFunction(readAllowListSignature, createReadAllowList(precompileAddr))
+	enabled := newStatefulPrecompileFunction(EnabledAddressesSignature, createEnabledAddresses(precompileAddr))
+
+	blockBytecode := newStatefulPrecompileFunction(blockBytecodeSignature, createBytecodeBlocker(true))
+	unblockBytecode := newStatefulPrecompileFunction(unblockBytecodeSignature, createBytecodeBlocker(false))
+
+	// Construct the contract with no fallback function.
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read, enabled, blockBytecode, unblockBytecode})
+	return contract
+}
@@ -0,0 +1,33 @@
+// (c) 2022-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: MIT
+
+pragma solidity >=0.8.0;
+
+interface ContractDeployerAllowListInterface {
+    // Set [addr] to have the admin role over the allow list
+    function setAdmin(address addr) external;
+
+    // Set [addr] to be enabled on the allow list
+    function setEnabled(address addr) external;
+
+    // Set [addr] to have no role over the allow list
+    function setNone(address addr) external;
+
+    // Read the status of [addr]
+    function readAllowList(address addr) external view returns (uint256);
+
+    // Query up to [limit] addresses with a role (enabled or admin), starting
+    // at [offset] (addresses granted a role before this function was added
+    // are only listed once they are granted a role again)
+    function enabledAddresses(uint256 offset, uint256 limit) external view returns (address[] memory);
+
+    // Prevent contracts whose init code or deployed code hashes to [codeHash]
+    // from being deployed (only callable by admins)
+    function blockBytecode(bytes32 codeHash) external;
+
+    // Allow contracts whose code hashes to [codeHash] to be deployed again
+    // (only callable by admins)
+    function unblockBytecode(bytes32 codeHash) external;
+}
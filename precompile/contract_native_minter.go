@@ -4,12 +4,15 @@
 package precompile
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 var (
@@ -19,15 +22,37 @@ var (
 
 	mintSignature = CalculateFunctionSelector("mintNativeCoin(address,uint256)") // address, amount
 
+	// NativeCoinMinted(address indexed addr, uint256 amount) is emitted for each mint
+	NativeCoinMintedTopic = CalculateEventTopic("NativeCoinMinted(address,uint256)")
+
 	ErrCannotMint = errors.New("non-enabled cannot mint")
 
+	// ErrAdminCannotMint is returned when an admin mints while
+	// [SeparateMintRole] is set.
+	ErrAdminCannotMint = errors.New("admin cannot mint")
+
 	mintInputLen = common.HashLength + common.HashLength
+
+	// mintedPrefix namespaces the cumulative amount minted by each minter (so
+	// it never collides with the allow list, which is keyed by address)
+	mintedPrefix = []byte("minted")
+
+	// separateMintRoleKey stores whether [SeparateMintRole] is set
+	separateMintRoleKey = crypto.Keccak256Hash([]byte("separateMintRole"))
 )
 
 // ContractNativeMinterConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the contract deployer specific precompile address.
 type ContractNativeMinterConfig struct {
 	AllowListConfig
+
+	// InitialMint is minted to each address when the precompile is configured.
+	InitialMint map[common.Address]*big.Int `json:"initialMint,omitempty"`
+
+	// SeparateMintRole splits the capabilities of the allow list roles so that
+	// admins can only manage the allow list (governance) and only enabled
+	// addresses can mint (operations). By default, admins can also mint.
+	SeparateMintRole bool `json:"separateMintRole,omitempty"`
 }
 
 // Address returns the address of the native minter contract.
@@ -35,9 +60,36 @@ func (c *ContractNativeMinterConfig) Address() common.Address {
 	return ContractNativeMinterAddress
 }
 
-// Configure configures [state] with the desired admins based on [c].
+// Configure configures [state] with the desired admins based on [c] and mints
+// [InitialMint]. The total of [InitialMint] is tracked as the amount minted by
+// [ContractNativeMinterAddress].
 func (c *ContractNativeMinterConfig) Configure(state StateDB) {
 	c.AllowListConfig.Configure(state, ContractNativeMinterAddress)
+	SetContractNativeMinterSeparateMintRole(state, c.SeparateMintRole)
+
+	// Mint in a deterministic order
+	addrs := make([]common.Address, 0, len(c.InitialMint))
+	for addr := range c.InitialMint {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+	total := new(big.Int)
+	for _, addr := range addrs {
+		amount := c.InitialMint[addr]
+		if amount == nil || amount.Sign() <= 0 {
+			continue
+		}
+		if !state.Exist(addr) {
+			state.CreateAccount(addr)
+		}
+		state.AddBalance(addr, amount)
+		total.Add(total, amount)
+	}
+	if total.Sign() > 0 {
+		SetContractNativeMinterMinted(state, ContractNativeMinterAddress, new(big.Int).Add(GetContractNativeMinterMinted(state, ContractNativeMinterAddress), total))
+	}
 }
 
 // Contract returns the singleton stateful precompiled contract to be used for the native minter.
@@ -56,6 +108,37 @@ func SetContractNativeMinterStatus(stateDB StateDB, address common.Address, role
 	setAllowListRole(stateDB, ContractNativeMinterAddress, address, role)
 }
 
+// GetContractNativeMinterSeparateMintRole returns true if admins of the minter
+// list cannot mint (see [SeparateMintRole]).
+func GetContractNativeMinterSeparateMintRole(stateDB StateDB) bool {
+	return stateDB.GetState(ContractNativeMinterAddress, separateMintRoleKey) != common.Hash{}
+}
+
+// SetContractNativeMinterSeparateMintRole sets whether admins of the minter
+// list cannot mint (see [SeparateMintRole]).
+func SetContractNativeMinterSeparateMintRole(stateDB StateDB, enabled bool) {
+	val := common.Hash{}
+	if enabled {
+		val = common.BigToHash(common.Big1)
+	}
+	stateDB.SetState(ContractNativeMinterAddress, separateMintRoleKey, val)
+}
+
+// mintedKey returns the storage key of the cumulative amount minted by [address].
+func mintedKey(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(mintedPrefix, address.Bytes())
+}
+
+// GetContractNativeMinterMinted returns the cumulative amount minted by [address].
+func GetContractNativeMinterMinted(stateDB StateDB, address common.Address) *big.Int {
+	return stateDB.GetState(ContractNativeMinterAddress, mintedKey(address)).Big()
+}
+
+// SetContractNativeMinterMinted sets the cumulative amount minted by [address] to [amount].
+func SetContractNativeMinterMinted(stateDB StateDB, address common.Address, amount *big.Int) {
+	stateDB.SetState(ContractNativeMinterAddress, mintedKey(address), common.BigToHash(amount))
+}
+
 // PackMintInput packs [address] and [amount] into the appropriate arguments for minting operation.
 func PackMintInput(address common.Address, amount *big.Int) ([]byte, error) {
 	// function selector (4 bytes) + input(hash for address + hash for amount)
@@ -71,7 +154,7 @@ func PackMintInput(address common.Address, amount *big.Int) ([]byte, error) {
 // assumes that [input] does not include selector (omits first 4 bytes in PackMintInput)
 func UnpackMintInput(input []byte) (common.Address, *big.Int, error) {
 	if len(input) != mintInputLen {
-		return common.Address{}, nil, fmt.Errorf("invalid input length for minting: %d", len(input))
+		return common.Address{}, nil, invalidInputLength("mintNativeCoin", mintInputLen, len(input))
 	}
 	to := common.BytesToAddress(input[:common.HashLength])
 	assetAmount := new(big.Int).SetBytes(input[common.HashLength : common.HashLength+common.HashLength])
@@ -96,9 +179,11 @@ func createMintNativeCoin(accessibleState PrecompileAccessibleState, caller comm
 
 	stateDB := accessibleState.GetStateDB()
 	// Verify that the caller is in the allow list and therefore has the right to modify it
-	callerStatus := getAllowListStatus(stateDB, ContractNativeMinterAddress, caller)
-	if !callerStatus.IsEnabled() {
-		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotMint, caller)
+	if err := requireRole(stateDB, ContractNativeMinterAddress, caller, AllowListEnabled, ErrCannotMint); err != nil {
+		return nil, remainingGas, err
+	}
+	if getAllowListStatus(stateDB, ContractNativeMinterAddress, caller).IsAdmin() && GetContractNativeMinterSeparateMintRole(stateDB) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrAdminCannotMint, caller)
 	}
 
 	// if there is no address in the state, create one.
@@ -107,6 +192,10 @@ func createMintNativeCoin(accessibleState PrecompileAccessibleState, caller comm
 	}
 
 	stateDB.AddBalance(to, amount)
+	SetContractNativeMinterMinted(stateDB, caller, new(big.Int).Add(GetContractNativeMinterMinted(stateDB, caller), amount))
+	// The log is not charged for, so the gas cost of minting is unchanged for
+	// chains that enabled the native minter before it emitted logs
+	emitLog(accessibleState, ContractNativeMinterAddress, []common.Hash{NativeCoinMintedTopic, to.Hash()}, common.BigToHash(amount).Bytes())
 	// Return an empty output and the remaining gas
 	return []byte{}, remainingGas, nil
 }
@@ -117,10 +206,12 @@ func createNativeMinterPrecompile(precompileAddr common.Address) StatefulPrecomp
 	setEnabled := newStatefulPrecompileFunction(setEnabledSignature, createAllowListRoleSetter(precompileAddr, AllowListEnabled))
 	setNone := newStatefulPrecompileFunction(setNoneSignature, createAllowListRoleSetter(precompileAddr, AllowListNoRole))
 	read := newStatefulPrecompileFunction(readAllowListSignature, createReadAllowList(precompileAddr))
+	enabled := newStatefulPrecompileFunction(EnabledAddressesSignature, createEnabledAddresses(precompileAddr))
+	transferAdmin := newStatefulPrecompileFunction(TransferAdminSignature, createAllowListAdminTransfer(precompileAddr))
 
 	mint := newStatefulPrecompileFunction(mintSignature, createMintNativeCoin)
 
 	// Construct the contract with no fallback function.
-	contract := newStatefulPrecompileWithFunctionSelectors(nil, []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read, mint})
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read, enabled, transferAdmin, mint})
 	return contract
 }
@@ -0,0 +1,169 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/assert"
+)
+
+func TestContractNativeMinterMinted(t *testing.T) {
+	state := newCountingStateDB()
+	minter := common.Address{0x1}
+	other := common.Address{0x2}
+
+	assert.Equal(t, GetContractNativeMinterMinted(state, minter).Sign(), 0)
+
+	SetContractNativeMinterMinted(state, minter, big.NewInt(100))
+	assert.Assert(t, GetContractNativeMinterMinted(state, minter).Cmp(big.NewInt(100)) == 0)
+	assert.Equal(t, GetContractNativeMinterMinted(state, other).Sign(), 0)
+
+	// The minted amount does not modify the role of [minter]
+	assert.Equal(t, GetContractNativeMinterStatus(state, minter), AllowListNoRole)
+	SetContractNativeMinterStatus(state, minter, AllowListEnabled)
+	assert.Assert(t, GetContractNativeMinterMinted(state, minter).Cmp(big.NewInt(100)) == 0)
+
+	// Minting accumulates the amount minted by the caller
+	for i := 0; i < 2; i++ {
+		input, err := PackMintInput(other, big.NewInt(50))
+		assert.NilError(t, err)
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		_, _, err = ContractNativeMinterPrecompile.Run(accessibleState, minter, ContractNativeMinterAddress, input, MintGasCost+LogGasCost(2, common.HashLength), common.Big0, false)
+		assert.NilError(t, err)
+	}
+	assert.Assert(t, GetContractNativeMinterMinted(state, minter).Cmp(big.NewInt(200)) == 0)
+	assert.Equal(t, GetContractNativeMinterMinted(state, other).Sign(), 0)
+	assert.Assert(t, state.GetBalance(other).Cmp(big.NewInt(100)) == 0)
+}
+
+func TestContractNativeMinterInitialMint(t *testing.T) {
+	state := newCountingStateDB()
+	admin := common.Address{0x1}
+	recipients := []common.Address{{0x2}, {0x3}}
+	config := &ContractNativeMinterConfig{
+		AllowListConfig: AllowListConfig{AllowListAdmins: []common.Address{admin}},
+		InitialMint: map[common.Address]*big.Int{
+			recipients[0]: big.NewInt(100),
+			recipients[1]: big.NewInt(250),
+		},
+	}
+	config.Configure(state)
+
+	assert.Equal(t, GetContractNativeMinterStatus(state, admin), AllowListAdmin)
+	assert.Assert(t, state.GetBalance(recipients[0]).Cmp(big.NewInt(100)) == 0)
+	assert.Assert(t, state.GetBalance(recipients[1]).Cmp(big.NewInt(250)) == 0)
+	assert.Equal(t, state.GetBalance(admin).Sign(), 0)
+	assert.Assert(t, GetContractNativeMinterMinted(state, ContractNativeMinterAddress).Cmp(big.NewInt(350)) == 0)
+
+	// Recipients of the initial mint are not granted a role
+	assert.Equal(t, GetContractNativeMinterStatus(state, recipients[0]), AllowListNoRole)
+}
+
+func TestContractNativeMinterSeparateMintRole(t *testing.T) {
+	governance := common.Address{0x1}
+	operations := common.Address{0x2}
+	other := common.Address{0x3}
+
+	run := func(state *countingStateDB, caller common.Address, input []byte, suppliedGas uint64) error {
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		_, _, err := ContractNativeMinterPrecompile.Run(accessibleState, caller, ContractNativeMinterAddress, input, suppliedGas, common.Big0, false)
+		return err
+	}
+	mint := func(state *countingStateDB, caller common.Address) error {
+		input, err := PackMintInput(other, big.NewInt(50))
+		assert.NilError(t, err)
+		return run(state, caller, input, MintGasCost+LogGasCost(2, common.HashLength))
+	}
+	setEnabled := func(state *countingStateDB, caller common.Address) error {
+		input, err := PackModifyAllowList(other, AllowListEnabled)
+		assert.NilError(t, err)
+		return run(state, caller, input, ModifyAllowListGasCost)
+	}
+	configure := func(separate bool) *countingStateDB {
+		state := newCountingStateDB()
+		(&ContractNativeMinterConfig{
+			AllowListConfig: AllowListConfig{
+				AllowListAdmins:  []common.Address{governance},
+				EnabledAddresses: []common.Address{operations},
+			},
+			SeparateMintRole: separate,
+		}).Configure(state)
+		return state
+	}
+
+	// By default, admins can both mint and manage the allow list
+	state := configure(false)
+	assert.Assert(t, !GetContractNativeMinterSeparateMintRole(state))
+	assert.NilError(t, mint(state, governance))
+	assert.NilError(t, setEnabled(state, governance))
+
+	// With separate roles, the governance admin can set roles but cannot mint
+	state = configure(true)
+	assert.Assert(t, GetContractNativeMinterSeparateMintRole(state))
+	assert.Assert(t, errors.Is(mint(state, governance), ErrAdminCannotMint))
+	assert.Equal(t, state.GetBalance(other).Sign(), 0)
+	assert.NilError(t, setEnabled(state, governance))
+	assert.Equal(t, GetContractNativeMinterStatus(state, other), AllowListEnabled)
+
+	// and the operations minter can mint but cannot set roles
+	assert.NilError(t, mint(state, operations))
+	assert.Assert(t, state.GetBalance(other).Cmp(big.NewInt(50)) == 0)
+	assert.Assert(t, errors.Is(setEnabled(state, operations), ErrCannotModifyAllowList))
+}
+
+func TestContractNativeMinterTransferAdmin(t *testing.T) {
+	admin := common.Address{0x1}
+	newAdmin := common.Address{0x2}
+	other := common.Address{0x3}
+
+	state := newCountingStateDB()
+	(&ContractNativeMinterConfig{
+		AllowListConfig: AllowListConfig{AllowListAdmins: []common.Address{admin}},
+	}).Configure(state)
+	run := func(caller common.Address, input []byte, suppliedGas uint64, readOnly bool) error {
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		ret, remainingGas, err := ContractNativeMinterPrecompile.Run(accessibleState, caller, ContractNativeMinterAddress, input, suppliedGas, common.Big0, readOnly)
+		if err == nil {
+			assert.Equal(t, len(ret), 0)
+			assert.Equal(t, remainingGas, uint64(0))
+		}
+		return err
+	}
+	transfer := func(caller, to common.Address) error {
+		return run(caller, PackTransferAdmin(to), TransferAdminGasCost, false)
+	}
+
+	// Only an admin can transfer the role, and it cannot be transferred to
+	// the caller or the zero address (which would leave no admin)
+	assert.Assert(t, errors.Is(transfer(other, other), ErrCannotModifyAllowList))
+	assert.Assert(t, errors.Is(transfer(admin, admin), ErrInvalidAdminTransfer))
+	assert.Assert(t, errors.Is(transfer(admin, common.Address{}), ErrInvalidAdminTransfer))
+	assert.Equal(t, GetContractNativeMinterStatus(state, admin), AllowListAdmin)
+
+	// Read-only calls and insufficient gas do not modify the roles
+	assert.Assert(t, run(admin, PackTransferAdmin(newAdmin), TransferAdminGasCost, true) != nil)
+	assert.Assert(t, run(admin, PackTransferAdmin(newAdmin), TransferAdminGasCost-1, false) != nil)
+	assert.Equal(t, GetContractNativeMinterStatus(state, newAdmin), AllowListNoRole)
+
+	// The roles are swapped in a single call
+	assert.NilError(t, transfer(admin, newAdmin))
+	assert.Equal(t, GetContractNativeMinterStatus(state, newAdmin), AllowListAdmin)
+	assert.Equal(t, GetContractNativeMinterStatus(state, admin), AllowListNoRole)
+
+	accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+	ret, _, err := ContractNativeMinterPrecompile.Run(accessibleState, other, ContractNativeMinterAddress, PackEnabledAddresses(common.Big0, big.NewInt(10)), EnabledAddressesGasCost+10*EnabledAddressesItemCost, common.Big0, true)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ret, packAddressArray([]common.Address{newAdmin}))
+
+	// The previous admin can no longer modify the allow list
+	input, err := PackModifyAllowList(other, AllowListEnabled)
+	assert.NilError(t, err)
+	assert.Assert(t, errors.Is(run(admin, input, ModifyAllowListGasCost, false), ErrCannotModifyAllowList))
+	assert.NilError(t, run(newAdmin, input, ModifyAllowListGasCost, false))
+	assert.Equal(t, GetContractNativeMinterStatus(state, other), AllowListEnabled)
+}
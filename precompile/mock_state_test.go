@@ -0,0 +1,120 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testLog is a log added to a [countingStateDB].
+type testLog struct {
+	Address     common.Address
+	Topics      []common.Hash
+	Data        []byte
+	BlockNumber uint64
+}
+
+// countingStateDB is an in-memory [StateDB] that counts storage reads and
+// writes.
+type countingStateDB struct {
+	reads    int
+	writes   int
+	storage  map[common.Address]map[common.Hash]common.Hash
+	balances map[common.Address]*big.Int
+	logs     []*testLog
+}
+
+func newCountingStateDB() *countingStateDB {
+	return &countingStateDB{
+		storage:  make(map[common.Address]map[common.Hash]common.Hash),
+		balances: make(map[common.Address]*big.Int),
+	}
+}
+
+func (s *countingStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	s.reads++
+	return s.storage[addr][key]
+}
+
+func (s *countingStateDB) SetState(addr common.Address, key common.Hash, val common.Hash) {
+	s.writes++
+	if s.storage[addr] == nil {
+		s.storage[addr] = make(map[common.Hash]common.Hash)
+	}
+	s.storage[addr][key] = val
+}
+
+func (s *countingStateDB) SetCode(common.Address, []byte)  {}
+func (s *countingStateDB) SetNonce(common.Address, uint64) {}
+func (s *countingStateDB) GetNonce(common.Address) uint64  { return 0 }
+
+func (s *countingStateDB) GetBalance(addr common.Address) *big.Int {
+	if b, ok := s.balances[addr]; ok {
+		return new(big.Int).Set(b)
+	}
+	return new(big.Int)
+}
+
+func (s *countingStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	s.balances[addr] = new(big.Int).Add(s.GetBalance(addr), amount)
+}
+
+func (s *countingStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	s.balances[addr] = new(big.Int).Sub(s.GetBalance(addr), amount)
+}
+
+func (s *countingStateDB) CreateAccount(common.Address) {}
+func (s *countingStateDB) Exist(common.Address) bool    { return true }
+
+func (s *countingStateDB) AddPrecompileLog(addr common.Address, topics []common.Hash, data []byte, blockNumber uint64) {
+	s.logs = append(s.logs, &testLog{Address: addr, Topics: topics, Data: data, BlockNumber: blockNumber})
+}
+
+// Logs returns every log added so far.
+func (s *countingStateDB) Logs() []*testLog { return s.logs }
+
+// copyState returns a deep copy of [s], so the same calls can be executed
+// against diverging copies of the state (like the blocks of a re-org).
+func copyState(s *countingStateDB) *countingStateDB {
+	c := newCountingStateDB()
+	for addr, storage := range s.storage {
+		c.storage[addr] = make(map[common.Hash]common.Hash, len(storage))
+		for k, v := range storage {
+			c.storage[addr][k] = v
+		}
+	}
+	for addr, balance := range s.balances {
+		c.balances[addr] = new(big.Int).Set(balance)
+	}
+	c.logs = append(c.logs, s.logs...)
+	return c
+}
+
+type countingAccessibleState struct {
+	state       *countingStateDB
+	blockTime   *big.Int
+	blockNumber *big.Int
+}
+
+func (s *countingAccessibleState) GetStateDB() StateDB { return s.state }
+func (s *countingAccessibleState) BlockTime() *big.Int { return s.blockTime }
+
+func (s *countingAccessibleState) BlockNumber() *big.Int {
+	if s.blockNumber == nil {
+		return common.Big0
+	}
+	return s.blockNumber
+}
+
+// BlockHash returns a hash derived from [number] (so tests can tell which
+// block hash was used).
+func (s *countingAccessibleState) BlockHash(number uint64) common.Hash {
+	return crypto.Keccak256Hash(new(big.Int).SetUint64(number).Bytes())
+}
+func (s *countingAccessibleState) CallFromPrecompile(_, _ common.Address, _ []byte, gas uint64) ([]byte, uint64, error) {
+	return nil, gas, nil
+}
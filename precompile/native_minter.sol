@@ -6,6 +6,9 @@
 pragma solidity >=0.8.0;
 
 interface NativeMinterInterface {
+    // Emitted when [amount] number of native coins are minted to [addr]
+    event NativeCoinMinted(address indexed addr, uint256 amount);
+
     // Set [addr] to have the admin role over the minter list
     function setAdmin(address addr) external;
 
@@ -18,6 +21,14 @@ interface NativeMinterInterface {
     // Read the status of [addr]
     function readAllowList(address addr) external view returns (uint256);
 
+    // Query up to [limit] addresses with a role (enabled or admin), starting
+    // at [offset] (addresses granted a role before this function was added
+    // are only listed once they are granted a role again)
+    function enabledAddresses(uint256 offset, uint256 limit) external view returns (address[] memory);
+
+    // Grant the admin role to [newAdmin] and remove it from the caller in one call
+    function transferAdmin(address newAdmin) external;
+
     // Mint [amount] number of native coins and send to [addr]
     function mintNativeCoin(address addr, uint256 amount) external;
 }
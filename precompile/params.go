@@ -3,15 +3,25 @@
 
 package precompile
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 // Gas costs for stateful precompiles
 const (
 	ModifyAllowListGasCost = 20_000
 	ReadAllowListGasCost   = 5_000
+	TransferAdminGasCost   = 2 * ModifyAllowListGasCost
+
+	EnabledAddressesGasCost  = 5_000
+	EnabledAddressesItemCost = 1_000
 
 	MintGasCost = 30_000
 
+	// Note: [SponsorGasCost], [RewardGasCost], [ResultCost], and [NextCost] are
+	// defaults that can be overridden with [RandomPartyGasCosts].
 	StartGasCost      = 50_000
 	DeleteGasCost     = 1_000
 	SponsorGasCost    = 10_000
@@ -23,6 +33,66 @@ const (
 	ComputeRewardCost = 3_000
 	ResultCost        = 5_000
 	NextCost          = 5_000
+	StartTimeGasCost  = 5_000
+
+	ComputableAtGasCost   = 5_000
+	CommitFeeGasCost      = 5_000
+	LatestResultGasCost   = 5_000
+	LockedStakeGasCost    = 5_000
+	LockedStakeItemCost   = 1_000
+	TimeRemainingGasCost  = 5_000
+	SponsoredTotalGasCost = 5_000
+	NextDeadlineGasCost   = 5_000
+	ClaimGasCost          = 10_000
+	ClaimableGasCost      = 5_000
+	WasRevealedGasCost    = 5_000
+	WasRevealedItemCost   = 1_000
+	VersionGasCost        = 2_000
+	ParticipantsGasCost   = 5_000
+	ParticipantsItemCost  = 1_000
+
+	CommitFeeCollectedGasCost = 5_000
+	SnapshotGasCost           = 10_000
+	PhaseDurationGasCost      = 5_000
+	IsFinalizedGasCost        = 5_000
+	CommitFeeOfGasCost        = 5_000
+	ResultFractionGasCost     = 5_000
+	DeadlineRemainingGasCost  = 5_000
+	SchemaVersionGasCost      = 2_000
+	CanCommitGasCost          = 5_000
+	LastForfeitCountGasCost   = 5_000
+	RewardPerRevealerGasCost  = 5_000
+	RecentResultsGasCost      = 5_000
+	RecentResultsItemCost     = 1_000
+	SponsorCountGasCost       = 5_000
+	IsSolventGasCost          = 5_000
+	PausedGasCost             = 5_000
+	// ParticipationParamsGasCost is charged for reading the three parameters
+	// returned by participationParams
+	ParticipationParamsGasCost = 5_000
+	// ResultCallbackGasCost is charged by compute if [RandomPartyConfig]
+	// sets a ResultCallback and is the gas limit of the callback (any gas it
+	// leaves unused is returned)
+	ResultCallbackGasCost = 100_000
+	// CommitSignedGasCost includes the cost of recovering the signer (priced
+	// the same as the ecrecover precompile)
+	CommitSignedGasCost = CommitGasCost + 3_000
+	SetCommitFeeGasCost = ModifyAllowListGasCost
+	AbortGasCost        = ModifyAllowListGasCost
+	SetPausedGasCost    = ModifyAllowListGasCost
+	// RevealAndClaimGasCost is charged for the reveal and the withdrawal of
+	// credited rewards
+	RevealAndClaimGasCost = RevealGasCost + ClaimGasCost
+
+	// RevealBatchGasCost is charged once by revealBatch, in addition to
+	// [RevealGasCost] for each reveal in the batch
+	RevealBatchGasCost = 5_000
+
+	// Gas costs of emitting a log from a stateful precompile (priced the same
+	// as the LOG opcodes)
+	LogGas      = 375
+	LogTopicGas = 375
+	LogDataGas  = 8
 )
 
 // Designated addresses of stateful precompiles
@@ -44,3 +114,42 @@ var (
 		RandomPartyAddress,
 	}
 )
+
+// isUsedAddress returns true if [addr] is the address of a stateful precompile.
+func isUsedAddress(addr common.Address) bool {
+	for _, used := range UsedAddresses {
+		if addr == used {
+			return true
+		}
+	}
+	return false
+}
+
+// numEVMPrecompiles is the number of precompiles in core/vm/contracts.go, which
+// occupy addresses 0x01 to 0x09.
+const numEVMPrecompiles = 9
+
+func init() {
+	if err := validateUsedAddresses(UsedAddresses); err != nil {
+		panic(err)
+	}
+}
+
+// validateUsedAddresses returns an error if any address in [addrs] is
+// duplicated or conflicts with a precompile in core/vm/contracts.go.
+func validateUsedAddresses(addrs []common.Address) error {
+	used := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		if _, ok := used[addr]; ok {
+			return fmt.Errorf("stateful precompile address %s is used more than once", addr)
+		}
+		used[addr] = struct{}{}
+
+		for i := 1; i <= numEVMPrecompiles; i++ {
+			if addr == common.BytesToAddress([]byte{byte(i)}) {
+				return fmt.Errorf("stateful precompile address %s conflicts with EVM precompile %d", addr, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/assert"
+)
+
+func TestValidateUsedAddresses(t *testing.T) {
+	type test struct {
+		addrs       []common.Address
+		expectedErr string
+	}
+
+	for name, test := range map[string]test{
+		"used addresses": {
+			addrs: UsedAddresses,
+		},
+		"duplicate address": {
+			addrs:       append([]common.Address{RandomPartyAddress}, UsedAddresses...),
+			expectedErr: "is used more than once",
+		},
+		"conflicts with ecrecover": {
+			addrs:       append([]common.Address{common.HexToAddress("0x0000000000000000000000000000000000000001")}, UsedAddresses...),
+			expectedErr: "conflicts with EVM precompile 1",
+		},
+		"conflicts with blake2f": {
+			addrs:       append([]common.Address{common.HexToAddress("0x0000000000000000000000000000000000000009")}, UsedAddresses...),
+			expectedErr: "conflicts with EVM precompile 9",
+		},
+		"after EVM precompiles": {
+			addrs: append([]common.Address{common.HexToAddress("0x000000000000000000000000000000000000000a")}, UsedAddresses...),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateUsedAddresses(test.addrs)
+			if len(test.expectedErr) == 0 {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Assert(t, err != nil && strings.Contains(err.Error(), test.expectedErr), "expected error containing %q but got %v", test.expectedErr, err)
+		})
+	}
+}
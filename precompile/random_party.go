@@ -4,17 +4,27 @@
 package precompile
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
 const (
 	delim = byte('/')
+
+	// RandomPartyVersion is returned by version() and is incremented whenever
+	// functions are added to the Random Party precompile, so that contracts can
+	// detect which features are available (see [RandomPartyPrecompile] for the
+	// version that added each function).
+	RandomPartyVersion = 21
 )
 
 var (
@@ -27,34 +37,319 @@ var (
 	// 1) start() => cleans up the metadata of a previous Random Party and inits
 	//     a new Random Party (setting the length of the "commit" phase and "reveal"
 	//     phase to [PhaseSeconds] and setting the "commit" lockup to
-	//     [CommitStake])
+	//     [CommitStake]). Reverts with [ErrPhaseDurationUnset] if [PhaseSeconds]
+	//     is 0, which would close both phases as soon as they are opened.
 	//
 	//     Note: There is only ever 1 Random Party going on at once.
+	//
+	//     Note: If [AlignToEpoch] is set, each deadline is rounded up to the next
+	//     multiple of [AlignToEpoch] seconds, so the phases of every Random Party
+	//     end on epoch boundaries (each phase lasts at least [PhaseSeconds]).
+	//
+	//     Note: If [MaxStoredState] is set, start() reverts with
+	//     [ErrStateLimitReached] if there is no room left for the result of the
+	//     new Random Party and its first commitment.
+	//
+	//     Note: If [AutoComputeOnStart] is set, start() computes a previous Random
+	//     Party that is past its reveal deadline but was never computed (and
+	//     charges for it) before starting the new one, rather than reverting
+	//     with [ErrRandomPartyUnderway]. The compute follows the same rules as
+	//     compute() (e.g. the caller of start() receives any [ComputeBounty]).
+	//     A Random Party without reveals that cannot be computed (see
+	//     [NoRevealsReject]) is still replaced without a result.
 	// 2) [optional] sponsor() => anyone can donate funds to an incentive pool that
 	//     is distributed amongst all participants that reveal the preimage of their
-	//     commitment
-	// 3) commit(bytes32 encoded) => submit the hash of some preimage that will
+	//     commitment (until the commit deadline or, if [SponsorUntilRevealDeadline]
+	//     is set, until the reveal deadline). Reverts with [ErrRewardsDisabled] if
+	//     [RewardsDisabled] is set.
+	// 3) commit(bytes32 encoded) => submit the [CommitHashAlgo] hash of some preimage that will
 	//     be broadcasted during the "reveal" phase ([CommitStake] tokens must be
 	//     locked as part of this operation and are returned when the preimage is
 	//     revealed)
+	//
+	//     Note: If [MaxCommitsPerAddress] is set, each address can own at most
+	//     that many commitments per round.
+	//
+	//     Note: If [MaxTotalStake] is set, commit reverts with
+	//     [ErrStakeCapReached] if the stake locked by the current Random Party
+	//     (including the stake of this commitment) would exceed it.
+	//
+	//     Note: If [MaxStoredState] is set, commit reverts with
+	//     [ErrStateLimitReached] once the stored results, the pending result, and
+	//     the commitments to the current Random Party would exceed it.
+	//
+	//     Note: If [StakeWeighted] is set, committers can lock more than
+	//     [CommitStake] (all attached value is locked) and the incentive pool is
+	//     split in proportion to the stake of each reveal.
+	//
+	//     Note: commitSigned(bytes32 encoded, uint8 v, bytes32 r, bytes32 s) can be
+	//     used by a relayer to commit on behalf of a participant that signed the
+	//     EIP-712 digest of the commitment (the relayer locks the [CommitStake] and
+	//     the participant receives it when the preimage is revealed).
+	//
+	//     Note: If [NoRevealMode] is set, there is no "reveal" phase (the reveal
+	//     deadline is the commit deadline). Each commitment is treated as revealed
+	//     as soon as it is made: its [CommitStake] is returned in the same call
+	//     (which is charged [RevealGasCost] on top of the cost of the commit) and
+	//     compute() hashes the commitments themselves (concatenated in commit
+	//     order) once the commit deadline passes. This is much weaker than the
+	//     commit/reveal flow: commitments are public as soon as they are made, so
+	//     the last committer (or a block producer ordering commits) can choose a
+	//     commitment that biases the result. Only use it where that bias is
+	//     acceptable.
 	// 4) reveal(uint256 index, bytes32 preimage) => reveal the preimage for some
 	//     hash that was broadcast during the "commit" phase ([CommitStake] is returned
-	//     at this time)
+	//     at this time and at most [MaxReveals] preimages are accepted per round)
+	//
+	//     Note: Reveals are indexed in the order they are executed (so reveals in
+	//     the same block are ordered by transaction and reveals in a batch are
+	//     ordered as provided), regardless of the index of their commitment.
+	//
+	//     Note: If [FirstRevealBonus] is set, the first reveal of each round is
+	//     also paid that bonus out of the incentive pool.
+	//
+	//     Note: If [RefundRevealGas] is set, that much gas (at most [RevealGasCost])
+	//     is returned to the caller for each successful reveal by reveal(),
+	//     revealBatch(), and revealAndClaim(). The refund never exceeds the gas
+	//     charged by the call, so a reveal can be made free but never pays the
+	//     caller gas.
+	//
+	//     Note: revealBatch(uint256[] indices, bytes32[] preimages) can be used to
+	//     reveal multiple preimages at once (if any reveal is invalid, the entire
+	//     batch is reverted). It charges [RevealBatchGasCost] plus [RevealGasCost]
+	//     for each reveal.
+	//
+	//     Note: revealAndClaim(uint256 index, bytes32 preimage) (since version 13)
+	//     reveals like reveal() and withdraws the rewards credited to the caller
+	//     (see claim()) in the same call, returning the amount withdrawn. The
+	//     caller's share of the current round is not known until compute() and is
+	//     paid (or credited) by compute() as usual, so every reward is paid exactly
+	//     once: rewards credited before the reveal are withdrawn by revealAndClaim
+	//     and the share of the current round is settled by compute().
 	//
 	//     Note: If someone that posted a commitment does not reveal that
 	//     commitment, they will not be able to retrieve their [CommitState].
 	//     This mechanism is a naive deterrent for participants that may try to
-	//     game the result of the computation.
+	//     game the result of the computation. Forfeited stakes are routed to
+	//     [ForfeitDestination] when the next Random Party is started (if it is
+	//     [ForfeitToRecipient], they are sent to [ForfeitRecipient] and a
+	//     StakesForfeited log is emitted).
 	// 5) compute() => after the "commit" and "reveal" phases have passed, anyone
-	//     can pay to compute the hash of all preimages (any balance in the
+	//     can pay to compute the hash of all preimages (concatenated in reveal
+	//     order) (any balance in the
 	//     incentive pool is distributed equally to everyone that broadcast a preimage)
 	//
+	//     Note: If [ComputeBounty] is set, the caller of compute() is paid the
+	//     bounty out of the incentive pool before it is distributed (the bounty is
+	//     reduced by [BountyDecayRate] for each second since the reveal deadline).
+	//
+	//     Note: If [RevealBonus] is set, each revealer is also paid a bonus out of
+	//     the stakes forfeited in the round.
+	//
+	//     Note: If [MaxPayoutsPerCompute] is set, compute only pays that many
+	//     revealers and credits the rewards of the rest, which can be withdrawn
+	//     with claim() (claimable(address account) returns the amount credited
+	//     to [account]).
+	//
+	//     Note: If [ComputeByRevealersOnly] is set, only participants that revealed
+	//     a preimage can compute a round (unless no one revealed).
+	//
+	//     Note: If any reward or bonus is paid, compute emits a RewardPerRevealer
+	//     log with the amount owed to each revealer (the incentive pool, less any
+	//     bounty, divided by the number of reveals, plus any [RevealBonus]). The
+	//     amount is also returned by rewardPerRevealer(uint256 round). If
+	//     [StakeWeighted] is set, it is the average amount owed to each revealer.
+	//
+	//     Note: If [RewardsDisabled] is set, compute only produces the result (no
+	//     bounty, rewards, or bonuses are paid and the incentive pool is left as
+	//     is), so it only charges for hashing the reveals. [FirstRevealBonus] is
+	//     not paid either (revealers still get their [CommitStake] back) and
+	//     forfeited stakes routed to revealers are added to the incentive pool
+	//     instead.
+	//
+	//     Note: If no one revealed, compute fails with [ErrNoReveals] (and start()
+	//     can be called to replace the round) unless [NoRevealsBehavior] is
+	//     [NoRevealsBlockHash], in which case the result is the hash of the parent
+	//     block (which can be influenced by block producers). The incentive pool
+	//     (less any [ComputeBounty]) of a round without reveals is not paid to
+	//     anyone, so it rolls over to the next round.
+	//
+	//     Note: If [ResultCallback] is set, compute calls
+	//     onRandomPartyResult(uint256 round, bytes32 result) on it once the round
+	//     is finalized and every reward has been paid (with at most
+	//     [ResultCallbackGasCost] gas, which is charged up front and any unused
+	//     gas is returned). Failures of the callback are ignored, so it can never
+	//     prevent a round from being computed. The callback is made last, so it
+	//     can safely call back into the precompile (e.g. to start() the next
+	//     Random Party). If the round is computed by start() (see
+	//     [AutoComputeOnStart]), the callback is made once the next Random Party
+	//     has been started.
+	//
 	// Contracts use the following methods to access the state of an ongoing/completed Random Party:
-	// 1) reward() => returns the amount in the current incentive pool
+	// 1) reward() => returns the amount in the current incentive pool (if
+	//     [RestrictRewardView] is set, only addresses enabled on the Random Party
+	//     allow list can call it and others revert with [ErrCannotReadReward])
 	// 2) result(uint256 round) => returns the computed hash of preimages of a given Random Party
-	//     round
+	//     round (reverts with [ErrRoundNotComputed] if the result of the round has not
+	//     been computed yet, so a result of zero is never ambiguous)
+	//
+	//     Note: If [EmitResultConsumed] is set, result() emits a ResultConsumed log
+	//     (and charges for it) unless it is called in a static context.
 	// 3) next() => returns the number of the next Random Party round (this
 	//     number-1 is used to query the latest result)
+	// 4) startTime() => returns the time at which the latest Random Party was
+	//     started
+	// 5) computableAt(uint256 timestamp) => returns true if compute() can be called
+	//     on the current Random Party at [timestamp]
+	// 6) commitFee() => returns the [CommitStake] that must be locked to commit
+	//     to the current Random Party
+	// 7) latestResult() => returns the result of the latest computed Random Party
+	//     round (reverts with [ErrRoundNotComputed] if no round has been computed)
+	// 8) lockedStake(address committer) => returns the [CommitStake] locked by
+	//     [committer] in commitments to the current Random Party that have not
+	//     been revealed
+	// 9) timeRemaining() => returns the number of seconds until the deadline of the
+	//     current phase ("commit" or "reveal") or zero if there is no Random Party
+	//     underway or it can be computed
+	// 10) sponsoredTotal(uint256 round) => returns the total amount donated with
+	//     sponsor() to the incentive pool of [round] (unlike reward(), this is not
+	//     reset when the round is computed)
+	// 11) nextDeadline() => returns the deadline of the current phase ("commit" or
+	//     "reveal") or zero if there is no Random Party underway or it can be
+	//     computed
+	// 12) wasRevealed(bytes32 preimage) => returns true if [preimage] was revealed
+	//     in the current (or latest computed) Random Party
+	// 13) version() => returns [RandomPartyVersion], which is incremented whenever
+	//     functions are added to the Random Party precompile (functions documented
+	//     here are available since version 1 unless noted otherwise)
+	// 14) participants(uint256 offset, uint256 limit) => returns up to [limit]
+	//     distinct owners of commitments to the current Random Party (in the order
+	//     they first committed), starting at [offset]
+	//     Available since version 2.
+	// 15) commitFeeCollected() => returns the total stake locked by commitments to
+	//     the current Random Party that have not been revealed (stakes that are
+	//     forfeited are no longer counted once the next Random Party is started)
+	//     Available since version 4.
+	// 16) snapshot() => returns the phase ([RandomPartyPhase]), the number of
+	//     commitments, the number of reveals, the commit and reveal deadlines, the
+	//     incentive pool, and the round of the current Random Party in one call
+	//     (the counts and round of the latest Random Party are returned until the
+	//     next one is started). The incentive pool is returned as 0 to callers
+	//     that cannot call reward().
+	//     Available since version 5.
+	// 17) phaseDuration() => returns [PhaseSeconds], the length of the "commit"
+	//     and "reveal" phases of each Random Party
+	//     Available since version 6.
+	// 18) isFinalized(uint256 round) => returns true if the result of [round] has
+	//     been computed (i.e. [round] is less than next()), so it can never change
+	//     Available since version 7.
+	// 19) commitFeeOf(uint256 round) => returns the [CommitStake] that was required
+	//     to commit in [round] (recorded when the round is started and updated by
+	//     setCommitFee before its first commitment), or 0 if [round] was never
+	//     started
+	//     Available since version 9.
+	// 20) resultFraction(uint256 round, uint256 precision) => returns the result of
+	//     [round] modulo [precision], a fixed-point fraction in [0, 1) with
+	//     denominator [precision] (reverts with [ErrRoundNotComputed] like result()
+	//     and with [ErrZeroPrecision] if [precision] is 0). Reducing the 256-bit
+	//     result biases the fraction by less than precision/2^256, which is
+	//     negligible for any practical [precision], but the fraction should not be
+	//     reduced again by a denominator that does not divide [precision] (e.g. use
+	//     resultFraction(round, n) rather than resultFraction(round, 1000) % n)
+	//     Available since version 10.
+	// 21) getCommitDeadlineRemaining() and getRevealDeadlineRemaining() => return the
+	//     number of seconds until the commit and reveal deadlines of the current
+	//     Random Party respectively (both count down from start, so the reveal
+	//     countdown can be shown during the "commit" phase), or zero if that
+	//     deadline has passed or there is no Random Party underway
+	//     Available since version 11.
+	// 22) schemaVersion() => returns the version of the storage layout stored in
+	//     the state of the Random Party ([RandomPartySchemaVersion] when it was
+	//     configured), which differs from [RandomPartySchemaVersion] until the state
+	//     is migrated
+	//     Available since version 12.
+	// 23) canCommit() => returns true if the caller could commit right now: a Random
+	//     Party is in its "commit" phase, the caller owns fewer than
+	//     [MaxCommitsPerAddress] commitments (if set), the commitment fits in
+	//     [MaxStoredState] (if set), locking [CommitStake] would not exceed
+	//     [MaxTotalStake] (if set), and (in [NoRevealMode]) [MaxReveals] has not
+	//     been reached. Whether the caller can pay [CommitStake] is not checked.
+	//     Available since version 14.
+	// 24) lastForfeitCount() => returns the number of commitments to the previous
+	//     Random Party that were never revealed (recorded when the next Random
+	//     Party is started, so commitments refunded by abort() are not counted)
+	//     Available since version 15.
+	// 25) rewardPerRevealer(uint256 round) => returns the amount owed to each
+	//     revealer of [round] when it was computed (see compute()), or 0 if no
+	//     reward or bonus was paid in [round] or it has not been computed
+	//     Available since version 16.
+	// 26) recentResults(uint256 n) => returns the results of the latest [n] computed
+	//     rounds as a bytes32[], newest first (fewer if fewer rounds have been
+	//     computed, and gas is charged per result returned)
+	//     Available since version 17.
+	// 27) sponsorCount() => returns the number of distinct addresses that called
+	//     sponsor() in the current (or latest computed) Random Party (reset to 0
+	//     when the next Random Party is started)
+	//     Available since version 18.
+	// 28) isSolvent() => returns true if the balance of the precompile covers the
+	//     incentive pool and the stake locked by unrevealed commitments (rewards
+	//     credited to claimable() are not counted), so monitoring can detect
+	//     accounting drift
+	//     Available since version 19.
+	// 29) paused() => returns the [PauseFlags] of the methods paused by admins
+	//     Available since version 20.
+	// 30) participationParams() => returns the [CommitStake] required to commit,
+	//     the minimum number of reveals needed to compute a round (1, or 0 if
+	//     [NoRevealsBehavior] is [NoRevealsBlockHash]), and
+	//     [MaxCommitsPerAddress] (0 if there is no cap) in one call
+	//     Available since version 21.
+	//
+	// Methods check their arguments in a consistent order: the base gas cost is
+	// charged first (so ErrOutOfGas takes precedence over all errors other than
+	// [ErrUnexpectedValue], [ErrPrecompileDisabled], and [ErrMethodPaused], which
+	// are checked before a method runs), then the phase of the Random Party is
+	// checked (e.g. [ErrTooLate]), then the input is decoded, and then the call
+	// itself is validated (e.g. [ErrInsufficientFunds]).
+	//
+	// Only sponsor(), commit(), and commitSigned() accept value. All other methods
+	// fail with [ErrUnexpectedValue] if any value is sent (so funds are never
+	// absorbed by the precompile by accident).
+	//
+	// Deadlines are derived from the time of the block that executes start(), so
+	// if a re-org re-executes start() in a block with a different time, both
+	// deadlines shift with it (the phases always last [PhaseSeconds], or until
+	// the following epoch boundary if [AlignToEpoch] is set). Every other call
+	// only depends on the state and the time of its own block, so re-executed
+	// commits and reveals that no longer fall within their phase revert (e.g.
+	// with [ErrTooLate]) rather than corrupting the Random Party, which can
+	// always be computed (or replaced) once its reveal deadline has passed.
+	// Contracts should wait for the result of a round rather than act on the
+	// deadlines of a Random Party that may still be re-orged.
+	//
+	// If [DisableBlockTimestamp] is set, start(), sponsor(), commit(),
+	// commitSigned(), and setCommitFee() fail with [ErrPrecompileDisabled] from
+	// that time onwards (reveals, compute(), claim(), and all views keep working
+	// so a Random Party that is underway can be completed).
+	//
+	// Admins of the Random Party allow list (see [AllowListAdmins]) can use
+	// setCommitFee(uint256 fee) to update [CommitStake] when there are no
+	// commitments in the current round (the allow list is managed with the same
+	// methods as other allow lists, and enabledAddresses(uint256 offset, uint256
+	// limit) is available since version 3). Fees below [MinCommitFee] revert with
+	// [ErrCommitFeeBelowMin].
+	//
+	// Admins can also use abort() (since version 8) to end the current Random Party
+	// without computing it (e.g. if it failed). Every commitment that was not
+	// revealed is refunded to its owner (emitting a StakeRefunded log) instead of
+	// being forfeited, and the next Random Party reuses the round of the aborted
+	// one.
+	//
+	// Admins can also use setPaused(uint256 flags) (since version 20) to pause
+	// start() ([PauseStart]), sponsor() ([PauseSponsor]), and commit() and
+	// commitSigned() ([PauseCommit]) independently (e.g. during an incident).
+	// Paused methods fail with [ErrMethodPaused] until the flag is cleared by
+	// another call to setPaused. Reveals, compute(), claim(), and all views are
+	// never paused, so a Random Party that is underway can always be completed.
 	//
 	// In short, anyone can start a Random Party on the
 	// chain, anyone can sponsor a reward for contributors, anyone can
@@ -73,6 +368,75 @@ var (
 	ComputeSignature = CalculateFunctionSelector("compute()")
 	ResultSignature  = CalculateFunctionSelector("result(uint256)")
 	NextSignature    = CalculateFunctionSelector("next()")
+
+	StartTimeSignature      = CalculateFunctionSelector("startTime()")
+	ComputableAtSignature   = CalculateFunctionSelector("computableAt(uint256)")
+	CommitFeeSignature      = CalculateFunctionSelector("commitFee()")
+	LatestResultSignature   = CalculateFunctionSelector("latestResult()")
+	RevealBatchSignature    = CalculateFunctionSelector("revealBatch(uint256[],bytes32[])")
+	LockedStakeSignature    = CalculateFunctionSelector("lockedStake(address)")
+	CommitSignedSignature   = CalculateFunctionSelector("commitSigned(bytes32,uint8,bytes32,bytes32)")
+	SetCommitFeeSignature   = CalculateFunctionSelector("setCommitFee(uint256)")
+	TimeRemainingSignature  = CalculateFunctionSelector("timeRemaining()")
+	SponsoredTotalSignature = CalculateFunctionSelector("sponsoredTotal(uint256)")
+	NextDeadlineSignature   = CalculateFunctionSelector("nextDeadline()")
+	ClaimSignature          = CalculateFunctionSelector("claim()")
+	ClaimableSignature      = CalculateFunctionSelector("claimable(address)")
+	WasRevealedSignature    = CalculateFunctionSelector("wasRevealed(bytes32)")
+	VersionSignature        = CalculateFunctionSelector("version()")
+	ParticipantsSignature   = CalculateFunctionSelector("participants(uint256,uint256)")
+
+	CommitFeeCollectedSignature = CalculateFunctionSelector("commitFeeCollected()")
+	SnapshotSignature           = CalculateFunctionSelector("snapshot()")
+	PhaseDurationSignature      = CalculateFunctionSelector("phaseDuration()")
+	IsFinalizedSignature        = CalculateFunctionSelector("isFinalized(uint256)")
+	AbortSignature              = CalculateFunctionSelector("abort()")
+	CommitFeeOfSignature        = CalculateFunctionSelector("commitFeeOf(uint256)")
+	ResultFractionSignature     = CalculateFunctionSelector("resultFraction(uint256,uint256)")
+
+	GetCommitDeadlineRemainingSignature = CalculateFunctionSelector("getCommitDeadlineRemaining()")
+	GetRevealDeadlineRemainingSignature = CalculateFunctionSelector("getRevealDeadlineRemaining()")
+	SchemaVersionSignature              = CalculateFunctionSelector("schemaVersion()")
+	RevealAndClaimSignature             = CalculateFunctionSelector("revealAndClaim(uint256,bytes32)")
+	CanCommitSignature                  = CalculateFunctionSelector("canCommit()")
+	LastForfeitCountSignature           = CalculateFunctionSelector("lastForfeitCount()")
+	RewardPerRevealerSignature          = CalculateFunctionSelector("rewardPerRevealer(uint256)")
+	RecentResultsSignature              = CalculateFunctionSelector("recentResults(uint256)")
+	SponsorCountSignature               = CalculateFunctionSelector("sponsorCount()")
+	IsSolventSignature                  = CalculateFunctionSelector("isSolvent()")
+	SetPausedSignature                  = CalculateFunctionSelector("setPaused(uint256)")
+	PausedSignature                     = CalculateFunctionSelector("paused()")
+	ParticipationParamsSignature        = CalculateFunctionSelector("participationParams()")
+)
+
+var (
+	// EIP-712 type hashes used to compute the digest signed by participants
+	// of [commitSigned]
+	eip712DomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,address verifyingContract)"))
+	commitTypeHash       = crypto.Keccak256Hash([]byte("Commit(bytes32 encoded,uint256 round)"))
+
+	// RandomPartyDomainSeparator is the EIP-712 domain separator of
+	// [RandomPartyAddress].
+	RandomPartyDomainSeparator = crypto.Keccak256Hash(
+		eip712DomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte("RandomParty")),
+		crypto.Keccak256([]byte("1")),
+		RandomPartyAddress.Hash().Bytes(),
+	)
+)
+
+var (
+	// Random Party events
+	ResultComputedTopic  = CalculateEventTopic("ResultComputed(uint256,bytes32)")
+	ResultConsumedTopic  = CalculateEventTopic("ResultConsumed(uint256,address)")
+	StakeRefundedTopic   = CalculateEventTopic("StakeRefunded(uint256,address,uint256)")
+	StakesForfeitedTopic = CalculateEventTopic("StakesForfeited(uint256,address,uint256)")
+
+	RewardPerRevealerTopic = CalculateEventTopic("RewardPerRevealer(uint256,uint256)")
+
+	// ResultCallbackSignature is the selector called on [ResultCallback] when
+	// a round is computed.
+	ResultCallbackSignature = CalculateFunctionSelector("onRandomPartyResult(uint256,bytes32)")
 )
 
 var (
@@ -82,15 +446,339 @@ var (
 	ErrTooLate              = errors.New("too late to interact")
 	ErrTooEarly             = errors.New("too early")
 	ErrDuplicateReveal      = errors.New("duplicate reveal")
+	ErrUnknownForfeitDest   = errors.New("unknown forfeit destination")
+	ErrUnknownNoReveals     = errors.New("unknown no reveals behavior")
 	ErrInsufficientFunds    = errors.New("insufficient funds to perform commit")
+	ErrRoundNotComputed     = errors.New("round not computed")
+	ErrRevealCapReached     = errors.New("reveal cap reached")
+	ErrUnknownCommitHash    = errors.New("unknown commit hash algorithm")
+	ErrInvalidRecipient     = errors.New("recipient is a stateful precompile")
+	ErrNotRevealer          = errors.New("caller did not reveal in the current round")
+	ErrInvalidSignature     = errors.New("invalid commit signature")
+	ErrCannotSetCommitFee   = errors.New("non-admin cannot set commit fee")
+	ErrCommitLimitReached   = errors.New("commit limit reached")
+	ErrRevealIndexTooLarge  = errors.New("reveal index exceeds uint64")
+	ErrNothingToClaim       = errors.New("nothing to claim")
+	ErrNoReveals            = errors.New("no preimages revealed")
+	ErrPrecompileDisabled   = errors.New("precompile disabled")
+	ErrInsufficientBalance  = errors.New("insufficient precompile balance")
+	ErrUnexpectedValue      = errors.New("unexpected value")
+	ErrCannotAbort          = errors.New("non-admin cannot abort")
+	ErrZeroPrecision        = errors.New("precision must be non-zero")
+	ErrCannotReadReward     = errors.New("non-enabled cannot read reward")
+	ErrPhaseDurationUnset   = errors.New("phase duration unset")
+	ErrStateLimitReached    = errors.New("stored state limit reached")
+	ErrRewardsDisabled      = errors.New("rewards disabled")
+	ErrCommitFeeBelowMin    = errors.New("commit fee below minimum")
+	ErrNoForfeitRecipient   = errors.New("forfeit recipient unset")
+	ErrStakeCapReached      = errors.New("stake cap reached")
+	ErrMethodPaused         = errors.New("method paused")
+	ErrCannotPause          = errors.New("non-admin cannot pause")
+	ErrInvalidPauseFlags    = errors.New("invalid pause flags")
+)
+
+// ForfeitDestination specifies where the [CommitStake] of participants that
+// never revealed their preimage is sent when the next Random Party is started.
+type ForfeitDestination uint64
+
+const (
+	// ForfeitToRevealers splits forfeited stakes equally amongst everyone that
+	// revealed a preimage in the round (or adds them to the incentive pool of
+	// the next round if no one revealed or [RewardsDisabled] is set). Any
+	// remainder that cannot be split equally is added to the incentive pool
+	// of the next round. It is
+	// the zero value, so configs that predate [ForfeitDestination] (which left
+	// forfeited stakes locked in the balance of the precompile) now pay them
+	// to revealers.
+	ForfeitToRevealers ForfeitDestination = iota
+	// ForfeitToPool adds forfeited stakes to the incentive pool of the next
+	// round.
+	ForfeitToPool
+	// ForfeitToBurn sends forfeited stakes to [constants.BlackholeAddr].
+	ForfeitToBurn
+	// ForfeitToRecipient sends forfeited stakes to [ForfeitRecipient] (e.g. a
+	// treasury).
+	ForfeitToRecipient
+)
+
+// PauseFlags are the bit flags of the methods paused with setPaused.
+type PauseFlags uint64
+
+const (
+	// PauseStart pauses start().
+	PauseStart PauseFlags = 1 << iota
+	// PauseSponsor pauses sponsor().
+	PauseSponsor
+	// PauseCommit pauses commit() and commitSigned().
+	PauseCommit
+
+	// allPauseFlags is the union of every valid [PauseFlags].
+	allPauseFlags = PauseStart | PauseSponsor | PauseCommit
 )
 
+// NoRevealsBehavior specifies how compute handles a round in which no
+// preimages were revealed.
+type NoRevealsBehavior uint64
+
+const (
+	// NoRevealsReject fails compute with [ErrNoReveals]. A round without
+	// reveals can instead be replaced by calling start() once its reveal
+	// deadline has passed.
+	NoRevealsReject NoRevealsBehavior = iota
+	// NoRevealsBlockHash computes the result of the round from the hash of
+	// the parent block. This entropy can be influenced by block producers, so
+	// it is much weaker than the result of a round with reveals.
+	NoRevealsBlockHash
+)
+
+// RandomPartyPhase is the phase of the Random Party at some block time (as
+// returned by snapshot()).
+type RandomPartyPhase uint64
+
+const (
+	// RandomPartyPhaseNone means no Random Party is underway (none was ever
+	// started or the latest one was computed).
+	RandomPartyPhaseNone RandomPartyPhase = iota
+	// RandomPartyPhaseCommit means commitments are accepted.
+	RandomPartyPhaseCommit
+	// RandomPartyPhaseReveal means preimages are accepted.
+	RandomPartyPhaseReveal
+	// RandomPartyPhaseComputable means the reveal deadline has passed and the
+	// Random Party has not been computed yet.
+	RandomPartyPhaseComputable
+)
+
+// CommitHashAlgo specifies the hash function used to verify that a revealed
+// preimage matches its commitment.
+type CommitHashAlgo uint64
+
+const (
+	// CommitHashKeccak256 verifies commitments with Keccak-256.
+	CommitHashKeccak256 CommitHashAlgo = iota
+	// CommitHashSHA256 verifies commitments with SHA-256 (useful when
+	// commitments are generated on chains without Keccak-256 support).
+	CommitHashSHA256
+)
+
+// Hash returns the commitment of [preimage] using [a].
+func (a CommitHashAlgo) Hash(preimage []byte) (common.Hash, error) {
+	switch a {
+	case CommitHashKeccak256:
+		return crypto.Keccak256Hash(preimage), nil
+	case CommitHashSHA256:
+		return sha256.Sum256(preimage), nil
+	default:
+		return common.Hash{}, fmt.Errorf("%w: %d", ErrUnknownCommitHash, a)
+	}
+}
+
+// VerifyReveal returns true if [preimage] would be accepted by reveal for
+// [commitment] using [a] (false if [a] is unknown).
+func (a CommitHashAlgo) VerifyReveal(commitment common.Hash, preimage common.Hash) bool {
+	h, err := a.Hash(preimage.Bytes())
+	return err == nil && h == commitment
+}
+
+// VerifyReveal returns true if [preimage] would be accepted by reveal for
+// [commitment] under the default [CommitHashKeccak256] (use
+// [CommitHashAlgo.VerifyReveal] with [GetCommitHashAlgo] if another algorithm
+// is configured), so clients can check a commit/reveal pair before submitting
+// it.
+func VerifyReveal(commitment common.Hash, preimage common.Hash) bool {
+	return CommitHashKeccak256.VerifyReveal(commitment, preimage)
+}
+
 // RandomPartyConfig specifies the configuration of the Random Party precompile.
 type RandomPartyConfig struct {
 	BlockTimestamp *big.Int `json:"blockTimestamp"`
 
-	PhaseSeconds *big.Int `json:"phaseSeconds"`
-	CommitStake  *big.Int `json:"commitStake"`
+	PhaseSeconds       *big.Int           `json:"phaseSeconds"`
+	CommitStake        *big.Int           `json:"commitStake"`
+	ForfeitDestination ForfeitDestination `json:"forfeitDestination"`
+
+	// StakeFromBalance allows commit to draw any part of [CommitStake] that is
+	// not attached as value from the balance of the committer (so contracts that
+	// pre-fund their balance can participate without forwarding value).
+	StakeFromBalance bool `json:"stakeFromBalance"`
+
+	// MaxReveals caps the number of reveals accepted per round to bound the
+	// cost of compute (0 means there is no cap). Commitments that cannot be
+	// revealed once the cap is reached keep their [CommitStake] locked.
+	MaxReveals uint64 `json:"maxReveals"`
+
+	// CommitHashAlgo is the hash function used to verify reveals against
+	// commitments (defaults to Keccak-256).
+	CommitHashAlgo CommitHashAlgo `json:"commitHashAlgo"`
+
+	// ComputeByRevealersOnly restricts compute to addresses that revealed in
+	// the round (anyone can compute a round without reveals).
+	ComputeByRevealersOnly bool `json:"computeByRevealersOnly"`
+
+	// GasCosts overrides the gas charged by some Random Party methods.
+	GasCosts RandomPartyGasCosts `json:"gasCosts"`
+
+	// RevealBonus is paid to each revealer (in addition to their returned
+	// [CommitStake]) when the round is computed. The bonus is funded by the
+	// stakes forfeited in the round, so it is only paid (or is reduced) when
+	// there are enough forfeited stakes to pay every revealer.
+	RevealBonus *big.Int `json:"revealBonus"`
+
+	// AllowListAdmins are the initial admins of the Random Party allow list.
+	// Admins can manage the allow list and update [CommitStake] with
+	// setCommitFee.
+	AllowListAdmins []common.Address `json:"adminAddresses"`
+	// EnabledAddresses are initially enabled on the Random Party allow list.
+	EnabledAddresses []common.Address `json:"enabledAddresses,omitempty"`
+
+	// EmitResultConsumed emits a ResultConsumed log each time result() is
+	// called (so operators can track which rounds are used on-chain). This
+	// makes result() charge for the log and the log is not emitted by static
+	// calls (including calls to result() through a Solidity view interface),
+	// which cannot modify state.
+	EmitResultConsumed bool `json:"emitResultConsumed"`
+
+	// MaxCommitsPerAddress caps the number of commitments owned by a single
+	// address per round (0 means there is no cap), so no one can dominate the
+	// preimages of a round.
+	MaxCommitsPerAddress uint64 `json:"maxCommitsPerAddress"`
+
+	// MaxPayoutsPerCompute caps the number of rewards paid by compute (0 means
+	// there is no cap) to bound its gas. Rewards of revealers beyond the cap
+	// are credited to them and can be withdrawn with claim().
+	MaxPayoutsPerCompute uint64 `json:"maxPayoutsPerCompute"`
+
+	// StakeWeighted allows committers to lock more than [CommitStake] (all
+	// value attached to commit is locked) and splits the incentive pool in
+	// proportion to the stake of each reveal. Each stake is returned when its
+	// preimage is revealed (or forfeited in full if it is not).
+	StakeWeighted bool `json:"stakeWeighted"`
+
+	// NoRevealsBehavior specifies how compute handles a round in which no
+	// preimages were revealed (defaults to rejecting the computation).
+	NoRevealsBehavior NoRevealsBehavior `json:"noRevealsBehavior"`
+
+	// DisableBlockTimestamp is the time at which the Random Party stops
+	// accepting start, sponsor, commit, and setCommitFee calls (nil or 0 never
+	// disables it). Calls that return funds (reveal, compute, and claim) and
+	// reads keep working, so a Random Party that is underway can still be
+	// completed.
+	DisableBlockTimestamp *big.Int `json:"disableBlockTimestamp,omitempty"`
+
+	// SponsorUntilRevealDeadline allows sponsor to be called until the reveal
+	// deadline (rather than only until the commit deadline), so sponsors can
+	// keep funding the incentive pool during the "reveal" phase.
+	SponsorUntilRevealDeadline bool `json:"sponsorUntilRevealDeadline,omitempty"`
+
+	// ComputeBounty is paid to the caller of compute out of the incentive pool
+	// (nil or 0 pays no bounty). The bounty is reduced by [BountyDecayRate]
+	// for every second that passed since the reveal deadline, so the earliest
+	// compute is paid the most.
+	ComputeBounty   *big.Int `json:"computeBounty,omitempty"`
+	BountyDecayRate *big.Int `json:"bountyDecayRate,omitempty"`
+
+	// FirstRevealBonus is paid out of the incentive pool to the recipient of
+	// the first reveal of each round (along with its returned [CommitStake]),
+	// so someone is always incentivized to reveal first (nil or 0 pays no
+	// bonus). The bonus is reduced to the size of the incentive pool.
+	FirstRevealBonus *big.Int `json:"firstRevealBonus,omitempty"`
+
+	// RestrictRewardView limits reward() (and the incentive pool returned by
+	// snapshot()) to addresses enabled on the Random Party allow list, e.g. to
+	// keep the pool sealed during an auction. The pool can still be read from
+	// the state of [RandomPartyAddress] directly.
+	RestrictRewardView bool `json:"restrictRewardView,omitempty"`
+
+	// MaxStoredState approximately bounds the number of entries stored by the
+	// Random Party (0 is unbounded), counting each stored result and each
+	// commitment to the current Random Party. Results are never pruned, so once
+	// the limit is reached no new Random Party can be started.
+	MaxStoredState uint64 `json:"maxStoredState,omitempty"`
+
+	// RewardsDisabled makes the Random Party only produce randomness: sponsor()
+	// is rejected and no bounty, rewards, or bonuses are paid (any existing
+	// incentive pool is left untouched until rewards are re-enabled).
+	RewardsDisabled bool `json:"rewardsDisabled,omitempty"`
+
+	// MinCommitFee is the floor of [CommitStake] (nil or 0 allows any fee,
+	// including none). A [CommitStake] below it is rejected by [Verify] and
+	// setCommitFee rejects fees below it, so the deterrent against committing
+	// without revealing cannot be configured away.
+	MinCommitFee *big.Int `json:"minCommitFee,omitempty"`
+
+	// ForfeitRecipient receives forfeited stakes if [ForfeitDestination] is
+	// [ForfeitToRecipient]. [Verify] rejects the zero address and precompiles
+	// (which could never spend them). If one is configured anyway, forfeited
+	// stakes are added to the incentive pool instead.
+	ForfeitRecipient common.Address `json:"forfeitRecipient,omitempty"`
+
+	// NoRevealMode skips the "reveal" phase and uses the commitments
+	// themselves as the entropy of each Random Party. This is cheaper and
+	// simpler, but the result can be biased by the last committer (see
+	// [RandomPartyPrecompile]).
+	NoRevealMode bool `json:"noRevealMode,omitempty"`
+
+	// AutoComputeOnStart makes start() compute a previous Random Party that
+	// is past its reveal deadline (so its result is finalized) instead of
+	// reverting because it was never computed.
+	AutoComputeOnStart bool `json:"autoComputeOnStart,omitempty"`
+
+	// RefundRevealGas is the gas returned to the caller for each successful
+	// reveal (0 disables refunds). It is capped at [RevealGasCost].
+	RefundRevealGas uint64 `json:"refundRevealGas,omitempty"`
+
+	// MaxTotalStake caps the stake locked by the commitments to a single
+	// Random Party (nil or 0 means there is no cap), which bounds the stake
+	// the precompile owes to participants at any time.
+	MaxTotalStake *big.Int `json:"maxTotalStake,omitempty"`
+
+	// AlignToEpoch is the length of an epoch in seconds (0 disables
+	// alignment). If set, the deadlines of each Random Party are rounded up
+	// to epoch boundaries (multiples of [AlignToEpoch]) rather than being
+	// relative to the time start() is called.
+	AlignToEpoch uint64 `json:"alignToEpoch,omitempty"`
+
+	// ResultCallback is a contract notified with the round and result each
+	// time a round is computed (the zero address disables the callback).
+	// [Verify] rejects precompiles.
+	ResultCallback common.Address `json:"resultCallback,omitempty"`
+}
+
+// RandomPartyGasCosts overrides the gas charged by Random Party methods (a
+// cost of 0 uses the default cost in params.go).
+type RandomPartyGasCosts struct {
+	Next    uint64 `json:"next"`
+	Result  uint64 `json:"result"`
+	Reward  uint64 `json:"reward"`
+	Sponsor uint64 `json:"sponsor"`
+}
+
+// Verify returns an error if [c] is invalid.
+func (c *RandomPartyConfig) Verify() error {
+	if err := checkCommitFee(c.MinCommitFee, c.CommitStake); err != nil {
+		return fmt.Errorf("invalid commitStake: %w", err)
+	}
+	switch c.ForfeitDestination {
+	case ForfeitToRevealers, ForfeitToPool, ForfeitToBurn:
+	case ForfeitToRecipient:
+		if c.ForfeitRecipient == (common.Address{}) {
+			return ErrNoForfeitRecipient
+		}
+		if isUsedAddress(c.ForfeitRecipient) {
+			return fmt.Errorf("%w: %s", ErrInvalidRecipient, c.ForfeitRecipient)
+		}
+	default:
+		return fmt.Errorf("invalid forfeitDestination: %w: %d", ErrUnknownForfeitDest, c.ForfeitDestination)
+	}
+	if _, err := c.CommitHashAlgo.Hash(nil); err != nil {
+		return fmt.Errorf("invalid commitHashAlgo: %w", err)
+	}
+	if c.NoRevealsBehavior != NoRevealsReject && c.NoRevealsBehavior != NoRevealsBlockHash {
+		return fmt.Errorf("invalid noRevealsBehavior: %w: %d", ErrUnknownNoReveals, c.NoRevealsBehavior)
+	}
+	if isUsedAddress(c.ResultCallback) {
+		return fmt.Errorf("invalid resultCallback: %w: %s", ErrInvalidRecipient, c.ResultCallback)
+	}
+	return nil
 }
 
 // Address returns the address of the Random Party contract.
@@ -101,9 +789,28 @@ func (c *RandomPartyConfig) Address() common.Address {
 // Timestamp returns the timestamp at which the Random Party should be enabled
 func (c *RandomPartyConfig) Timestamp() *big.Int { return c.BlockTimestamp }
 
+// ParticipationCost returns the [CommitStake] and the gas required to both
+// commit and reveal in a Random Party configured with [config], so callers can
+// display the total cost of participating without hardcoding gas constants.
+//
+// Note: the gas returned only covers the precompile's own execution (the
+// intrinsic gas of each transaction is not included).
+func ParticipationCost(config *RandomPartyConfig) (*big.Int, uint64) {
+	stake := new(big.Int)
+	if config.CommitStake != nil {
+		stake.Set(config.CommitStake)
+	}
+	return stake, CommitGasCost + RevealGasCost
+}
+
 // SetPhaseSeconds persists the configuration for "commit" and "reveal"
 // duration to the [StateDB].
+//
+// A nil [duration] is stored as 0, which prevents start from being called.
 func SetPhaseSeconds(state StateDB, duration *big.Int) {
+	if duration == nil {
+		duration = common.Big0
+	}
 	setBig(state, phaseSecondsKey, duration)
 }
 
@@ -113,10 +820,282 @@ func SetCommitStake(state StateDB, fee *big.Int) {
 	setBig(state, commitStakeKey, fee)
 }
 
+// SetForfeitDestination persists the configuration for where forfeited
+// [CommitStake] is sent to the [StateDB].
+func SetForfeitDestination(state StateDB, dest ForfeitDestination) {
+	setBig(state, forfeitDestinationKey, new(big.Int).SetUint64(uint64(dest)))
+}
+
+// SetForfeitRecipient persists the recipient of forfeited stakes (if
+// [ForfeitDestination] is [ForfeitToRecipient]) to the [StateDB].
+func SetForfeitRecipient(state StateDB, recipient common.Address) {
+	state.SetState(RandomPartyAddress, common.BytesToHash(forfeitRecipientKey), recipient.Hash())
+}
+
+// SetNoRevealMode persists whether the "reveal" phase is skipped to the
+// [StateDB].
+func SetNoRevealMode(state StateDB, enabled bool) {
+	setBool(state, noRevealModeKey, enabled)
+}
+
+// SetRefundRevealGas persists the gas refunded for each reveal to the
+// [StateDB].
+func SetRefundRevealGas(state StateDB, refund uint64) {
+	setBig(state, refundRevealGasKey, new(big.Int).SetUint64(refund))
+}
+
+// SetMaxTotalStake persists the cap on the stake locked by a Random Party to
+// the [StateDB].
+func SetMaxTotalStake(state StateDB, max *big.Int) {
+	if max == nil {
+		max = common.Big0
+	}
+	setBig(state, maxTotalStakeKey, max)
+}
+
+// SetAlignToEpoch persists the epoch length deadlines are aligned to to the
+// [StateDB].
+func SetAlignToEpoch(state StateDB, epoch uint64) {
+	setBig(state, alignToEpochKey, new(big.Int).SetUint64(epoch))
+}
+
+// SetResultCallback persists the contract notified when a round is computed
+// to the [StateDB].
+func SetResultCallback(state StateDB, callback common.Address) {
+	state.SetState(RandomPartyAddress, common.BytesToHash(resultCallbackKey), callback.Hash())
+}
+
+// getResultCallback returns the contract notified when a round is computed
+// (or the zero address if there is none).
+func getResultCallback(state StateDB) common.Address {
+	return common.BytesToAddress(state.GetState(RandomPartyAddress, common.BytesToHash(resultCallbackKey)).Bytes())
+}
+
+// SetAutoComputeOnStart persists whether start() computes an expired Random
+// Party to the [StateDB].
+func SetAutoComputeOnStart(state StateDB, enabled bool) {
+	setBool(state, autoComputeOnStartKey, enabled)
+}
+
+// SetStakeFromBalance persists whether [CommitStake] can be drawn from the
+// balance of the committer to the [StateDB].
+func SetStakeFromBalance(state StateDB, enabled bool) {
+	setBool(state, stakeFromBalanceKey, enabled)
+}
+
+// SetMaxReveals persists the maximum number of reveals per round to the
+// [StateDB].
+func SetMaxReveals(state StateDB, max uint64) {
+	setBig(state, maxRevealsKey, new(big.Int).SetUint64(max))
+}
+
+// SetCommitHashAlgo persists the hash function used to verify reveals to the
+// [StateDB].
+func SetCommitHashAlgo(state StateDB, algo CommitHashAlgo) {
+	setBig(state, commitHashAlgoKey, new(big.Int).SetUint64(uint64(algo)))
+}
+
+// GetCommitHashAlgo returns the hash function used to verify reveals.
+func GetCommitHashAlgo(state StateDB) CommitHashAlgo {
+	return CommitHashAlgo(getBig(state, commitHashAlgoKey).Uint64())
+}
+
+// SetComputeByRevealersOnly persists whether compute is restricted to
+// revealers to the [StateDB].
+func SetComputeByRevealersOnly(state StateDB, enabled bool) {
+	setBool(state, computeByRevealersOnlyKey, enabled)
+}
+
+// SetGasCosts persists the gas cost overrides of Random Party methods to the
+// [StateDB].
+func SetGasCosts(state StateDB, costs RandomPartyGasCosts) {
+	setBig(state, nextGasCostKey, new(big.Int).SetUint64(costs.Next))
+	setBig(state, resultGasCostKey, new(big.Int).SetUint64(costs.Result))
+	setBig(state, rewardGasCostKey, new(big.Int).SetUint64(costs.Reward))
+	setBig(state, sponsorGasCostKey, new(big.Int).SetUint64(costs.Sponsor))
+}
+
+// SetRevealBonus persists the bonus paid to each revealer to the [StateDB].
+func SetRevealBonus(state StateDB, bonus *big.Int) {
+	setBig(state, revealBonusKey, bonus)
+}
+
+// SetEmitResultConsumed persists whether result() emits a ResultConsumed log
+// to the [StateDB].
+func SetEmitResultConsumed(state StateDB, enabled bool) {
+	setBool(state, emitResultConsumedKey, enabled)
+}
+
+// SetMaxCommitsPerAddress persists the maximum number of commitments per
+// address per round to the [StateDB].
+func SetMaxCommitsPerAddress(state StateDB, max uint64) {
+	setBig(state, maxCommitsPerAddressKey, new(big.Int).SetUint64(max))
+}
+
+// SetMaxPayoutsPerCompute persists the maximum number of rewards paid by
+// compute to the [StateDB].
+func SetMaxPayoutsPerCompute(state StateDB, max uint64) {
+	setBig(state, maxPayoutsPerComputeKey, new(big.Int).SetUint64(max))
+}
+
+// SetStakeWeighted persists whether rewards are weighted by stake to the
+// [StateDB].
+func SetStakeWeighted(state StateDB, enabled bool) {
+	setBool(state, stakeWeightedKey, enabled)
+}
+
+// SetNoRevealsBehavior persists how compute handles a round without reveals
+// to the [StateDB].
+func SetNoRevealsBehavior(state StateDB, behavior NoRevealsBehavior) {
+	setBig(state, noRevealsBehaviorKey, new(big.Int).SetUint64(uint64(behavior)))
+}
+
+// SetDisableBlockTimestamp persists the time at which the Random Party is
+// disabled to the [StateDB] (nil never disables it).
+func SetDisableBlockTimestamp(state StateDB, timestamp *big.Int) {
+	if timestamp == nil {
+		timestamp = common.Big0
+	}
+	setBig(state, disableTimestampKey, timestamp)
+}
+
+// SetSponsorUntilRevealDeadline persists whether sponsor can be called during
+// the "reveal" phase to the [StateDB].
+func SetSponsorUntilRevealDeadline(state StateDB, enabled bool) {
+	setBool(state, sponsorUntilRevealKey, enabled)
+}
+
+// SetRestrictRewardView persists whether reward() is limited to addresses
+// enabled on the Random Party allow list to the [StateDB].
+func SetRestrictRewardView(state StateDB, enabled bool) {
+	setBool(state, restrictRewardViewKey, enabled)
+}
+
+// SetMinCommitFee persists the floor of [CommitStake] to the [StateDB].
+func SetMinCommitFee(state StateDB, fee *big.Int) {
+	if fee == nil {
+		fee = common.Big0
+	}
+	setBig(state, minCommitFeeKey, fee)
+}
+
+// getForfeitRecipient returns the recipient of forfeited stakes if
+// [ForfeitDestination] is [ForfeitToRecipient].
+func getForfeitRecipient(state StateDB) common.Address {
+	return common.BytesToAddress(state.GetState(RandomPartyAddress, common.BytesToHash(forfeitRecipientKey)).Bytes())
+}
+
+// hasForfeitRecipient returns true if the recipient of forfeited stakes can
+// receive them (otherwise they are added to the incentive pool).
+func hasForfeitRecipient(state StateDB) bool {
+	recipient := getForfeitRecipient(state)
+	return recipient != (common.Address{}) && !isUsedAddress(recipient)
+}
+
+// checkCommitFee returns [ErrCommitFeeBelowMin] if [fee] is below [min]
+// (nil is treated as 0).
+func checkCommitFee(min *big.Int, fee *big.Int) error {
+	if min == nil || min.Sign() == 0 {
+		return nil
+	}
+	if fee == nil || fee.Cmp(min) < 0 {
+		return fmt.Errorf("%w: %d < %d", ErrCommitFeeBelowMin, fee, min)
+	}
+	return nil
+}
+
+// SetRewardsDisabled persists whether rewards are disabled to the [StateDB].
+func SetRewardsDisabled(state StateDB, disabled bool) {
+	setBool(state, rewardsDisabledKey, disabled)
+}
+
+// SetMaxStoredState persists the maximum number of entries stored by the
+// Random Party to the [StateDB].
+func SetMaxStoredState(state StateDB, max uint64) {
+	setBig(state, maxStoredStateKey, new(big.Int).SetUint64(max))
+}
+
+// SetComputeBounty persists the bounty paid to the caller of compute and the
+// amount it decays by each second after the reveal deadline to the [StateDB]
+// (nil is treated as 0).
+func SetComputeBounty(state StateDB, bounty *big.Int, decayRate *big.Int) {
+	if bounty == nil {
+		bounty = common.Big0
+	}
+	if decayRate == nil {
+		decayRate = common.Big0
+	}
+	setBig(state, computeBountyKey, bounty)
+	setBig(state, bountyDecayRateKey, decayRate)
+}
+
+// SetFirstRevealBonus persists the bonus paid for the first reveal of each
+// round to the [StateDB] (nil is treated as 0).
+func SetFirstRevealBonus(state StateDB, bonus *big.Int) {
+	if bonus == nil {
+		bonus = common.Big0
+	}
+	setBig(state, firstRevealBonusKey, bonus)
+}
+
 // Configure initializes the address space of [RandomPartyAddress].
 func (c *RandomPartyConfig) Configure(state StateDB) {
 	SetPhaseSeconds(state, c.PhaseSeconds)
 	SetCommitStake(state, c.CommitStake)
+	SetForfeitDestination(state, c.ForfeitDestination)
+	SetStakeFromBalance(state, c.StakeFromBalance)
+	SetMaxReveals(state, c.MaxReveals)
+	SetCommitHashAlgo(state, c.CommitHashAlgo)
+	SetComputeByRevealersOnly(state, c.ComputeByRevealersOnly)
+	SetGasCosts(state, c.GasCosts)
+	SetEmitResultConsumed(state, c.EmitResultConsumed)
+	SetMaxCommitsPerAddress(state, c.MaxCommitsPerAddress)
+	SetMaxPayoutsPerCompute(state, c.MaxPayoutsPerCompute)
+	SetStakeWeighted(state, c.StakeWeighted)
+	SetNoRevealsBehavior(state, c.NoRevealsBehavior)
+	SetDisableBlockTimestamp(state, c.DisableBlockTimestamp)
+	SetSponsorUntilRevealDeadline(state, c.SponsorUntilRevealDeadline)
+	SetComputeBounty(state, c.ComputeBounty, c.BountyDecayRate)
+	SetFirstRevealBonus(state, c.FirstRevealBonus)
+	SetRestrictRewardView(state, c.RestrictRewardView)
+	SetMaxStoredState(state, c.MaxStoredState)
+	SetRewardsDisabled(state, c.RewardsDisabled)
+	SetMinCommitFee(state, c.MinCommitFee)
+	SetForfeitRecipient(state, c.ForfeitRecipient)
+	SetNoRevealMode(state, c.NoRevealMode)
+	SetAutoComputeOnStart(state, c.AutoComputeOnStart)
+	SetRefundRevealGas(state, c.RefundRevealGas)
+	SetMaxTotalStake(state, c.MaxTotalStake)
+	SetAlignToEpoch(state, c.AlignToEpoch)
+	SetResultCallback(state, c.ResultCallback)
+	if c.RevealBonus != nil {
+		SetRevealBonus(state, c.RevealBonus)
+	}
+	SetRandomPartySchemaVersion(state, RandomPartySchemaVersion)
+	(&AllowListConfig{AllowListAdmins: c.AllowListAdmins, EnabledAddresses: c.EnabledAddresses}).Configure(state, RandomPartyAddress)
+}
+
+// GetRandomPartyStatus returns the role of [address] for the Random Party
+// allow list.
+func GetRandomPartyStatus(stateDB StateDB, address common.Address) AllowListRole {
+	return getAllowListStatus(stateDB, RandomPartyAddress, address)
+}
+
+// SetRandomPartyStatus sets the permissions of [address] to [role] for the
+// Random Party allow list. assumes [role] has already been verified as valid.
+func SetRandomPartyStatus(stateDB StateDB, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, RandomPartyAddress, address, role)
+}
+
+// GetResult returns the result of [round] as read by result() (without
+// charging gas or emitting a ResultConsumed log), so it can be read directly
+// from a state snapshot. The empty hash is returned if [round] has not been
+// computed.
+func GetResult(stateDB StateDB, round *big.Int) common.Hash {
+	if round.Cmp(getBig(stateDB, resultPrefix)) >= 0 {
+		return common.Hash{}
+	}
+	return getCounterHash(stateDB, resultPrefix, round)
 }
 
 // Contract returns the singleton stateful precompiled contract to be used for
@@ -136,28 +1115,258 @@ var (
 	commitStakeKey    = []byte{0x7}
 	commitOwnerPrefix = []byte{0x8}
 	rewardPrefix      = []byte{0x9}
+
+	forfeitDestinationKey = []byte{0xa}
+	schemaVersionKey      = []byte{0xb}
+	partyRoundKey         = []byte{0xc}
+	stakeFromBalanceKey   = []byte{0xd}
+	startTimeKey          = []byte{0xe}
+	maxRevealsKey         = []byte{0xf}
+	commitHashAlgoKey     = []byte{0x10}
+
+	computeByRevealersOnlyKey = []byte{0x11}
+	nextGasCostKey            = []byte{0x12}
+	resultGasCostKey          = []byte{0x13}
+	rewardGasCostKey          = []byte{0x14}
+	sponsorGasCostKey         = []byte{0x15}
+	revealBonusKey            = []byte{0x16}
+	revealBonusPaidKey        = []byte{0x17}
+	emitResultConsumedKey     = []byte{0x18}
+	commitCountPrefix         = []byte{0x19}
+	maxCommitsPerAddressKey   = []byte{0x1a}
+	sponsoredPrefix           = []byte{0x1b}
+	claimablePrefix           = []byte{0x1c}
+	maxPayoutsPerComputeKey   = []byte{0x1d}
+	commitStakePrefix         = []byte{0x1e}
+	revealStakePrefix         = []byte{0x1f}
+	stakeWeightedKey          = []byte{0x20}
+	noRevealsBehaviorKey      = []byte{0x21}
+	disableTimestampKey       = []byte{0x22}
+	participantPrefix         = []byte{0x23}
+	participantSeenPrefix     = []byte{0x24}
+	sponsorUntilRevealKey     = []byte{0x25}
+	computeBountyKey          = []byte{0x26}
+	bountyDecayRateKey        = []byte{0x27}
+	firstRevealBonusKey       = []byte{0x28}
+	roundCommitFeePrefix      = []byte{0x29}
+	restrictRewardViewKey     = []byte{0x2a}
+	maxStoredStateKey         = []byte{0x2b}
+	rewardsDisabledKey        = []byte{0x2c}
+	minCommitFeeKey           = []byte{0x2d}
+	forfeitRecipientKey       = []byte{0x2e}
+	noRevealModeKey           = []byte{0x2f}
+	lastForfeitCountKey       = []byte{0x30}
+	autoComputeOnStartKey     = []byte{0x31}
+	rewardPerRevealerPrefix   = []byte{0x32}
+	refundRevealGasKey        = []byte{0x33}
+	sponsorCountKey           = []byte{0x34}
+	sponsorSeenPrefix         = []byte{0x35}
+	startCountKey             = []byte{0x36}
+	maxTotalStakeKey          = []byte{0x37}
+	alignToEpochKey           = []byte{0x38}
+	resultCallbackKey         = []byte{0x39}
+	pauseFlagsKey             = []byte{0x3a}
 )
 
-func fastKey(pfx []byte, n *big.Int) common.Hash {
-	val := n.Bytes()
-	b := make([]byte, len(pfx)+1+len(val))
+// partyKeys are the prefixes of the per-index entries of a single Random
+// Party round.
+//
+// Each prefix is namespaced by the round of the Random Party so that entries
+// of different rounds never share a storage slot (the round is encoded with
+// a fixed width to ensure no namespaced prefix is a prefix of another).
+type partyKeys struct {
+	commits    []byte
+	owners     []byte
+	reveals    []byte
+	recipients []byte
+
+	// commitCounts is the prefix of the number of commitments made by each
+	// address (only tracked if [MaxCommitsPerAddress] is set)
+	commitCounts []byte
+
+	// commitStakes and revealStakes are the prefixes of the stake locked by
+	// each commitment and reveal (only tracked if [StakeWeighted] is set). The
+	// total stake of all unrevealed commitments (always tracked) is stored at
+	// [commitStakes] and the total stake of all reveals is stored at
+	// [revealStakes].
+	commitStakes []byte
+	revealStakes []byte
+
+	// participants is the prefix of the distinct owners of commitments (in
+	// the order they first committed) and participantSeen is the prefix of
+	// whether each address is in [participants].
+	participants    []byte
+	participantSeen []byte
+}
+
+func newPartyKeys(round *big.Int) partyKeys {
+	return partyKeys{
+		commits:    partyPrefix(commitPrefix, round),
+		owners:     partyPrefix(commitOwnerPrefix, round),
+		reveals:    partyPrefix(revealPrefix, round),
+		recipients: partyPrefix(rewardPrefix, round),
+
+		commitCounts: partyPrefix(commitCountPrefix, round),
+		commitStakes: partyPrefix(commitStakePrefix, round),
+		revealStakes: partyPrefix(revealStakePrefix, round),
+
+		participants:    partyPrefix(participantPrefix, round),
+		participantSeen: partyPrefix(participantSeenPrefix, round),
+	}
+}
+
+// RandomPartyList identifies a list of entries stored by the Random Party
+// precompile (see [RandomPartyStorageKey]).
+type RandomPartyList uint64
+
+const (
+	// RandomPartyCommitments are the hashes committed in a round (the entry
+	// of a commitment is cleared when it is revealed).
+	RandomPartyCommitments RandomPartyList = iota
+	// RandomPartyCommitOwners are the owners of the commitments in a round.
+	RandomPartyCommitOwners
+	// RandomPartyReveals are the preimages revealed in a round.
+	RandomPartyReveals
+	// RandomPartyRevealRecipients are the addresses rewarded for the reveals
+	// in a round.
+	RandomPartyRevealRecipients
+	// RandomPartyResults are the results of all computed rounds (this list is
+	// not namespaced by round).
+	RandomPartyResults
+)
+
+// prefix returns the prefix of the entries of [l] in [round].
+func (l RandomPartyList) prefix(round *big.Int) ([]byte, error) {
+	keys := newPartyKeys(round)
+	switch l {
+	case RandomPartyCommitments:
+		return keys.commits, nil
+	case RandomPartyCommitOwners:
+		return keys.owners, nil
+	case RandomPartyReveals:
+		return keys.reveals, nil
+	case RandomPartyRevealRecipients:
+		return keys.recipients, nil
+	case RandomPartyResults:
+		return resultPrefix, nil
+	default:
+		return nil, fmt.Errorf("unknown Random Party list: %d", l)
+	}
+}
+
+// RandomPartyStorageKey returns the storage slot of [RandomPartyAddress] that
+// holds entry [idx] of [list] in [round], so tooling can read entries directly
+// (e.g. with eth_getStorageAt). The round of the current Random Party is the
+// value returned by next() when it was started ([round] is ignored for
+// [RandomPartyResults]).
+//
+// Hashes are stored as is, addresses are left-padded to 32 bytes, and a zero
+// slot means the entry does not exist (or was cleared).
+func RandomPartyStorageKey(list RandomPartyList, round *big.Int, idx *big.Int) (common.Hash, error) {
+	pfx, err := list.prefix(round)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return fastKey(pfx, idx), nil
+}
+
+// RandomPartyLengthKey returns the storage slot of [RandomPartyAddress] that
+// holds the number of entries ever added to [list] in [round] (cleared entries
+// are still counted).
+func RandomPartyLengthKey(list RandomPartyList, round *big.Int) (common.Hash, error) {
+	pfx, err := list.prefix(round)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(pfx), nil
+}
+
+// currentPartyKeys returns the [partyKeys] of the latest Random Party that was
+// started.
+func currentPartyKeys(state StateDB) partyKeys {
+	return newPartyKeys(getBig(state, partyRoundKey))
+}
+
+// buildKey returns [pfx], [delim], and [suffix] concatenated into a newly
+// allocated slice of the exact size. Keys are never built by appending to
+// [pfx], which may share its backing array with other keys (e.g. the prefixes
+// of [partyKeys]) that an append could overwrite.
+func buildKey(pfx []byte, suffix []byte) []byte {
+	b := make([]byte, len(pfx)+1+len(suffix))
 	copy(b, pfx)
 	b[len(pfx)] = delim
-	copy(b[len(pfx)+1:], val)
-	return common.BytesToHash(b)
+	copy(b[len(pfx)+1:], suffix)
+	return b
+}
+
+func partyPrefix(pfx []byte, round *big.Int) []byte {
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], round.Uint64())
+	return buildKey(pfx, r[:])
+}
+
+// addrKey returns the storage key of the entry of [addr] under [pfx].
+func addrKey(pfx []byte, addr common.Address) common.Hash {
+	return common.BytesToHash(buildKey(pfx, addr.Bytes()))
+}
+
+func fastKey(pfx []byte, n *big.Int) common.Hash {
+	return common.BytesToHash(buildKey(pfx, n.Bytes()))
 }
 
-func transfer(state StateDB, dest common.Address, amount *big.Int) {
+// transfer moves [amount] from the balance of [RandomPartyAddress] (which
+// holds all stakes and sponsored funds) to [dest]. Transfers to stateful
+// precompiles fail with [ErrInvalidRecipient] to avoid crediting the balance
+// of a precompile by accident.
+//
+// Recipients are recorded when they commit or reveal, so [dest] may have been
+// deleted (e.g. a contract that self-destructed) by the time it is paid. Its
+// account is then re-created with the transferred balance, like any transfer
+// to an address without an account, so the funds are never lost.
+func transfer(state StateDB, dest common.Address, amount *big.Int) error {
+	if isUsedAddress(dest) {
+		return fmt.Errorf("%w: %s", ErrInvalidRecipient, dest)
+	}
+	if amount.Sign() == 0 {
+		return nil
+	}
+	if balance := state.GetBalance(RandomPartyAddress); balance.Cmp(amount) < 0 {
+		return fmt.Errorf("%w: cannot pay %d with balance %d", ErrInsufficientBalance, amount, balance)
+	}
+	state.SubBalance(RandomPartyAddress, amount)
 	if !state.Exist(dest) {
-		state.CreateAccount(dest) // could've been deleted between interactions
+		state.CreateAccount(dest)
 	}
-	state.AddBalance(dest, getBig(state, commitStakeKey))
+	state.AddBalance(dest, amount)
+	return nil
 }
 
 func HBigBytes(b *big.Int) []byte {
 	return common.BigToHash(b).Bytes()
 }
 
+// getDeadlines returns the deadlines of the current Random Party and whether
+// a Random Party is underway. start sets both deadlines and compute clears
+// both, so a pair with only one zero deadline can only come from corrupted
+// state and is treated as if no Random Party was started.
+func getDeadlines(state StateDB) (*big.Int, *big.Int, bool) {
+	commitDeadline := getBig(state, commitDeadlineKey)
+	revealDeadline := getBig(state, revealDeadlineKey)
+	if commitDeadline.Sign() == 0 || revealDeadline.Sign() == 0 {
+		return nil, nil, false
+	}
+	return commitDeadline, revealDeadline, true
+}
+
+// getGasCost returns the gas cost override stored at [key] or [defaultCost] if
+// no override is set.
+func getGasCost(state StateDB, key []byte, defaultCost uint64) uint64 {
+	if cost := getBig(state, key); cost.Sign() != 0 {
+		return cost.Uint64()
+	}
+	return defaultCost
+}
+
 // *math.Big setter/getter
 func setBig(state StateDB, key []byte, val *big.Int) {
 	state.SetState(RandomPartyAddress, common.BytesToHash(key), common.BigToHash(val))
@@ -167,6 +1376,18 @@ func getBig(state StateDB, key []byte) *big.Int {
 	return new(big.Int).SetBytes(h.Bytes())
 }
 
+// bool setter/getter
+func setBool(state StateDB, key []byte, val bool) {
+	if val {
+		setBig(state, key, common.Big1)
+	} else {
+		setBig(state, key, common.Big0)
+	}
+}
+func getBool(state StateDB, key []byte) bool {
+	return getBig(state, key).Sign() != 0
+}
+
 // counter commmon.Hash setter/getter/deleter
 func addCounterHash(state StateDB, pfx []byte, hash common.Hash) *big.Int {
 	currV := getBig(state, pfx)
@@ -194,310 +1415,2201 @@ func deleteIdxAddress(state StateDB, pfx []byte, idx *big.Int) {
 	state.SetState(RandomPartyAddress, fastKey(pfx, idx), common.Hash{})
 }
 
+// per-address *big.Int setter/getter
+func setAddrBig(state StateDB, pfx []byte, addr common.Address, val *big.Int) {
+	state.SetState(RandomPartyAddress, addrKey(pfx, addr), common.BigToHash(val))
+}
+func getAddrBig(state StateDB, pfx []byte, addr common.Address) *big.Int {
+	return state.GetState(RandomPartyAddress, addrKey(pfx, addr)).Big()
+}
+
 // packers/unpackers
+//
+// Methods without arguments are packed as a copy of their selector, so the
+// shared signatures can never be modified through a packed input.
+func PackSponsor() []byte {
+	return append(make([]byte, 0, selectorLen), SponsorSignature...)
+}
+func PackReward() []byte {
+	return append(make([]byte, 0, selectorLen), RewardSignature...)
+}
+func PackNext() []byte {
+	return append(make([]byte, 0, selectorLen), NextSignature...)
+}
+func PackCompute() []byte {
+	return append(make([]byte, 0, selectorLen), ComputeSignature...)
+}
+
+// UnpackReward decodes the output of reward().
+func UnpackReward(ret []byte) (*big.Int, error) {
+	if len(ret) != common.HashLength {
+		return nil, fmt.Errorf("invalid output length for reward: %d", len(ret))
+	}
+	return new(big.Int).SetBytes(ret), nil
+}
+
 func PackCommit(hash common.Hash) []byte {
-	return append(CommitSignature, hash.Bytes()...)
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, CommitSignature...)
+	input = append(input, hash.Bytes()...)
+	return input
 }
 func UnpackCommit(input []byte) (common.Hash, error) {
 	if len(input) != common.HashLength {
-		return common.Hash{}, fmt.Errorf("invalid input length for commit: %d", len(input))
+		return common.Hash{}, invalidInputLength("commit", common.HashLength, len(input))
 	}
 	return common.BytesToHash(input), nil
 }
 func PackReveal(v *big.Int, hash common.Hash) []byte {
-	r := append(RevealSignature, common.BigToHash(v).Bytes()...)
-	return append(r, hash.Bytes()...)
+	input := make([]byte, 0, selectorLen+common.HashLength*2)
+	input = append(input, RevealSignature...)
+	input = append(input, common.BigToHash(v).Bytes()...)
+	input = append(input, hash.Bytes()...)
+	return input
+}
+func PackRevealAndClaim(v *big.Int, hash common.Hash) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength*2)
+	input = append(input, RevealAndClaimSignature...)
+	input = append(input, common.BigToHash(v).Bytes()...)
+	input = append(input, hash.Bytes()...)
+	return input
+}
+func UnpackRevealAndClaim(input []byte) (*big.Int, common.Hash, error) {
+	if len(input) != common.HashLength*2 {
+		return nil, common.Hash{}, invalidInputLength("revealAndClaim", common.HashLength*2, len(input))
+	}
+	return new(big.Int).SetBytes(input[:common.HashLength]), common.BytesToHash(input[common.HashLength:]), nil
 }
 func UnpackReveal(input []byte) (*big.Int, common.Hash, error) {
 	if len(input) != common.HashLength*2 {
-		return nil, common.Hash{}, fmt.Errorf("invalid input length for reveal: %d", len(input))
+		return nil, common.Hash{}, invalidInputLength("reveal", common.HashLength*2, len(input))
 	}
 	return new(big.Int).SetBytes(input[:common.HashLength]), common.BytesToHash(input[common.HashLength:]), nil
 }
 func PackResult(v *big.Int) []byte {
-	return append(ResultSignature, common.BigToHash(v).Bytes()...)
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, ResultSignature...)
+	input = append(input, common.BigToHash(v).Bytes()...)
+	return input
 }
 func UnpackResult(input []byte) (*big.Int, error) {
 	if len(input) != common.HashLength {
-		return nil, fmt.Errorf("invalid input length for result: %d", len(input))
+		return nil, invalidInputLength("result", common.HashLength, len(input))
 	}
 	return new(big.Int).SetBytes(input), nil
 }
 
-func start(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, StartGasCost); err != nil {
-		return nil, 0, err
+// PackRevealBatch packs [indices] and [preimages] as the ABI encoding of
+// revealBatch(uint256[],bytes32[]).
+func PackRevealBatch(indices []*big.Int, preimages []common.Hash) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength*(4+len(indices)+len(preimages)))
+	input = append(input, RevealBatchSignature[:selectorLen]...)
+	input = append(input, HBigBytes(big.NewInt(2*common.HashLength))...)
+	input = append(input, HBigBytes(big.NewInt(int64(common.HashLength*(3+len(indices)))))...)
+	input = append(input, HBigBytes(big.NewInt(int64(len(indices))))...)
+	for _, idx := range indices {
+		input = append(input, HBigBytes(idx)...)
 	}
-
-	if len(input) != 0 {
-		return nil, remainingGas, fmt.Errorf("invalid input length for start: %d", len(input))
+	input = append(input, HBigBytes(big.NewInt(int64(len(preimages))))...)
+	for _, preimage := range preimages {
+		input = append(input, preimage.Bytes()...)
 	}
+	return input
+}
 
-	stateDB := evm.GetStateDB()
-	commitDeadline := getBig(stateDB, commitDeadlineKey)
-	if commitDeadline.Sign() != 0 {
-		return nil, remainingGas, ErrRandomPartyUnderway
+// UnpackRevealBatch unpacks the ABI encoded arguments of
+// revealBatch(uint256[],bytes32[]) (the arrays must be non-empty and of equal
+// length).
+func UnpackRevealBatch(input []byte) ([]*big.Int, []common.Hash, error) {
+	if len(input) < 2*common.HashLength {
+		return nil, nil, fmt.Errorf("%w for revealBatch: expected at least %d, got %d", ErrInvalidInputLength, 2*common.HashLength, len(input))
 	}
-
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
+	indexWords, err := unpackWordArray(input, input[:common.HashLength])
+	if err != nil {
+		return nil, nil, err
 	}
-
-	// Cleanup old commits and reveals
-	commits := getBig(stateDB, commitPrefix)
-	for i := common.Big0; i.Cmp(commits) < 0; i = new(big.Int).Add(i, common.Big1) {
-		if remainingGas, err = deductGas(remainingGas, DeleteGasCost); err != nil {
-			return nil, 0, err
-		}
-		deleteCounterHash(stateDB, commitPrefix, i)
-		deleteIdxAddress(stateDB, commitOwnerPrefix, i)
+	preimageWords, err := unpackWordArray(input, input[common.HashLength:2*common.HashLength])
+	if err != nil {
+		return nil, nil, err
 	}
-	setBig(stateDB, commitPrefix, common.Big0)
-	reveals := getBig(stateDB, revealPrefix)
-	for i := common.Big0; i.Cmp(reveals) < 0; i = new(big.Int).Add(i, common.Big1) {
-		if remainingGas, err = deductGas(remainingGas, DeleteGasCost); err != nil {
-			return nil, 0, err
-		}
-		deleteCounterHash(stateDB, revealPrefix, i)
-		deleteIdxAddress(stateDB, rewardPrefix, i)
+	if len(indexWords) == 0 || len(indexWords) != len(preimageWords) {
+		return nil, nil, fmt.Errorf("invalid array lengths for revealBatch: %d indices and %d preimages", len(indexWords), len(preimageWords))
 	}
-	setBig(stateDB, revealPrefix, common.Big0)
 
-	// Set phase deadlines
-	phaseDuration := getBig(stateDB, phaseSecondsKey)
-	commitDeadline = new(big.Int).Add(evm.BlockTime(), phaseDuration)
-	setBig(stateDB, commitDeadlineKey, commitDeadline)
-	setBig(stateDB, revealDeadlineKey, new(big.Int).Add(commitDeadline, phaseDuration))
-	return []byte{}, remainingGas, nil
+	indices := make([]*big.Int, len(indexWords))
+	preimages := make([]common.Hash, len(preimageWords))
+	for i := range indexWords {
+		indices[i] = new(big.Int).SetBytes(indexWords[i])
+		preimages[i] = common.BytesToHash(preimageWords[i])
+	}
+	return indices, preimages, nil
 }
 
-func sponsor(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, SponsorGasCost); err != nil {
+// unpackWordArray returns the 32 byte words of the dynamic array in [input]
+// at the offset encoded in [offsetWord].
+func unpackWordArray(input []byte, offsetWord []byte) ([][]byte, error) {
+	offset := new(big.Int).SetBytes(offsetWord)
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(input)-common.HashLength) {
+		return nil, fmt.Errorf("invalid array offset: %d", offset)
+	}
+	start := offset.Uint64() + common.HashLength
+	length := new(big.Int).SetBytes(input[offset.Uint64():start])
+	if !length.IsUint64() || length.Uint64() > (uint64(len(input))-start)/common.HashLength {
+		return nil, fmt.Errorf("invalid array length: %d", length)
+	}
+	words := make([][]byte, length.Uint64())
+	for i := range words {
+		words[i] = input[start+uint64(i)*common.HashLength : start+uint64(i+1)*common.HashLength]
+	}
+	return words, nil
+}
+
+func PackComputableAt(timestamp *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, ComputableAtSignature...)
+	input = append(input, common.BigToHash(timestamp).Bytes()...)
+	return input
+}
+func UnpackComputableAt(input []byte) (*big.Int, error) {
+	if len(input) != common.HashLength {
+		return nil, invalidInputLength("computableAt", common.HashLength, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+func PackLockedStake(committer common.Address) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, LockedStakeSignature...)
+	input = append(input, committer.Hash().Bytes()...)
+	return input
+}
+func UnpackLockedStake(input []byte) (common.Address, error) {
+	if len(input) != common.HashLength {
+		return common.Address{}, invalidInputLength("lockedStake", common.HashLength, len(input))
+	}
+	return common.BytesToAddress(input), nil
+}
+
+func PackCommitSigned(hash common.Hash, v uint8, r common.Hash, s common.Hash) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength*4)
+	input = append(input, CommitSignedSignature...)
+	input = append(input, hash.Bytes()...)
+	input = append(input, common.BigToHash(new(big.Int).SetUint64(uint64(v))).Bytes()...)
+	input = append(input, r.Bytes()...)
+	input = append(input, s.Bytes()...)
+	return input
+}
+func UnpackCommitSigned(input []byte) (common.Hash, uint8, common.Hash, common.Hash, error) {
+	if len(input) != common.HashLength*4 {
+		return common.Hash{}, 0, common.Hash{}, common.Hash{}, invalidInputLength("commitSigned", common.HashLength*4, len(input))
+	}
+	v := new(big.Int).SetBytes(input[common.HashLength : common.HashLength*2])
+	if !v.IsUint64() || v.Uint64() > 255 {
+		return common.Hash{}, 0, common.Hash{}, common.Hash{}, fmt.Errorf("%w: v %d out of range", ErrInvalidSignature, v)
+	}
+	return common.BytesToHash(input[:common.HashLength]), uint8(v.Uint64()), common.BytesToHash(input[common.HashLength*2 : common.HashLength*3]), common.BytesToHash(input[common.HashLength*3:]), nil
+}
+
+// CommitDigest returns the EIP-712 digest that a participant must sign to
+// have [hash] committed on their behalf (with [commitSigned]) in [round].
+func CommitDigest(hash common.Hash, round *big.Int) common.Hash {
+	structHash := crypto.Keccak256Hash(commitTypeHash.Bytes(), hash.Bytes(), common.BigToHash(round).Bytes())
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, RandomPartyDomainSeparator.Bytes(), structHash.Bytes())
+}
+
+// recoverCommitSigner returns the address that signed [digest] (accepting
+// both 0/1 and 27/28 recovery ids and rejecting malleable signatures).
+func recoverCommitSigner(digest common.Hash, v uint8, r common.Hash, s common.Hash) (common.Address, error) {
+	if v >= 27 {
+		v -= 27
+	}
+	if !crypto.ValidateSignatureValues(v, r.Big(), s.Big(), true) {
+		return common.Address{}, ErrInvalidSignature
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig[:common.HashLength], r.Bytes())
+	copy(sig[common.HashLength:common.HashLength*2], s.Bytes())
+	sig[crypto.RecoveryIDOffset] = v
+	pub, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+func PackSetCommitFee(fee *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, SetCommitFeeSignature...)
+	input = append(input, common.BigToHash(fee).Bytes()...)
+	return input
+}
+func UnpackSetCommitFee(input []byte) (*big.Int, error) {
+	if len(input) != common.HashLength {
+		return nil, invalidInputLength("setCommitFee", common.HashLength, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+func PackSetPaused(flags PauseFlags) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, SetPausedSignature...)
+	input = append(input, common.BigToHash(new(big.Int).SetUint64(uint64(flags))).Bytes()...)
+	return input
+}
+func UnpackSetPaused(input []byte) (PauseFlags, error) {
+	if len(input) != common.HashLength {
+		return 0, invalidInputLength("setPaused", common.HashLength, len(input))
+	}
+	flags := new(big.Int).SetBytes(input)
+	if !flags.IsUint64() || PauseFlags(flags.Uint64())&^allPauseFlags != 0 {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidPauseFlags, flags)
+	}
+	return PauseFlags(flags.Uint64()), nil
+}
+
+func PackSponsoredTotal(round *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, SponsoredTotalSignature...)
+	input = append(input, common.BigToHash(round).Bytes()...)
+	return input
+}
+func UnpackSponsoredTotal(input []byte) (*big.Int, error) {
+	if len(input) != common.HashLength {
+		return nil, invalidInputLength("sponsoredTotal", common.HashLength, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+func PackClaimable(account common.Address) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, ClaimableSignature...)
+	input = append(input, account.Hash().Bytes()...)
+	return input
+}
+func UnpackClaimable(input []byte) (common.Address, error) {
+	if len(input) != common.HashLength {
+		return common.Address{}, invalidInputLength("claimable", common.HashLength, len(input))
+	}
+	return common.BytesToAddress(input), nil
+}
+
+func PackParticipants(offset *big.Int, limit *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength*2)
+	input = append(input, ParticipantsSignature...)
+	input = append(input, common.BigToHash(offset).Bytes()...)
+	input = append(input, common.BigToHash(limit).Bytes()...)
+	return input
+}
+func UnpackParticipants(input []byte) (*big.Int, *big.Int, error) {
+	if len(input) != common.HashLength*2 {
+		return nil, nil, invalidInputLength("participants", common.HashLength*2, len(input))
+	}
+	return new(big.Int).SetBytes(input[:common.HashLength]), new(big.Int).SetBytes(input[common.HashLength:]), nil
+}
+
+func PackWasRevealed(preimage common.Hash) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, WasRevealedSignature...)
+	input = append(input, preimage.Bytes()...)
+	return input
+}
+func UnpackWasRevealed(input []byte) (common.Hash, error) {
+	if len(input) != common.HashLength {
+		return common.Hash{}, invalidInputLength("wasRevealed", common.HashLength, len(input))
+	}
+	return common.BytesToHash(input), nil
+}
+
+func PackIsFinalized(round *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, IsFinalizedSignature...)
+	input = append(input, common.BigToHash(round).Bytes()...)
+	return input
+}
+func UnpackIsFinalized(input []byte) (*big.Int, error) {
+	if len(input) != common.HashLength {
+		return nil, invalidInputLength("isFinalized", common.HashLength, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+func PackCommitFeeOf(round *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, CommitFeeOfSignature...)
+	input = append(input, common.BigToHash(round).Bytes()...)
+	return input
+}
+func UnpackCommitFeeOf(input []byte) (*big.Int, error) {
+	if len(input) != common.HashLength {
+		return nil, invalidInputLength("commitFeeOf", common.HashLength, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+func PackResultFraction(round *big.Int, precision *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength*2)
+	input = append(input, ResultFractionSignature...)
+	input = append(input, common.BigToHash(round).Bytes()...)
+	input = append(input, common.BigToHash(precision).Bytes()...)
+	return input
+}
+func PackRewardPerRevealer(round *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, RewardPerRevealerSignature...)
+	input = append(input, common.BigToHash(round).Bytes()...)
+	return input
+}
+func UnpackRewardPerRevealer(input []byte) (*big.Int, error) {
+	if len(input) != common.HashLength {
+		return nil, invalidInputLength("rewardPerRevealer", common.HashLength, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+func PackRecentResults(n *big.Int) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, RecentResultsSignature...)
+	input = append(input, common.BigToHash(n).Bytes()...)
+	return input
+}
+func UnpackRecentResults(input []byte) (*big.Int, error) {
+	if len(input) != common.HashLength {
+		return nil, invalidInputLength("recentResults", common.HashLength, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+func UnpackResultFraction(input []byte) (*big.Int, *big.Int, error) {
+	if len(input) != common.HashLength*2 {
+		return nil, nil, invalidInputLength("resultFraction", common.HashLength*2, len(input))
+	}
+	return new(big.Int).SetBytes(input[:common.HashLength]), new(big.Int).SetBytes(input[common.HashLength:]), nil
+}
+
+// RandomPartySnapshot is the state of the current Random Party returned by
+// snapshot().
+type RandomPartySnapshot struct {
+	Phase          RandomPartyPhase
+	Commits        *big.Int
+	Reveals        *big.Int
+	CommitDeadline *big.Int
+	RevealDeadline *big.Int
+	Reward         *big.Int
+	Round          *big.Int
+}
+
+// snapshotLen is the number of words returned by snapshot().
+const snapshotLen = 7
+
+// UnpackSnapshot decodes the output of snapshot().
+func UnpackSnapshot(ret []byte) (RandomPartySnapshot, error) {
+	if len(ret) != common.HashLength*snapshotLen {
+		return RandomPartySnapshot{}, fmt.Errorf("invalid output length for snapshot: %d", len(ret))
+	}
+	words := make([]*big.Int, snapshotLen)
+	for i := range words {
+		words[i] = new(big.Int).SetBytes(ret[i*common.HashLength : (i+1)*common.HashLength])
+	}
+	return RandomPartySnapshot{
+		Phase:          RandomPartyPhase(words[0].Uint64()),
+		Commits:        words[1],
+		Reveals:        words[2],
+		CommitDeadline: words[3],
+		RevealDeadline: words[4],
+		Reward:         words[5],
+		Round:          words[6],
+	}, nil
+}
+
+// RandomPartyParticipationParams are the parameters that gate participation
+// returned by participationParams().
+type RandomPartyParticipationParams struct {
+	CommitFee            *big.Int
+	MinReveals           *big.Int
+	MaxCommitsPerAddress *big.Int
+}
+
+// participationParamsLen is the number of words returned by
+// participationParams().
+const participationParamsLen = 3
+
+// UnpackParticipationParams decodes the output of participationParams().
+func UnpackParticipationParams(ret []byte) (RandomPartyParticipationParams, error) {
+	if len(ret) != common.HashLength*participationParamsLen {
+		return RandomPartyParticipationParams{}, fmt.Errorf("invalid output length for participationParams: %d", len(ret))
+	}
+	return RandomPartyParticipationParams{
+		CommitFee:            new(big.Int).SetBytes(ret[:common.HashLength]),
+		MinReveals:           new(big.Int).SetBytes(ret[common.HashLength : 2*common.HashLength]),
+		MaxCommitsPerAddress: new(big.Int).SetBytes(ret[2*common.HashLength:]),
+	}, nil
+}
+
+func start(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, StartGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("start", 0, len(input))
+	}
+
+	stateDB := evm.GetStateDB()
+	shouldCompute := false
+	if _, revealDeadline, ok := getDeadlines(stateDB); ok && !isAbandoned(evm, stateDB, revealDeadline) {
+		if !getBool(stateDB, autoComputeOnStartKey) || evm.BlockTime().Cmp(revealDeadline) < 0 {
+			return nil, remainingGas, ErrRandomPartyUnderway
+		}
+		shouldCompute = true
+	}
+	if getBig(stateDB, phaseSecondsKey).Sign() == 0 {
+		return nil, remainingGas, ErrPhaseDurationUnset
+	}
+	// Finalize the result of the previous Random Party (if [AutoComputeOnStart]
+	// is set) before its entries are cleared below
+	if shouldCompute {
+		if _, remainingGas, err = computeRound(evm, callerAddr, addr, nil, remainingGas, value, readOnly); err != nil {
+			return nil, remainingGas, err
+		}
+	}
+	// Starting clears the entries of the previous Random Party, so only the
+	// results remain (leave room for the result of the new Random Party and
+	// its first commitment)
+	if err := checkStoredState(stateDB, new(big.Int).Add(getBig(stateDB, resultPrefix), common.Big2)); err != nil {
+		return nil, remainingGas, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	// Cleanup old commits and reveals (any commit that was not revealed is
+	// forfeited)
+	//
+	// The whole cleanup is charged before anything is deleted, so running out
+	// of gas never leaves the previous Random Party partially cleared.
+	keys := currentPartyKeys(stateDB)
+	commitStakeAmount := getBig(stateDB, commitStakeKey)
+	trackCommitCounts := getBig(stateDB, maxCommitsPerAddressKey).Sign() > 0
+	forfeited := new(big.Int)
+	forfeitCount := new(big.Int)
+	commits := getBig(stateDB, keys.commits)
+	for i := common.Big0; i.Cmp(commits) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if remainingGas, err = deductGas(remainingGas, DeleteGasCost); err != nil {
+			return nil, 0, err
+		}
+		if getCounterHash(stateDB, keys.commits, i).Big().Sign() != 0 {
+			forfeited.Add(forfeited, commitmentStake(stateDB, keys, i, commitStakeAmount))
+			forfeitCount.Add(forfeitCount, common.Big1)
+		}
+	}
+
+	// Any forfeited stakes that were paid out as [RevealBonus] are no longer
+	// available
+	forfeited.Sub(forfeited, math.BigMin(forfeited, getBig(stateDB, revealBonusPaidKey)))
+
+	// Route forfeited stakes to the configured destination
+	destination := ForfeitDestination(getBig(stateDB, forfeitDestinationKey).Uint64())
+	reveals := getBig(stateDB, keys.reveals)
+	eachForfeitAmount := common.Big0
+	shouldRewardForfeit := false
+	if forfeited.Sign() > 0 && destination == ForfeitToRevealers && reveals.Sign() > 0 && !getBool(stateDB, rewardsDisabledKey) {
+		eachForfeitAmount = new(big.Int).Div(forfeited, reveals)
+		shouldRewardForfeit = true
+	}
+	for i := common.Big0; i.Cmp(reveals) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if remainingGas, err = deductGas(remainingGas, DeleteGasCost); err != nil {
+			return nil, 0, err
+		}
+		if shouldRewardForfeit {
+			if remainingGas, err = deductGas(remainingGas, ComputeRewardCost); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	// The log is the last charge, so it is only emitted once the cleanup is
+	// fully paid for
+	sendToRecipient := forfeited.Sign() > 0 && destination == ForfeitToRecipient && hasForfeitRecipient(stateDB)
+	if sendToRecipient {
+		if remainingGas, err = addLog(evm, RandomPartyAddress, []common.Hash{StakesForfeitedTopic, common.BigToHash(getBig(stateDB, partyRoundKey)), getForfeitRecipient(stateDB).Hash()}, HBigBytes(forfeited), remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// Every address with a commitment count is either the owner of an
+	// unrevealed commitment or the recipient of a reveal, so counts are
+	// cleared along with those entries.
+	//
+	// An abandoned Random Party is replaced under the same round, so every
+	// entry must be deleted (not just the counters) to ensure the new Random
+	// Party never reads stale entries at the same indices.
+	//
+	// There are never more participants than commitments, so participants are
+	// cleared along with commitments at the same index.
+	participants := getBig(stateDB, keys.participants)
+	for i := common.Big0; i.Cmp(commits) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if i.Cmp(participants) < 0 {
+			setAddrBig(stateDB, keys.participantSeen, getIdxAddress(stateDB, keys.participants, i), common.Big0)
+			deleteCounterHash(stateDB, keys.participants, i)
+		}
+		if trackCommitCounts && getCounterHash(stateDB, keys.commits, i).Big().Sign() != 0 {
+			setAddrBig(stateDB, keys.commitCounts, getIdxAddress(stateDB, keys.owners, i), common.Big0)
+		}
+		deleteCounterHash(stateDB, keys.commits, i)
+		deleteCounterHash(stateDB, keys.commitStakes, i)
+		deleteIdxAddress(stateDB, keys.owners, i)
+	}
+	setBig(stateDB, keys.commits, common.Big0)
+	setBig(stateDB, keys.commitStakes, common.Big0)
+	setBig(stateDB, keys.participants, common.Big0)
+	setBig(stateDB, lastForfeitCountKey, forfeitCount)
+	setBig(stateDB, revealBonusPaidKey, common.Big0)
+
+	if forfeited.Sign() > 0 {
+		switch {
+		case destination == ForfeitToBurn:
+			if err := transfer(stateDB, constants.BlackholeAddr, forfeited); err != nil {
+				return nil, remainingGas, err
+			}
+		case shouldRewardForfeit:
+			// Paid to each revealer below, with the remainder that cannot be
+			// split equally kept in the incentive pool
+			remainder := new(big.Int).Mod(forfeited, reveals)
+			setBig(stateDB, rewardPrefix, new(big.Int).Add(getBig(stateDB, rewardPrefix), remainder))
+		case sendToRecipient:
+			if err := transfer(stateDB, getForfeitRecipient(stateDB), forfeited); err != nil {
+				return nil, remainingGas, err
+			}
+		default:
+			setBig(stateDB, rewardPrefix, new(big.Int).Add(getBig(stateDB, rewardPrefix), forfeited))
+		}
+	}
+	for i := common.Big0; i.Cmp(reveals) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if shouldRewardForfeit {
+			if err := transfer(stateDB, getIdxAddress(stateDB, keys.recipients, i), eachForfeitAmount); err != nil {
+				return nil, remainingGas, err
+			}
+		}
+		if trackCommitCounts {
+			setAddrBig(stateDB, keys.commitCounts, getIdxAddress(stateDB, keys.recipients, i), common.Big0)
+		}
+		deleteCounterHash(stateDB, keys.reveals, i)
+		deleteCounterHash(stateDB, keys.revealStakes, i)
+		deleteIdxAddress(stateDB, keys.recipients, i)
+	}
+	setBig(stateDB, keys.revealStakes, common.Big0)
+	setBig(stateDB, keys.reveals, common.Big0)
+
+	// The new Random Party stores its entries under the round its result
+	// will be stored at
+	round := getBig(stateDB, resultPrefix)
+	setBig(stateDB, partyRoundKey, round)
+	setBig(stateDB, partyPrefix(roundCommitFeePrefix, round), getBig(stateDB, commitStakeKey))
+	setBig(stateDB, startCountKey, new(big.Int).Add(getBig(stateDB, startCountKey), common.Big1))
+	setBig(stateDB, sponsorCountKey, common.Big0)
+
+	// Set phase deadlines
+	setBig(stateDB, startTimeKey, evm.BlockTime())
+	phaseDuration := getBig(stateDB, phaseSecondsKey)
+	epoch := getBig(stateDB, alignToEpochKey)
+	commitDeadline := alignToEpoch(new(big.Int).Add(evm.BlockTime(), phaseDuration), epoch)
+	setBig(stateDB, commitDeadlineKey, commitDeadline)
+	revealDeadline := alignToEpoch(new(big.Int).Add(commitDeadline, phaseDuration), epoch)
+	if getBool(stateDB, noRevealModeKey) {
+		revealDeadline = commitDeadline
+	}
+	setBig(stateDB, revealDeadlineKey, revealDeadline)
+
+	// Notify [ResultCallback] of the computed round only once the next Random
+	// Party has been started (so the callback never observes a partially
+	// started Random Party)
+	if shouldCompute {
+		if remainingGas, err = notifyResultCallback(evm, remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+	return []byte{}, remainingGas, nil
+}
+
+// alignToEpoch rounds [t] up to the next multiple of [epoch] (if [t] is not
+// already one). [t] is returned unchanged if [epoch] is 0.
+func alignToEpoch(t *big.Int, epoch *big.Int) *big.Int {
+	if epoch.Sign() == 0 {
+		return t
+	}
+	if rem := new(big.Int).Mod(t, epoch); rem.Sign() > 0 {
+		t.Add(t, epoch).Sub(t, rem)
+	}
+	return t
+}
+
+func sponsor(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	stateDB := evm.GetStateDB()
+	if remainingGas, err = deductGas(suppliedGas, getGasCost(stateDB, sponsorGasCostKey, SponsorGasCost)); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("sponsor", 0, len(input))
+	}
+	if getBool(stateDB, rewardsDisabledKey) {
+		return nil, remainingGas, ErrRewardsDisabled
+	}
+
+	commitDeadline, revealDeadline, ok := getDeadlines(stateDB)
+	if !ok {
+		return nil, remainingGas, ErrNoRandomPartyStarted
+	}
+	deadline := commitDeadline
+	if getBool(stateDB, sponsorUntilRevealKey) {
+		deadline = revealDeadline
+	}
+	if evm.BlockTime().Cmp(deadline) >= 0 {
+		return nil, remainingGas, ErrTooLate
+	}
+
+	rewardAmount := getBig(stateDB, rewardPrefix)
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	setBig(stateDB, rewardPrefix, new(big.Int).Add(rewardAmount, value))
+
+	// Record the total sponsored in the round (the incentive pool is reset
+	// when the round is computed)
+	sponsoredKey := partyPrefix(sponsoredPrefix, getBig(stateDB, partyRoundKey))
+	setBig(stateDB, sponsoredKey, new(big.Int).Add(getBig(stateDB, sponsoredKey), value))
+
+	// Count each sponsor once per Random Party. Sponsors are marked with the
+	// number of Random Parties started so far (rather than the round, which
+	// is reused when an abandoned Random Party is replaced), so the marks
+	// never need to be cleared.
+	starts := getBig(stateDB, startCountKey)
+	if getAddrBig(stateDB, sponsorSeenPrefix, callerAddr).Cmp(starts) != 0 {
+		setAddrBig(stateDB, sponsorSeenPrefix, callerAddr, starts)
+		setBig(stateDB, sponsorCountKey, new(big.Int).Add(getBig(stateDB, sponsorCountKey), common.Big1))
+	}
+	return []byte{}, remainingGas, nil
+}
+
+func abort(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, AbortGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	stateDB := evm.GetStateDB()
+	if _, _, ok := getDeadlines(stateDB); !ok {
+		return nil, remainingGas, ErrNoRandomPartyStarted
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("abort", 0, len(input))
+	}
+
+	if err := requireRole(stateDB, RandomPartyAddress, callerAddr, AllowListAdmin, ErrCannotAbort); err != nil {
+		return nil, remainingGas, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	// End the Random Party before refunding any stakes (the remaining entries
+	// of the round are cleared when the next Random Party is started, under
+	// the same round)
+	setBig(stateDB, commitDeadlineKey, common.Big0)
+	setBig(stateDB, revealDeadlineKey, common.Big0)
+
+	// Refund every commitment that was not revealed (revealed stakes were
+	// already returned) and delete it, so it is not forfeited by start (which
+	// only clears the commitment counts of owners of stored commitments)
+	keys := currentPartyKeys(stateDB)
+	round := getBig(stateDB, partyRoundKey)
+	commitStakeAmount := getBig(stateDB, commitStakeKey)
+	trackCommitCounts := getBig(stateDB, maxCommitsPerAddressKey).Sign() > 0
+	commits := getBig(stateDB, keys.commits)
+	for i := common.Big0; i.Cmp(commits) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if remainingGas, err = deductGas(remainingGas, DeleteGasCost); err != nil {
+			return nil, 0, err
+		}
+		if getCounterHash(stateDB, keys.commits, i).Big().Sign() == 0 {
+			continue
+		}
+		if remainingGas, err = deductGas(remainingGas, ComputeRewardCost); err != nil {
+			return nil, 0, err
+		}
+		owner := getIdxAddress(stateDB, keys.owners, i)
+		stake := commitmentStake(stateDB, keys, i, commitStakeAmount)
+		deleteCounterHash(stateDB, keys.commits, i)
+		deleteCounterHash(stateDB, keys.commitStakes, i)
+		setBig(stateDB, keys.commitStakes, new(big.Int).Sub(getBig(stateDB, keys.commitStakes), stake))
+		if trackCommitCounts {
+			setAddrBig(stateDB, keys.commitCounts, owner, common.Big0)
+		}
+		if err := transfer(stateDB, owner, stake); err != nil {
+			return nil, remainingGas, err
+		}
+		if remainingGas, err = addLog(evm, RandomPartyAddress, []common.Hash{StakeRefundedTopic, common.BigToHash(round), owner.Hash()}, HBigBytes(stake), remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+	return []byte{}, remainingGas, nil
+}
+
+func reward(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	stateDB := evm.GetStateDB()
+	if remainingGas, err = deductGas(suppliedGas, getGasCost(stateDB, rewardGasCostKey, RewardGasCost)); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("reward", 0, len(input))
+	}
+
+	_, _, ok := getDeadlines(stateDB)
+	if !ok {
+		return nil, remainingGas, ErrNoRandomPartyStarted
+	}
+	if err := checkRewardView(stateDB, callerAddr); err != nil {
+		return nil, remainingGas, err
+	}
+	return HBigBytes(getBig(stateDB, rewardPrefix)), remainingGas, nil
+}
+
+// checkStoredState returns [ErrStateLimitReached] if [entries] exceeds
+// [MaxStoredState].
+func checkStoredState(stateDB StateDB, entries *big.Int) error {
+	max := getBig(stateDB, maxStoredStateKey)
+	if max.Sign() > 0 && entries.Cmp(max) > 0 {
+		return fmt.Errorf("%w: %d entries exceeds %d", ErrStateLimitReached, entries, max)
+	}
+	return nil
+}
+
+// checkRewardView returns [ErrCannotReadReward] unless [caller] can read the
+// incentive pool (any caller can unless [RestrictRewardView] is set).
+func checkRewardView(stateDB StateDB, caller common.Address) error {
+	if !getBool(stateDB, restrictRewardViewKey) {
+		return nil
+	}
+	return requireRole(stateDB, RandomPartyAddress, caller, AllowListEnabled, ErrCannotReadReward)
+}
+
+func commit(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CommitGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	stateDB := evm.GetStateDB()
+	// In [NoRevealMode], the commitment is also revealed
+	if getBool(stateDB, noRevealModeKey) {
+		if remainingGas, err = deductGas(remainingGas, RevealGasCost); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := checkCommitPhase(evm, stateDB); err != nil {
+		return nil, remainingGas, err
+	}
+
+	h, err := UnpackCommit(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	idx, err := addCommitment(stateDB, callerAddr, callerAddr, h, value, readOnly)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return HBigBytes(idx), remainingGas, nil
+}
+
+// commitSigned commits to a hash on behalf of the participant that signed
+// [CommitDigest] (the caller, usually a relayer, locks the [CommitStake] and
+// the signer is recorded as the owner of the commitment).
+//
+// Note: the digest is bound to the current round, so a signature cannot be
+// replayed in a later round. The domain does not include a chain ID
+// because precompiles cannot access it.
+func commitSigned(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CommitSignedGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	stateDB := evm.GetStateDB()
+	// In [NoRevealMode], the commitment is also revealed
+	if getBool(stateDB, noRevealModeKey) {
+		if remainingGas, err = deductGas(remainingGas, RevealGasCost); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := checkCommitPhase(evm, stateDB); err != nil {
+		return nil, remainingGas, err
+	}
+
+	h, v, r, s, err := UnpackCommitSigned(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	signer, err := recoverCommitSigner(CommitDigest(h, getBig(stateDB, partyRoundKey)), v, r, s)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	idx, err := addCommitment(stateDB, callerAddr, signer, h, value, readOnly)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return HBigBytes(idx), remainingGas, nil
+}
+
+// checkCommitPhase returns an error if the current Random Party is not in its
+// "commit" phase.
+func checkCommitPhase(evm PrecompileAccessibleState, stateDB StateDB) error {
+	commitDeadline, _, ok := getDeadlines(stateDB)
+	if !ok {
+		return ErrNoRandomPartyStarted
+	}
+	if evm.BlockTime().Cmp(commitDeadline) >= 0 {
+		return ErrTooLate
+	}
+	return nil
+}
+
+// checkCommitLimits returns an error if [owner] cannot own another commitment
+// locking [stake] in the current Random Party because of
+// [MaxCommitsPerAddress], [MaxStoredState], [MaxTotalStake], or (in
+// [NoRevealMode], where every commitment is also a reveal) [MaxReveals]. If
+// [MaxCommitsPerAddress] is set, the number of commitments owned by [owner] is
+// returned (otherwise it is nil).
+func checkCommitLimits(stateDB StateDB, keys partyKeys, owner common.Address, stake *big.Int) (*big.Int, error) {
+	maxCommits := getBig(stateDB, maxCommitsPerAddressKey)
+	var ownerCommits *big.Int
+	if maxCommits.Sign() > 0 {
+		ownerCommits = getAddrBig(stateDB, keys.commitCounts, owner)
+		if ownerCommits.Cmp(maxCommits) >= 0 {
+			return nil, fmt.Errorf("%w: %s has %d commitments", ErrCommitLimitReached, owner, ownerCommits)
+		}
+	}
+	// Count the stored results, the pending result, and every commitment
+	// (including this one)
+	entries := new(big.Int).Add(getBig(stateDB, resultPrefix), getBig(stateDB, keys.commits))
+	if err := checkStoredState(stateDB, entries.Add(entries, common.Big2)); err != nil {
+		return nil, err
+	}
+	if maxStake := getBig(stateDB, maxTotalStakeKey); maxStake.Sign() > 0 {
+		total := new(big.Int).Add(getBig(stateDB, keys.commitStakes), stake)
+		if total.Cmp(maxStake) > 0 {
+			return nil, fmt.Errorf("%w: %d exceeds %d", ErrStakeCapReached, total, maxStake)
+		}
+	}
+	if getBool(stateDB, noRevealModeKey) {
+		if err := checkRevealCap(stateDB, keys); err != nil {
+			return nil, err
+		}
+	}
+	return ownerCommits, nil
+}
+
+// addCommitment locks the [CommitStake] paid by [payer] and records [h] as a
+// commitment owned by [owner], returning its index. If [StakeWeighted] is set,
+// all of [value] is locked (if it exceeds [CommitStake]).
+func addCommitment(stateDB StateDB, payer common.Address, owner common.Address, h common.Hash, value *big.Int, readOnly bool) (*big.Int, error) {
+	// Make sure value is sufficient (any shortfall can be drawn from the
+	// balance of the payer if [StakeFromBalance] is enabled)
+	commitStakeAmount := getBig(stateDB, commitStakeKey)
+	shortfall := new(big.Int).Set(commitStakeAmount)
+	if value != nil {
+		shortfall.Sub(shortfall, value)
+	}
+	if shortfall.Sign() > 0 && (!getBool(stateDB, stakeFromBalanceKey) || stateDB.GetBalance(payer).Cmp(shortfall) < 0) {
+		return nil, fmt.Errorf("%w: required %d", ErrInsufficientFunds, commitStakeAmount)
+	}
+	stake := commitStakeAmount
+	if getBool(stateDB, stakeWeightedKey) && value != nil && value.Cmp(stake) > 0 {
+		stake = value
+	}
+	keys := currentPartyKeys(stateDB)
+	ownerCommits, err := checkCommitLimits(stateDB, keys, owner, stake)
+	if err != nil {
+		return nil, err
+	}
+
+	if readOnly {
+		return nil, vmerrs.ErrWriteProtection
+	}
+
+	if shortfall.Sign() > 0 {
+		stateDB.SubBalance(payer, shortfall)
+		stateDB.AddBalance(RandomPartyAddress, shortfall)
+	}
+
+	if ownerCommits != nil {
+		setAddrBig(stateDB, keys.commitCounts, owner, new(big.Int).Add(ownerCommits, common.Big1))
+	}
+	idx := addCounterHash(stateDB, keys.commits, h)
+	setIdxAddress(stateDB, keys.owners, idx, owner)
+	if getAddrBig(stateDB, keys.participantSeen, owner).Sign() == 0 {
+		setAddrBig(stateDB, keys.participantSeen, owner, common.Big1)
+		addCounterHash(stateDB, keys.participants, owner.Hash())
+	}
+	if getBool(stateDB, stakeWeightedKey) {
+		stateDB.SetState(RandomPartyAddress, fastKey(keys.commitStakes, idx), common.BigToHash(stake))
+	}
+	setBig(stateDB, keys.commitStakes, new(big.Int).Add(getBig(stateDB, keys.commitStakes), stake))
+
+	// In [NoRevealMode], the commitment itself is the entropy, so it is
+	// revealed as soon as it is made
+	if getBool(stateDB, noRevealModeKey) {
+		if err := recordReveal(stateDB, keys, idx, h, owner); err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+func reveal(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, RevealGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	stateDB := evm.GetStateDB()
+	if err := checkRevealPhase(evm, stateDB); err != nil {
+		return nil, remainingGas, err
+	}
+
+	idx, preimage, err := UnpackReveal(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	if err := revealPreimage(stateDB, currentPartyKeys(stateDB), idx, preimage, readOnly); err != nil {
+		return nil, remainingGas, err
+	}
+	return []byte{}, refundRevealGas(stateDB, suppliedGas, remainingGas, 1), nil
+}
+
+// revealAndClaim reveals a preimage and withdraws the rewards credited to the
+// caller. Only rewards credited before the reveal are withdrawn (the share of
+// the current round is paid or credited by compute), so no reward is paid by
+// both revealAndClaim and compute.
+func revealAndClaim(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, RevealAndClaimGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	stateDB := evm.GetStateDB()
+	if err := checkRevealPhase(evm, stateDB); err != nil {
+		return nil, remainingGas, err
+	}
+
+	idx, preimage, err := UnpackRevealAndClaim(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	claimable := getAddrBig(stateDB, claimablePrefix, callerAddr)
+	if claimable.Sign() > 0 && isUsedAddress(callerAddr) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrInvalidRecipient, callerAddr)
+	}
+	if err := revealPreimage(stateDB, currentPartyKeys(stateDB), idx, preimage, readOnly); err != nil {
+		return nil, remainingGas, err
+	}
+
+	if claimable.Sign() > 0 {
+		setAddrBig(stateDB, claimablePrefix, callerAddr, common.Big0)
+		if err := transfer(stateDB, callerAddr, claimable); err != nil {
+			return nil, remainingGas, err
+		}
+	}
+	return HBigBytes(claimable), refundRevealGas(stateDB, suppliedGas, remainingGas, 1), nil
+}
+
+func revealBatch(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, RevealBatchGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	stateDB := evm.GetStateDB()
+	if err := checkRevealPhase(evm, stateDB); err != nil {
+		return nil, remainingGas, err
+	}
+
+	indices, preimages, err := UnpackRevealBatch(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Any invalid reveal reverts the entire batch
+	keys := currentPartyKeys(stateDB)
+	for i, idx := range indices {
+		if remainingGas, err = deductGas(remainingGas, RevealGasCost); err != nil {
+			return nil, 0, err
+		}
+		if err := revealPreimage(stateDB, keys, idx, preimages[i], readOnly); err != nil {
+			return nil, remainingGas, fmt.Errorf("reveal %d of batch failed: %w", i, err)
+		}
+	}
+	return []byte{}, refundRevealGas(stateDB, suppliedGas, remainingGas, uint64(len(indices))), nil
+}
+
+// refundRevealGas returns [remainingGas] plus the [RefundRevealGas] of
+// [reveals] successful reveals, capped so that no more than [suppliedGas] is
+// ever returned.
+func refundRevealGas(stateDB StateDB, suppliedGas uint64, remainingGas uint64, reveals uint64) uint64 {
+	refund := getBig(stateDB, refundRevealGasKey)
+	if refund.Sign() == 0 {
+		return remainingGas
+	}
+	refund = math.BigMin(refund, new(big.Int).SetUint64(RevealGasCost))
+	refund.Mul(refund, new(big.Int).SetUint64(reveals))
+	refund = math.BigMin(refund, new(big.Int).SetUint64(suppliedGas-remainingGas))
+	return remainingGas + refund.Uint64()
+}
+
+// checkRevealPhase returns an error if the current Random Party is not in its
+// "reveal" phase.
+func checkRevealPhase(evm PrecompileAccessibleState, stateDB StateDB) error {
+	commitDeadline, revealDeadline, ok := getDeadlines(stateDB)
+	if !ok {
+		return ErrNoRandomPartyStarted
+	}
+	if evm.BlockTime().Cmp(commitDeadline) < 0 {
+		return ErrTooEarly
+	}
+	if evm.BlockTime().Cmp(revealDeadline) >= 0 {
+		return ErrTooLate
+	}
+	return nil
+}
+
+// revealPreimage verifies that [preimage] is the preimage of the commitment at
+// [idx] and, if so, returns the [CommitStake] of the commitment to its owner.
+func revealPreimage(stateDB StateDB, keys partyKeys, idx *big.Int, preimage common.Hash, readOnly bool) error {
+	// Commitment counters are iterated as uint64, so an index outside of that
+	// range can never refer to a commitment
+	if !idx.IsUint64() {
+		return fmt.Errorf("%w: %d", ErrRevealIndexTooLarge, idx)
+	}
+	largestCommit := getBig(stateDB, keys.commits)
+	if idx.Cmp(largestCommit) >= 0 {
+		return fmt.Errorf("no hash with index %d", idx)
+	}
+	h := getCounterHash(stateDB, keys.commits, idx)
+	if h.Big().Sign() == 0 {
+		return ErrDuplicateReveal
+	}
+	ch, err := CommitHashAlgo(getBig(stateDB, commitHashAlgoKey).Uint64()).Hash(preimage.Bytes())
+	if err != nil {
+		return err
+	}
+	if h != ch {
+		return fmt.Errorf("expected %v but got %v (hash %v preimage %v)", h, ch, h, preimage)
+	}
+	if err := checkRevealCap(stateDB, keys); err != nil {
+		return err
+	}
+
+	feeRecipient := getIdxAddress(stateDB, keys.owners, idx)
+	if isUsedAddress(feeRecipient) {
+		return fmt.Errorf("%w: %s", ErrInvalidRecipient, feeRecipient)
+	}
+
+	if readOnly {
+		return vmerrs.ErrWriteProtection
+	}
+	return recordReveal(stateDB, keys, idx, preimage, feeRecipient)
+}
+
+// checkRevealCap returns [ErrRevealCapReached] if the current Random Party
+// has accepted [MaxReveals] reveals.
+func checkRevealCap(stateDB StateDB, keys partyKeys) error {
+	maxReveals := getBig(stateDB, maxRevealsKey)
+	if maxReveals.Sign() > 0 && getBig(stateDB, keys.reveals).Cmp(maxReveals) >= 0 {
+		return ErrRevealCapReached
+	}
+	return nil
+}
+
+// recordReveal returns the stake of the commitment at [idx] to
+// [feeRecipient] and records [preimage] as revealed by [feeRecipient].
+func recordReveal(stateDB StateDB, keys partyKeys, idx *big.Int, preimage common.Hash, feeRecipient common.Address) error {
+	stake := commitmentStake(stateDB, keys, idx, getBig(stateDB, commitStakeKey))
+	amount := stake
+	// Reveals are only ever appended to a round, so the first reveal of a
+	// round is the only one made when there are no reveals (and
+	// [FirstRevealBonus] can never be paid twice in a round)
+	if getBig(stateDB, keys.reveals).Sign() == 0 && !getBool(stateDB, rewardsDisabledKey) {
+		rewardAmount := getBig(stateDB, rewardPrefix)
+		bonus := math.BigMin(getBig(stateDB, firstRevealBonusKey), rewardAmount)
+		if bonus.Sign() > 0 {
+			setBig(stateDB, rewardPrefix, new(big.Int).Sub(rewardAmount, bonus))
+			amount = new(big.Int).Add(amount, bonus)
+		}
+	}
+	if err := transfer(stateDB, feeRecipient, amount); err != nil {
+		return err
+	}
+
+	// prevent duplicate reveals
+	setBig(stateDB, keys.commitStakes, new(big.Int).Sub(getBig(stateDB, keys.commitStakes), stake))
+	deleteCounterHash(stateDB, keys.commits, idx)
+	deleteCounterHash(stateDB, keys.commitStakes, idx)
+	deleteIdxAddress(stateDB, keys.owners, idx)
+	nidx := addCounterHash(stateDB, keys.reveals, preimage)
+	setIdxAddress(stateDB, keys.recipients, nidx, feeRecipient)
+	if getBool(stateDB, stakeWeightedKey) {
+		stateDB.SetState(RandomPartyAddress, fastKey(keys.revealStakes, nidx), common.BigToHash(stake))
+		setBig(stateDB, keys.revealStakes, new(big.Int).Add(getBig(stateDB, keys.revealStakes), stake))
+	}
+	return nil
+}
+
+// commitmentStake returns the stake locked by the commitment at [idx] (which
+// is only recorded if [StakeWeighted] is set, otherwise it is
+// [commitStakeAmount]).
+func commitmentStake(stateDB StateDB, keys partyKeys, idx *big.Int, commitStakeAmount *big.Int) *big.Int {
+	if stake := getCounterHash(stateDB, keys.commitStakes, idx).Big(); stake.Sign() > 0 {
+		return stake
+	}
+	return commitStakeAmount
+}
+
+func compute(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if ret, remainingGas, err = computeRound(evm, callerAddr, addr, input, suppliedGas, value, readOnly); err != nil {
+		return ret, remainingGas, err
+	}
+	if remainingGas, err = notifyResultCallback(evm, remainingGas); err != nil {
+		return nil, 0, err
+	}
+	return ret, remainingGas, nil
+}
+
+// notifyResultCallback calls onRandomPartyResult(uint256,bytes32) on
+// [ResultCallback] (if set) with the latest computed round and its result.
+// [ResultCallbackGasCost] is deducted from [suppliedGas] and any gas left
+// unused by the callback is returned. Failures of the callback are ignored.
+//
+// The callback may call back into the precompile, so it must be made after
+// all other reads and writes of the caller, so the callback never observes
+// (or overwrites) a partially updated state.
+func notifyResultCallback(evm PrecompileAccessibleState, suppliedGas uint64) (uint64, error) {
+	stateDB := evm.GetStateDB()
+	callback := getResultCallback(stateDB)
+	if callback == (common.Address{}) || isUsedAddress(callback) {
+		return suppliedGas, nil
+	}
+	remainingGas, err := deductGas(suppliedGas, ResultCallbackGasCost)
+	if err != nil {
+		return 0, err
+	}
+	round := new(big.Int).Sub(getBig(stateDB, resultPrefix), common.Big1)
+	input := make([]byte, 0, len(ResultCallbackSignature)+2*common.HashLength)
+	input = append(input, ResultCallbackSignature...)
+	input = append(input, HBigBytes(round)...)
+	input = append(input, GetResult(stateDB, round).Bytes()...)
+	_, leftOverGas, _ := evm.CallFromPrecompile(RandomPartyAddress, callback, input, ResultCallbackGasCost)
+	return remainingGas + leftOverGas, nil
+}
+
+// computeRound finalizes the current Random Party (see compute()) without
+// notifying [ResultCallback].
+func computeRound(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ComputeGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	stateDB := evm.GetStateDB()
+	_, revealDeadline, ok := getDeadlines(stateDB)
+	if !ok {
+		return nil, remainingGas, ErrNoRandomPartyStarted
+	}
+	if evm.BlockTime().Cmp(revealDeadline) < 0 {
+		return nil, remainingGas, ErrTooEarly
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("compute", 0, len(input))
+	}
+
+	keys := currentPartyKeys(stateDB)
+	reveals := getBig(stateDB, keys.reveals)
+	noRevealsBehavior := NoRevealsBehavior(getBig(stateDB, noRevealsBehaviorKey).Uint64())
+	if reveals.Sign() == 0 && noRevealsBehavior == NoRevealsReject {
+		return nil, remainingGas, ErrNoReveals
+	}
+	rewardAmount := getBig(stateDB, rewardPrefix)
+	// If [RewardsDisabled] is set, nothing is paid out of the incentive pool
+	// (and it is not reset below)
+	rewardsDisabled := getBool(stateDB, rewardsDisabledKey)
+	if rewardsDisabled {
+		rewardAmount = common.Big0
+	}
+	// Pay [ComputeBounty] (reduced by [BountyDecayRate] for each second since
+	// the reveal deadline) to the caller out of the incentive pool
+	bountyAmount := computeBountyAmount(stateDB, new(big.Int).Sub(evm.BlockTime(), revealDeadline))
+	bountyAmount = math.BigMin(bountyAmount, rewardAmount)
+	if bountyAmount.Sign() > 0 {
+		if remainingGas, err = deductGas(remainingGas, ComputeRewardCost); err != nil {
+			return nil, 0, err
+		}
+		rewardAmount = new(big.Int).Sub(rewardAmount, bountyAmount)
+	}
+	eachRewardAmount := common.Big0
+	shouldReward := false
+	if reveals.Sign() > 0 && rewardAmount.Sign() > 0 {
+		eachRewardAmount = new(big.Int).Div(rewardAmount, reveals)
+		shouldReward = true
+	}
+	// Pay [RevealBonus] to each revealer out of the stakes forfeited in this
+	// round (the bonus is reduced if there are not enough forfeited stakes to
+	// pay it to every revealer). The forfeited stakes are the stake of the
+	// unrevealed commitments tracked at [keys.commitStakes].
+	eachBonusAmount := common.Big0
+	if revealBonus := getBig(stateDB, revealBonusKey); !rewardsDisabled && revealBonus.Sign() > 0 && reveals.Sign() > 0 {
+		forfeited := new(big.Int).Set(getBig(stateDB, keys.commitStakes))
+		eachBonusAmount = math.BigMin(revealBonus, forfeited.Div(forfeited, reveals))
+	}
+	if eachBonusAmount.Sign() > 0 {
+		eachRewardAmount = new(big.Int).Add(eachRewardAmount, eachBonusAmount)
+		shouldReward = true
+	}
+	// If no one revealed, anyone can compute (otherwise the Random Party could
+	// never be finalized)
+	revealersOnly := reveals.Sign() > 0 && getBool(stateDB, computeByRevealersOnlyKey)
+	callerRevealed := false
+	ri := reveals.Uint64()
+	preimages := make([]byte, common.HashLength*ri)
+	// Only the first [MaxPayoutsPerCompute] rewards are paid by compute (the
+	// rest are credited to be claimed later)
+	maxPayouts := getBig(stateDB, maxPayoutsPerComputeKey).Uint64()
+	var payouts, claims []payout
+	if shouldReward {
+		payouts = make([]payout, 0, ri)
+	}
+	// If [StakeWeighted] is set, the incentive pool is split in proportion to
+	// the stake of each reveal (rather than equally)
+	stakeWeighted := shouldReward && getBool(stateDB, stakeWeightedKey)
+	revealedStake := getBig(stateDB, keys.revealStakes)
+	for i := uint64(0); i < ri; i++ {
+		if remainingGas, err = deductGas(remainingGas, ComputeItemCost); err != nil {
+			return nil, 0, err
+		}
+		bi := new(big.Int).SetUint64(i)
+		copy(preimages[i*common.HashLength:], getCounterHash(stateDB, keys.reveals, bi).Bytes())
+
+		if !shouldReward && !revealersOnly {
+			continue
+		}
+		rewardRecipient := getIdxAddress(stateDB, keys.recipients, bi)
+		if rewardRecipient == callerAddr {
+			callerRevealed = true
+		}
+		if !shouldReward {
+			continue
+		}
+
+		amount := eachRewardAmount
+		if stakeWeighted && revealedStake.Sign() > 0 {
+			amount = new(big.Int).Mul(rewardAmount, getCounterHash(stateDB, keys.revealStakes, bi).Big())
+			amount.Div(amount, revealedStake)
+			amount.Add(amount, eachBonusAmount)
+		}
+		if maxPayouts > 0 && uint64(len(payouts)) >= maxPayouts {
+			claims = append(claims, payout{rewardRecipient, amount})
+			continue
+		}
+		if remainingGas, err = deductGas(remainingGas, ComputeRewardCost); err != nil {
+			return nil, 0, err
+		}
+		payouts = append(payouts, payout{rewardRecipient, amount})
+	}
+	if revealersOnly && !callerRevealed {
+		return nil, remainingGas, ErrNotRevealer
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	// Finalize the round before paying any rewards (checks-effects-interactions),
+	// so the Random Party never appears to be underway during a payout
+	setBig(stateDB, commitDeadlineKey, common.Big0)
+	setBig(stateDB, revealDeadlineKey, common.Big0)
+	if !rewardsDisabled {
+		// Without reveals, the pool (less any bounty) is not paid to anyone,
+		// so it rolls over to the next round rather than being stranded in
+		// the balance of the precompile
+		remainingReward := common.Big0
+		if ri == 0 {
+			remainingReward = rewardAmount
+		}
+		setBig(stateDB, rewardPrefix, remainingReward)
+	}
+	setBig(stateDB, revealBonusPaidKey, new(big.Int).Mul(eachBonusAmount, reveals))
+	result := crypto.Keccak256Hash(preimages)
+	if ri == 0 {
+		result = blockHashResult(evm)
+	}
+	round := addCounterHash(stateDB, resultPrefix, result)
+	// The round is indexed so consumers can filter for the result of a given
+	// round (it is the round the result is stored under)
+	if remainingGas, err = addLog(evm, RandomPartyAddress, []common.Hash{ResultComputedTopic, common.BigToHash(round)}, result.Bytes(), remainingGas); err != nil {
+		return nil, 0, err
+	}
+	// Record the split so revealers can verify it (even if some of it is
+	// only credited to be claimed later)
+	if shouldReward {
+		setBig(stateDB, partyPrefix(rewardPerRevealerPrefix, round), eachRewardAmount)
+		if remainingGas, err = addLog(evm, RandomPartyAddress, []common.Hash{RewardPerRevealerTopic, common.BigToHash(round)}, HBigBytes(eachRewardAmount), remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	for _, claim := range claims {
+		claimable := getAddrBig(stateDB, claimablePrefix, claim.recipient)
+		setAddrBig(stateDB, claimablePrefix, claim.recipient, claimable.Add(claimable, claim.amount))
+	}
+	if err := transfer(stateDB, callerAddr, bountyAmount); err != nil {
+		return nil, remainingGas, err
+	}
+	for _, payout := range payouts {
+		if err := transfer(stateDB, payout.recipient, payout.amount); err != nil {
+			return nil, remainingGas, err
+		}
+	}
+	return []byte{}, remainingGas, nil
+}
+
+// computeBountyAmount returns [ComputeBounty] reduced by [BountyDecayRate]
+// for each of the [elapsed] seconds since the reveal deadline.
+func computeBountyAmount(stateDB StateDB, elapsed *big.Int) *big.Int {
+	bounty := getBig(stateDB, computeBountyKey)
+	if bounty.Sign() == 0 {
+		return bounty
+	}
+	decay := new(big.Int).Mul(getBig(stateDB, bountyDecayRateKey), elapsed)
+	if decay.Cmp(bounty) >= 0 {
+		return common.Big0
+	}
+	return bounty.Sub(bounty, decay)
+}
+
+// blockHashResult returns the result of a round without reveals when
+// [NoRevealsBehavior] is [NoRevealsBlockHash] (the hash of the parent block).
+func blockHashResult(evm PrecompileAccessibleState) common.Hash {
+	var parentHash common.Hash
+	if number := evm.BlockNumber(); number.Sign() > 0 {
+		parentHash = evm.BlockHash(number.Uint64() - 1)
+	}
+	return crypto.Keccak256Hash(parentHash.Bytes())
+}
+
+// isAbandoned returns true if the Random Party with [revealDeadline] can never
+// be computed because no preimages were revealed and [NoRevealsBehavior] is
+// [NoRevealsReject] (so it can be replaced with start()).
+func isAbandoned(evm PrecompileAccessibleState, stateDB StateDB, revealDeadline *big.Int) bool {
+	if evm.BlockTime().Cmp(revealDeadline) < 0 {
+		return false
+	}
+	if NoRevealsBehavior(getBig(stateDB, noRevealsBehaviorKey).Uint64()) != NoRevealsReject {
+		return false
+	}
+	return getBig(stateDB, currentPartyKeys(stateDB).reveals).Sign() == 0
+}
+
+// payout is a reward owed to [recipient] by compute.
+type payout struct {
+	recipient common.Address
+	amount    *big.Int
+}
+
+func claim(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ClaimGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("claim", 0, len(input))
+	}
+
+	stateDB := evm.GetStateDB()
+	claimable := getAddrBig(stateDB, claimablePrefix, callerAddr)
+	if claimable.Sign() == 0 {
+		return nil, remainingGas, ErrNothingToClaim
+	}
+	if isUsedAddress(callerAddr) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrInvalidRecipient, callerAddr)
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	setAddrBig(stateDB, claimablePrefix, callerAddr, common.Big0)
+	if err := transfer(stateDB, callerAddr, claimable); err != nil {
+		return nil, remainingGas, err
+	}
+	return HBigBytes(claimable), remainingGas, nil
+}
+
+func claimableHandler(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ClaimableGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	account, err := UnpackClaimable(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := evm.GetStateDB()
+	return HBigBytes(getAddrBig(stateDB, claimablePrefix, account)), remainingGas, nil
+}
+
+func result(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	stateDB := evm.GetStateDB()
+	if remainingGas, err = deductGas(suppliedGas, getGasCost(stateDB, resultGasCostKey, ResultCost)); err != nil {
+		return nil, 0, err
+	}
+
+	round, err := UnpackResult(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	// Reject rounds that have not been computed (including rounds that are
+	// impossibly far in the future) rather than returning empty state
+	if next := getBig(stateDB, resultPrefix); round.Cmp(next) >= 0 {
+		return nil, remainingGas, fmt.Errorf("%w: round %d is not below next round %d", ErrRoundNotComputed, round, next)
+	}
+	if getBool(stateDB, emitResultConsumedKey) && !readOnly {
+		if remainingGas, err = addLog(evm, RandomPartyAddress, []common.Hash{ResultConsumedTopic}, append(HBigBytes(round), callerAddr.Hash().Bytes()...), remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+	return GetResult(stateDB, round).Bytes(), remainingGas, nil
+}
+
+func next(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	stateDB := evm.GetStateDB()
+	if remainingGas, err = deductGas(suppliedGas, getGasCost(stateDB, nextGasCostKey, NextCost)); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("next", 0, len(input))
+	}
+
+	return HBigBytes(getBig(stateDB, resultPrefix)), remainingGas, nil
+}
+
+func startTime(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, StartTimeGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("startTime", 0, len(input))
+	}
+
+	stateDB := evm.GetStateDB()
+	return HBigBytes(getBig(stateDB, startTimeKey)), remainingGas, nil
+}
+
+func computableAt(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ComputableAtGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	timestamp, err := UnpackComputableAt(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := evm.GetStateDB()
+	_, revealDeadline, ok := getDeadlines(stateDB)
+	if !ok || timestamp.Cmp(revealDeadline) < 0 {
+		return HBigBytes(common.Big0), remainingGas, nil
+	}
+	return HBigBytes(common.Big1), remainingGas, nil
+}
+
+func commitFee(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CommitFeeGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("commitFee", 0, len(input))
+	}
+
+	stateDB := evm.GetStateDB()
+	return HBigBytes(getBig(stateDB, commitStakeKey)), remainingGas, nil
+}
+
+func participationParams(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ParticipationParamsGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("participationParams", 0, len(input))
+	}
+
+	// compute rejects rounds without reveals unless the result can be taken
+	// from the block hash instead
+	stateDB := evm.GetStateDB()
+	minReveals := common.Big1
+	if NoRevealsBehavior(getBig(stateDB, noRevealsBehaviorKey).Uint64()) == NoRevealsBlockHash {
+		minReveals = common.Big0
+	}
+	ret = make([]byte, 0, common.HashLength*participationParamsLen)
+	for _, word := range []*big.Int{
+		getBig(stateDB, commitStakeKey),
+		minReveals,
+		getBig(stateDB, maxCommitsPerAddressKey),
+	} {
+		ret = append(ret, HBigBytes(word)...)
+	}
+	return ret, remainingGas, nil
+}
+
+func latestResult(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, LatestResultGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("latestResult", 0, len(input))
+	}
+
+	stateDB := evm.GetStateDB()
+	rounds := getBig(stateDB, resultPrefix)
+	if rounds.Sign() == 0 {
+		return nil, remainingGas, ErrRoundNotComputed
+	}
+	return getCounterHash(stateDB, resultPrefix, rounds.Sub(rounds, common.Big1)).Bytes(), remainingGas, nil
+}
+
+func lockedStake(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, LockedStakeGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	committer, err := UnpackLockedStake(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Revealing a preimage deletes its commitment (and returns its stake), so
+	// any stored commitment owned by [committer] is still locked.
+	stateDB := evm.GetStateDB()
+	keys := currentPartyKeys(stateDB)
+	commitStakeAmount := getBig(stateDB, commitStakeKey)
+	locked := new(big.Int)
+	commits := getBig(stateDB, keys.commits)
+	for i := common.Big0; i.Cmp(commits) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if remainingGas, err = deductGas(remainingGas, LockedStakeItemCost); err != nil {
+			return nil, 0, err
+		}
+		if getCounterHash(stateDB, keys.commits, i).Big().Sign() == 0 {
+			continue
+		}
+		if getIdxAddress(stateDB, keys.owners, i) == committer {
+			locked.Add(locked, commitmentStake(stateDB, keys, i, commitStakeAmount))
+		}
+	}
+	return HBigBytes(locked), remainingGas, nil
+}
+
+func timeRemaining(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, TimeRemainingGasCost); err != nil {
 		return nil, 0, err
 	}
 
 	if len(input) != 0 {
-		return nil, remainingGas, fmt.Errorf("invalid input length for reward: %d", len(input))
+		return nil, remainingGas, invalidInputLength("timeRemaining", 0, len(input))
 	}
 
-	stateDB := evm.GetStateDB()
-	commitDeadline := getBig(stateDB, commitDeadlineKey)
-	if commitDeadline.Sign() == 0 {
-		return nil, remainingGas, ErrNoRandomPartyStarted
-	}
-	if evm.BlockTime().Cmp(commitDeadline) >= 0 {
-		return nil, remainingGas, ErrTooLate
+	// Return the seconds until the deadline of the current phase (or zero if
+	// there is no Random Party or it can already be computed)
+	deadline, ok := getNextDeadline(evm, evm.GetStateDB())
+	if !ok {
+		return HBigBytes(common.Big0), remainingGas, nil
 	}
+	return HBigBytes(new(big.Int).Sub(deadline, evm.BlockTime())), remainingGas, nil
+}
 
-	rewardAmount := getBig(stateDB, rewardPrefix)
+func getCommitDeadlineRemaining(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, DeadlineRemainingGasCost); err != nil {
+		return nil, 0, err
+	}
 
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("getCommitDeadlineRemaining", 0, len(input))
 	}
 
-	setBig(stateDB, rewardPrefix, new(big.Int).Add(rewardAmount, value))
-	return []byte{}, remainingGas, nil
+	commitDeadline, _, ok := getDeadlines(evm.GetStateDB())
+	if !ok {
+		return HBigBytes(common.Big0), remainingGas, nil
+	}
+	return HBigBytes(secondsUntil(evm, commitDeadline)), remainingGas, nil
 }
 
-func reward(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, RewardGasCost); err != nil {
+func canCommit(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CanCommitGasCost); err != nil {
 		return nil, 0, err
 	}
 
 	if len(input) != 0 {
-		return nil, remainingGas, fmt.Errorf("invalid input length for reward: %d", len(input))
+		return nil, remainingGas, invalidInputLength("canCommit", 0, len(input))
 	}
 
 	stateDB := evm.GetStateDB()
-	commitDeadline := getBig(stateDB, commitDeadlineKey)
-	if commitDeadline.Sign() == 0 {
-		return nil, remainingGas, ErrNoRandomPartyStarted
+	if err := checkCommitPhase(evm, stateDB); err != nil {
+		return HBigBytes(common.Big0), remainingGas, nil
 	}
-	return HBigBytes(getBig(stateDB, rewardPrefix)), remainingGas, nil
+	if _, err := checkCommitLimits(stateDB, currentPartyKeys(stateDB), callerAddr, getBig(stateDB, commitStakeKey)); err != nil {
+		return HBigBytes(common.Big0), remainingGas, nil
+	}
+	return HBigBytes(common.Big1), remainingGas, nil
 }
 
-func commit(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, CommitGasCost); err != nil {
+func lastForfeitCount(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, LastForfeitCountGasCost); err != nil {
 		return nil, 0, err
 	}
 
-	stateDB := evm.GetStateDB()
-	commitDeadline := getBig(stateDB, commitDeadlineKey)
-	if commitDeadline.Sign() == 0 {
-		return nil, remainingGas, ErrNoRandomPartyStarted
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("lastForfeitCount", 0, len(input))
 	}
-	if evm.BlockTime().Cmp(commitDeadline) >= 0 {
-		return nil, remainingGas, ErrTooLate
+
+	return HBigBytes(getBig(evm.GetStateDB(), lastForfeitCountKey)), remainingGas, nil
+}
+
+func rewardPerRevealer(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, RewardPerRevealerGasCost); err != nil {
+		return nil, 0, err
 	}
 
-	h, err := UnpackCommit(input)
+	round, err := UnpackRewardPerRevealer(input)
 	if err != nil {
 		return nil, remainingGas, err
 	}
 
-	// Make sure value is sufficient
-	commitStakeAmount := getBig(stateDB, commitStakeKey)
-	if value == nil || value.Cmp(commitStakeAmount) < 0 {
-		return nil, remainingGas, fmt.Errorf("%w: required %d", ErrInsufficientFunds, commitStakeAmount)
+	// Rounds are stored with a fixed width, so larger rounds were never computed
+	if !round.IsUint64() {
+		return HBigBytes(common.Big0), remainingGas, nil
 	}
+	return HBigBytes(getBig(evm.GetStateDB(), partyPrefix(rewardPerRevealerPrefix, round))), remainingGas, nil
+}
 
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
+func recentResults(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, RecentResultsGasCost); err != nil {
+		return nil, 0, err
 	}
 
-	idx := addCounterHash(stateDB, commitPrefix, h)
-	setIdxAddress(stateDB, commitOwnerPrefix, idx, callerAddr)
-	return HBigBytes(idx), remainingGas, nil
+	n, err := UnpackRecentResults(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the results of rounds [next-n, next) in reverse (gas is charged
+	// per result returned)
+	stateDB := evm.GetStateDB()
+	next := getBig(stateDB, resultPrefix)
+	end := new(big.Int).Sub(next, math.BigMin(n, next))
+	var results []common.Hash
+	for i := new(big.Int).Sub(next, common.Big1); i.Cmp(end) >= 0; i = new(big.Int).Sub(i, common.Big1) {
+		if remainingGas, err = deductGas(remainingGas, RecentResultsItemCost); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, getCounterHash(stateDB, resultPrefix, i))
+	}
+	return packHashArray(results), remainingGas, nil
 }
 
-func reveal(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, RevealGasCost); err != nil {
+func sponsorCount(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SponsorCountGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("sponsorCount", 0, len(input))
+	}
+
+	return HBigBytes(getBig(evm.GetStateDB(), sponsorCountKey)), remainingGas, nil
+}
+
+func isSolvent(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, IsSolventGasCost); err != nil {
 		return nil, 0, err
 	}
 
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("isSolvent", 0, len(input))
+	}
+
 	stateDB := evm.GetStateDB()
-	commitDeadline := getBig(stateDB, commitDeadlineKey)
-	revealDeadline := getBig(stateDB, revealDeadlineKey)
-	if commitDeadline.Sign() == 0 || revealDeadline.Sign() == 0 {
-		return nil, remainingGas, ErrNoRandomPartyStarted
+	owed := new(big.Int).Add(getBig(stateDB, rewardPrefix), getBig(stateDB, currentPartyKeys(stateDB).commitStakes))
+	if stateDB.GetBalance(RandomPartyAddress).Cmp(owed) < 0 {
+		return HBigBytes(common.Big0), remainingGas, nil
 	}
-	if evm.BlockTime().Cmp(commitDeadline) < 0 {
-		return nil, remainingGas, ErrTooEarly
+	return HBigBytes(common.Big1), remainingGas, nil
+}
+
+func getRevealDeadlineRemaining(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, DeadlineRemainingGasCost); err != nil {
+		return nil, 0, err
 	}
-	if evm.BlockTime().Cmp(revealDeadline) >= 0 {
-		return nil, remainingGas, ErrTooLate
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("getRevealDeadlineRemaining", 0, len(input))
 	}
 
-	idx, preimage, err := UnpackReveal(input)
+	_, revealDeadline, ok := getDeadlines(evm.GetStateDB())
+	if !ok {
+		return HBigBytes(common.Big0), remainingGas, nil
+	}
+	return HBigBytes(secondsUntil(evm, revealDeadline)), remainingGas, nil
+}
+
+// secondsUntil returns the number of seconds from the current block until
+// [deadline] (or zero if [deadline] has passed).
+func secondsUntil(evm PrecompileAccessibleState, deadline *big.Int) *big.Int {
+	if evm.BlockTime().Cmp(deadline) >= 0 {
+		return common.Big0
+	}
+	return new(big.Int).Sub(deadline, evm.BlockTime())
+}
+
+func nextDeadline(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, NextDeadlineGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("nextDeadline", 0, len(input))
+	}
+
+	deadline, ok := getNextDeadline(evm, evm.GetStateDB())
+	if !ok {
+		return HBigBytes(common.Big0), remainingGas, nil
+	}
+	return HBigBytes(deadline), remainingGas, nil
+}
+
+// getNextDeadline returns the deadline of the current phase ("commit" or
+// "reveal") of the Random Party at the current block time or false if there is
+// no Random Party underway or it can be computed.
+func getNextDeadline(evm PrecompileAccessibleState, stateDB StateDB) (*big.Int, bool) {
+	commitDeadline, revealDeadline, ok := getDeadlines(stateDB)
+	if !ok {
+		return nil, false
+	}
+	blockTime := evm.BlockTime()
+	switch {
+	case blockTime.Cmp(commitDeadline) < 0:
+		return commitDeadline, true
+	case blockTime.Cmp(revealDeadline) < 0:
+		return revealDeadline, true
+	default:
+		return nil, false
+	}
+}
+
+func sponsoredTotal(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SponsoredTotalGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	round, err := UnpackSponsoredTotal(input)
 	if err != nil {
 		return nil, remainingGas, err
 	}
-	largestCommit := getBig(stateDB, commitPrefix)
-	if idx.Cmp(largestCommit) >= 0 {
-		return nil, remainingGas, fmt.Errorf("no hash with index %d", idx)
+
+	// Rounds are stored with a fixed width, so larger rounds were never sponsored
+	if !round.IsUint64() {
+		return HBigBytes(common.Big0), remainingGas, nil
 	}
-	h := getCounterHash(stateDB, commitPrefix, idx)
-	if h.Big().Sign() == 0 {
-		return nil, remainingGas, ErrDuplicateReveal
+	return HBigBytes(getBig(evm.GetStateDB(), partyPrefix(sponsoredPrefix, round))), remainingGas, nil
+}
+
+func wasRevealed(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, WasRevealedGasCost); err != nil {
+		return nil, 0, err
 	}
-	ch := crypto.Keccak256Hash(preimage.Bytes())
-	if h != ch {
-		return nil, remainingGas, fmt.Errorf("expected %v but got %v (hash %v preimage %v)", h, ch, h, preimage)
+
+	preimage, err := UnpackWasRevealed(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Scan the reveals of the current round (reveals are only ever appended,
+	// so this costs at most one read per reveal)
+	stateDB := evm.GetStateDB()
+	keys := currentPartyKeys(stateDB)
+	reveals := getBig(stateDB, keys.reveals)
+	for i := common.Big0; i.Cmp(reveals) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if remainingGas, err = deductGas(remainingGas, WasRevealedItemCost); err != nil {
+			return nil, 0, err
+		}
+		if getCounterHash(stateDB, keys.reveals, i) == preimage {
+			return HBigBytes(common.Big1), remainingGas, nil
+		}
+	}
+	return HBigBytes(common.Big0), remainingGas, nil
+}
+
+// setCommitFee allows an admin of the Random Party allow list to update
+// [CommitStake] for subsequent commitments.
+//
+// Note: the stake of existing commitments is not recorded individually (it is
+// returned or forfeited using the current [CommitStake]), so the fee can only
+// be updated when there are no commitments in the current round (before the
+// first commit or after the next Random Party is started).
+func setCommitFee(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SetCommitFeeGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	// The fee is unpacked as a uint256, so it can never be negative
+	fee, err := UnpackSetCommitFee(input)
+	if err != nil {
+		return nil, remainingGas, err
 	}
 
-	feeRecipient := getIdxAddress(stateDB, commitOwnerPrefix, idx)
+	stateDB := evm.GetStateDB()
+	if err := requireRole(stateDB, RandomPartyAddress, callerAddr, AllowListAdmin, ErrCannotSetCommitFee); err != nil {
+		return nil, remainingGas, err
+	}
+	if getBig(stateDB, currentPartyKeys(stateDB).commits).Sign() > 0 {
+		return nil, remainingGas, ErrRandomPartyUnderway
+	}
+	if err := checkCommitFee(getBig(stateDB, minCommitFeeKey), fee); err != nil {
+		return nil, remainingGas, err
+	}
 
 	if readOnly {
 		return nil, remainingGas, vmerrs.ErrWriteProtection
 	}
 
-	transfer(stateDB, feeRecipient, getBig(stateDB, commitStakeKey))
-
-	// prevent duplicate reveals
-	deleteCounterHash(stateDB, commitPrefix, idx)
-	deleteIdxAddress(stateDB, commitOwnerPrefix, idx)
-	nidx := addCounterHash(stateDB, revealPrefix, preimage)
-	setIdxAddress(stateDB, rewardPrefix, nidx, feeRecipient)
+	SetCommitStake(stateDB, fee)
+	// The fee of a round that has not been started yet is recorded by start
+	if _, _, ok := getDeadlines(stateDB); ok {
+		setBig(stateDB, partyPrefix(roundCommitFeePrefix, getBig(stateDB, partyRoundKey)), fee)
+	}
 	return []byte{}, remainingGas, nil
 }
 
-func compute(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, ComputeGasCost); err != nil {
+func setPaused(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SetPausedGasCost); err != nil {
 		return nil, 0, err
 	}
 
+	flags, err := UnpackSetPaused(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
 	stateDB := evm.GetStateDB()
-	revealDeadline := getBig(stateDB, revealDeadlineKey)
-	if revealDeadline.Sign() == 0 {
-		return nil, remainingGas, ErrNoRandomPartyStarted
+	if err := requireRole(stateDB, RandomPartyAddress, callerAddr, AllowListAdmin, ErrCannotPause); err != nil {
+		return nil, remainingGas, err
 	}
-	if evm.BlockTime().Cmp(revealDeadline) < 0 {
-		return nil, remainingGas, ErrTooEarly
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	setBig(stateDB, pauseFlagsKey, new(big.Int).SetUint64(uint64(flags)))
+	return []byte{}, remainingGas, nil
+}
+
+func paused(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, PausedGasCost); err != nil {
+		return nil, 0, err
 	}
 
 	if len(input) != 0 {
-		return nil, remainingGas, fmt.Errorf("invalid input length for compute: %d", len(input))
+		return nil, remainingGas, invalidInputLength("paused", 0, len(input))
 	}
 
-	reveals := getBig(stateDB, revealPrefix)
-	rewardAmount := getBig(stateDB, rewardPrefix)
-	eachRewardAmount := common.Big0
-	shouldReward := false
-	if reveals.Sign() > 0 && rewardAmount.Sign() > 0 {
-		eachRewardAmount = new(big.Int).Div(rewardAmount, reveals)
-		shouldReward = true
+	return HBigBytes(getBig(evm.GetStateDB(), pauseFlagsKey)), remainingGas, nil
+}
+
+func version(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, VersionGasCost); err != nil {
+		return nil, 0, err
 	}
-	ri := reveals.Uint64()
-	preimages := make([]byte, common.HashLength*ri)
-	for i := uint64(0); i < ri; i++ {
-		if remainingGas, err = deductGas(remainingGas, ComputeItemCost); err != nil {
-			return nil, 0, err
-		}
-		bi := new(big.Int).SetUint64(i)
-		copy(preimages[i:i+common.HashLength], getCounterHash(stateDB, revealPrefix, bi).Bytes())
 
-		if !shouldReward {
-			continue
-		}
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("version", 0, len(input))
+	}
 
-		if remainingGas, err = deductGas(remainingGas, ComputeRewardCost); err != nil {
-			return nil, 0, err
-		}
-		rewardRecipient := getIdxAddress(stateDB, rewardPrefix, bi)
-		transfer(stateDB, rewardRecipient, eachRewardAmount)
+	return HBigBytes(big.NewInt(RandomPartyVersion)), remainingGas, nil
+}
+
+func commitFeeCollected(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CommitFeeCollectedGasCost); err != nil {
+		return nil, 0, err
 	}
 
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("commitFeeCollected", 0, len(input))
 	}
 
-	setBig(stateDB, commitDeadlineKey, common.Big0)
-	setBig(stateDB, revealDeadlineKey, common.Big0)
-	setBig(stateDB, rewardPrefix, common.Big0)
-	addCounterHash(stateDB, resultPrefix, crypto.Keccak256Hash(preimages))
-	return []byte{}, remainingGas, nil
+	stateDB := evm.GetStateDB()
+	return HBigBytes(getBig(stateDB, currentPartyKeys(stateDB).commitStakes)), remainingGas, nil
 }
 
-func result(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, ResultCost); err != nil {
+func snapshot(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SnapshotGasCost); err != nil {
 		return nil, 0, err
 	}
 
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("snapshot", 0, len(input))
+	}
+
+	// Deadlines are cleared when a Random Party is computed (but its counts
+	// are kept until the next Random Party is started)
 	stateDB := evm.GetStateDB()
-	round, err := UnpackResult(input)
+	keys := currentPartyKeys(stateDB)
+	phase := RandomPartyPhaseNone
+	commitDeadline, revealDeadline, ok := getDeadlines(stateDB)
+	switch {
+	case !ok:
+		commitDeadline, revealDeadline = common.Big0, common.Big0
+	case evm.BlockTime().Cmp(commitDeadline) < 0:
+		phase = RandomPartyPhaseCommit
+	case evm.BlockTime().Cmp(revealDeadline) < 0:
+		phase = RandomPartyPhaseReveal
+	default:
+		phase = RandomPartyPhaseComputable
+	}
+
+	pool := common.Big0
+	if checkRewardView(stateDB, callerAddr) == nil {
+		pool = getBig(stateDB, rewardPrefix)
+	}
+
+	ret = make([]byte, 0, common.HashLength*snapshotLen)
+	for _, word := range []*big.Int{
+		new(big.Int).SetUint64(uint64(phase)),
+		getBig(stateDB, keys.commits),
+		getBig(stateDB, keys.reveals),
+		commitDeadline,
+		revealDeadline,
+		pool,
+		getBig(stateDB, partyRoundKey),
+	} {
+		ret = append(ret, HBigBytes(word)...)
+	}
+	return ret, remainingGas, nil
+}
+
+func phaseDuration(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, PhaseDurationGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != 0 {
+		return nil, remainingGas, invalidInputLength("phaseDuration", 0, len(input))
+	}
+
+	return HBigBytes(getBig(evm.GetStateDB(), phaseSecondsKey)), remainingGas, nil
+}
+
+func isFinalized(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, IsFinalizedGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	round, err := UnpackIsFinalized(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Results are stored in order, so every round before next() is final
+	if round.Cmp(getBig(evm.GetStateDB(), resultPrefix)) < 0 {
+		return HBigBytes(common.Big1), remainingGas, nil
+	}
+	return HBigBytes(common.Big0), remainingGas, nil
+}
+
+func commitFeeOf(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CommitFeeOfGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	round, err := UnpackCommitFeeOf(input)
 	if err != nil {
 		return nil, remainingGas, err
 	}
-	return getCounterHash(stateDB, resultPrefix, round).Bytes(), remainingGas, nil
+
+	// Rounds are stored with a fixed width, so larger rounds were never started
+	if !round.IsUint64() {
+		return HBigBytes(common.Big0), remainingGas, nil
+	}
+	return HBigBytes(getBig(evm.GetStateDB(), partyPrefix(roundCommitFeePrefix, round))), remainingGas, nil
 }
 
-func next(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, NextCost); err != nil {
+func resultFraction(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ResultFractionGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	round, precision, err := UnpackResultFraction(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	if precision.Sign() == 0 {
+		return nil, remainingGas, ErrZeroPrecision
+	}
+	stateDB := evm.GetStateDB()
+	if next := getBig(stateDB, resultPrefix); round.Cmp(next) >= 0 {
+		return nil, remainingGas, fmt.Errorf("%w: round %d is not below next round %d", ErrRoundNotComputed, round, next)
+	}
+
+	result := getCounterHash(stateDB, resultPrefix, round).Big()
+	return HBigBytes(result.Mod(result, precision)), remainingGas, nil
+}
+
+func schemaVersion(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SchemaVersionGasCost); err != nil {
 		return nil, 0, err
 	}
 
 	if len(input) != 0 {
-		return nil, remainingGas, fmt.Errorf("invalid input length for next: %d", len(input))
+		return nil, remainingGas, invalidInputLength("schemaVersion", 0, len(input))
+	}
+
+	return HBigBytes(new(big.Int).SetUint64(GetRandomPartySchemaVersion(evm.GetStateDB()))), remainingGas, nil
+}
+
+func participants(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ParticipantsGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	offset, limit, err := UnpackParticipants(input)
+	if err != nil {
+		return nil, remainingGas, err
 	}
 
+	// Return the participants in [offset, offset+limit) (gas is charged per
+	// participant returned)
 	stateDB := evm.GetStateDB()
-	return HBigBytes(getBig(stateDB, resultPrefix)), remainingGas, nil
+	keys := currentPartyKeys(stateDB)
+	end := math.BigMin(new(big.Int).Add(offset, limit), getBig(stateDB, keys.participants))
+	var addrs []common.Address
+	for i := offset; i.Cmp(end) < 0; i = new(big.Int).Add(i, common.Big1) {
+		if remainingGas, err = deductGas(remainingGas, ParticipantsItemCost); err != nil {
+			return nil, 0, err
+		}
+		addrs = append(addrs, getIdxAddress(stateDB, keys.participants, i))
+	}
+	return packAddressArray(addrs), remainingGas, nil
+}
+
+// nonPayable wraps [execute] so that it fails with [ErrUnexpectedValue] if any
+// value is sent (rather than silently adding the value to the balance of the
+// precompile, where it could never be withdrawn).
+func nonPayable(execute RunStatefulPrecompileFunc) RunStatefulPrecompileFunc {
+	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if value != nil && value.Sign() != 0 {
+			return nil, suppliedGas, fmt.Errorf("%w: %d", ErrUnexpectedValue, value)
+		}
+		return execute(evm, callerAddr, addr, input, suppliedGas, value, readOnly)
+	}
+}
+
+// whenEnabled wraps [execute] so that it fails with [ErrPrecompileDisabled]
+// once the block time reaches [DisableBlockTimestamp].
+func whenEnabled(execute RunStatefulPrecompileFunc) RunStatefulPrecompileFunc {
+	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		disableTimestamp := getBig(evm.GetStateDB(), disableTimestampKey)
+		if disableTimestamp.Sign() > 0 && evm.BlockTime().Cmp(disableTimestamp) >= 0 {
+			return nil, suppliedGas, fmt.Errorf("%w: since %d", ErrPrecompileDisabled, disableTimestamp)
+		}
+		return execute(evm, callerAddr, addr, input, suppliedGas, value, readOnly)
+	}
+}
+
+// whenNotPaused wraps [execute] so that it fails with [ErrMethodPaused] while
+// [flag] is set by setPaused.
+func whenNotPaused(flag PauseFlags, execute RunStatefulPrecompileFunc) RunStatefulPrecompileFunc {
+	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, value *big.Int, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if PauseFlags(getBig(evm.GetStateDB(), pauseFlagsKey).Uint64())&flag != 0 {
+			return nil, suppliedGas, ErrMethodPaused
+		}
+		return execute(evm, callerAddr, addr, input, suppliedGas, value, readOnly)
+	}
 }
 
 // createRandomPartyPrecompile returns a StatefulPrecompiledContrac
 func createRandomPartyPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
-	startFunc := newStatefulPrecompileFunction(StartSignature, start)
-	sponsorFunc := newStatefulPrecompileFunction(SponsorSignature, sponsor)
-	rewardFunc := newStatefulPrecompileFunction(RewardSignature, reward)
-	commitFunc := newStatefulPrecompileFunction(CommitSignature, commit)
-	revealFunc := newStatefulPrecompileFunction(RevealSignature, reveal)
-	computeFunc := newStatefulPrecompileFunction(ComputeSignature, compute)
-	resultFunc := newStatefulPrecompileFunction(ResultSignature, result)
-	nextFunc := newStatefulPrecompileFunction(NextSignature, next)
+	startFunc := newStatefulPrecompileFunction(StartSignature, nonPayable(whenEnabled(whenNotPaused(PauseStart, start))))
+	sponsorFunc := newStatefulPrecompileFunction(SponsorSignature, whenEnabled(whenNotPaused(PauseSponsor, sponsor)))
+	rewardFunc := newStatefulPrecompileFunction(RewardSignature, nonPayable(reward))
+	commitFunc := newStatefulPrecompileFunction(CommitSignature, whenEnabled(whenNotPaused(PauseCommit, commit)))
+	revealFunc := newStatefulPrecompileFunction(RevealSignature, nonPayable(reveal))
+	computeFunc := newStatefulPrecompileFunction(ComputeSignature, nonPayable(compute))
+	resultFunc := newStatefulPrecompileFunction(ResultSignature, nonPayable(result))
+	nextFunc := newStatefulPrecompileFunction(NextSignature, nonPayable(next))
+	startTimeFunc := newStatefulPrecompileFunction(StartTimeSignature, nonPayable(startTime))
+	computableAtFunc := newStatefulPrecompileFunction(ComputableAtSignature, nonPayable(computableAt))
+	commitFeeFunc := newStatefulPrecompileFunction(CommitFeeSignature, nonPayable(commitFee))
+	latestResultFunc := newStatefulPrecompileFunction(LatestResultSignature, nonPayable(latestResult))
+	revealBatchFunc := newStatefulPrecompileFunction(RevealBatchSignature, nonPayable(revealBatch))
+	lockedStakeFunc := newStatefulPrecompileFunction(LockedStakeSignature, nonPayable(lockedStake))
+	commitSignedFunc := newStatefulPrecompileFunction(CommitSignedSignature, whenEnabled(whenNotPaused(PauseCommit, commitSigned)))
+	setCommitFeeFunc := newStatefulPrecompileFunction(SetCommitFeeSignature, nonPayable(whenEnabled(setCommitFee)))
+	timeRemainingFunc := newStatefulPrecompileFunction(TimeRemainingSignature, nonPayable(timeRemaining))
+	sponsoredTotalFunc := newStatefulPrecompileFunction(SponsoredTotalSignature, nonPayable(sponsoredTotal))
+	nextDeadlineFunc := newStatefulPrecompileFunction(NextDeadlineSignature, nonPayable(nextDeadline))
+	claimFunc := newStatefulPrecompileFunction(ClaimSignature, nonPayable(claim))
+	claimableFunc := newStatefulPrecompileFunction(ClaimableSignature, nonPayable(claimableHandler))
+	wasRevealedFunc := newStatefulPrecompileFunction(WasRevealedSignature, nonPayable(wasRevealed))
+	versionFunc := newStatefulPrecompileFunction(VersionSignature, nonPayable(version))
+	participantsFunc := newStatefulPrecompileFunction(ParticipantsSignature, nonPayable(participants))
+	commitFeeCollectedFunc := newStatefulPrecompileFunction(CommitFeeCollectedSignature, nonPayable(commitFeeCollected))
+	snapshotFunc := newStatefulPrecompileFunction(SnapshotSignature, nonPayable(snapshot))
+	phaseDurationFunc := newStatefulPrecompileFunction(PhaseDurationSignature, nonPayable(phaseDuration))
+	isFinalizedFunc := newStatefulPrecompileFunction(IsFinalizedSignature, nonPayable(isFinalized))
+	commitFeeOfFunc := newStatefulPrecompileFunction(CommitFeeOfSignature, nonPayable(commitFeeOf))
+	resultFractionFunc := newStatefulPrecompileFunction(ResultFractionSignature, nonPayable(resultFraction))
+	getCommitDeadlineRemainingFunc := newStatefulPrecompileFunction(GetCommitDeadlineRemainingSignature, nonPayable(getCommitDeadlineRemaining))
+	getRevealDeadlineRemainingFunc := newStatefulPrecompileFunction(GetRevealDeadlineRemainingSignature, nonPayable(getRevealDeadlineRemaining))
+	schemaVersionFunc := newStatefulPrecompileFunction(SchemaVersionSignature, nonPayable(schemaVersion))
+	revealAndClaimFunc := newStatefulPrecompileFunction(RevealAndClaimSignature, nonPayable(revealAndClaim))
+	canCommitFunc := newStatefulPrecompileFunction(CanCommitSignature, nonPayable(canCommit))
+	lastForfeitCountFunc := newStatefulPrecompileFunction(LastForfeitCountSignature, nonPayable(lastForfeitCount))
+	rewardPerRevealerFunc := newStatefulPrecompileFunction(RewardPerRevealerSignature, nonPayable(rewardPerRevealer))
+	recentResultsFunc := newStatefulPrecompileFunction(RecentResultsSignature, nonPayable(recentResults))
+	sponsorCountFunc := newStatefulPrecompileFunction(SponsorCountSignature, nonPayable(sponsorCount))
+	isSolventFunc := newStatefulPrecompileFunction(IsSolventSignature, nonPayable(isSolvent))
+	setPausedFunc := newStatefulPrecompileFunction(SetPausedSignature, nonPayable(setPaused))
+	pausedFunc := newStatefulPrecompileFunction(PausedSignature, nonPayable(paused))
+	participationParamsFunc := newStatefulPrecompileFunction(ParticipationParamsSignature, nonPayable(participationParams))
+	abortFunc := newStatefulPrecompileFunction(AbortSignature, nonPayable(abort))
+
+	setAdmin := newStatefulPrecompileFunction(setAdminSignature, createAllowListRoleSetter(precompileAddr, AllowListAdmin))
+	setEnabled := newStatefulPrecompileFunction(setEnabledSignature, createAllowListRoleSetter(precompileAddr, AllowListEnabled))
+	setNone := newStatefulPrecompileFunction(setNoneSignature, createAllowListRoleSetter(precompileAddr, AllowListNoRole))
+	read := newStatefulPrecompileFunction(readAllowListSignature, createReadAllowList(precompileAddr))
+	enabled := newStatefulPrecompileFunction(EnabledAddressesSignature, createEnabledAddresses(precompileAddr))
 
 	// Construct the contract with no fallback function.
 	contract := newStatefulPrecompileWithFunctionSelectors(nil, []*statefulPrecompileFunction{
 		startFunc, sponsorFunc, rewardFunc, commitFunc, revealFunc, computeFunc, resultFunc, nextFunc,
+		startTimeFunc, computableAtFunc, commitFeeFunc, latestResultFunc,
+		revealBatchFunc, lockedStakeFunc, commitSignedFunc, setCommitFeeFunc, timeRemainingFunc,
+		sponsoredTotalFunc, nextDeadlineFunc, claimFunc, claimableFunc, wasRevealedFunc,
+		versionFunc, participantsFunc, commitFeeCollectedFunc, snapshotFunc, phaseDurationFunc,
+		isFinalizedFunc, abortFunc, commitFeeOfFunc, resultFractionFunc,
+		getCommitDeadlineRemainingFunc, getRevealDeadlineRemainingFunc, schemaVersionFunc, revealAndClaimFunc,
+		canCommitFunc, lastForfeitCountFunc, rewardPerRevealerFunc, recentResultsFunc, sponsorCountFunc,
+		isSolventFunc, setPausedFunc, pausedFunc, participationParamsFunc,
+		setAdmin, setEnabled, setNone, read, enabled,
 	})
 	return contract
 }
@@ -12,40 +12,345 @@ pragma solidity >=0.8.0;
 // 1) start() => cleans up the metadata of a previous Random Party and inits
 //     a new Random Party (setting the length of the "commit" phase and "reveal"
 //     phase to [PhaseSeconds] and setting the "commit" lockup to
-//     [CommitStake])
+//     [CommitStake]). Reverts with [ErrPhaseDurationUnset] if [PhaseSeconds]
+//     is 0, which would close both phases as soon as they are opened.
 //
 //     Note: There is only ever 1 Random Party going on at once.
+//
+//     Note: If [AlignToEpoch] is set, each deadline is rounded up to the next
+//     multiple of [AlignToEpoch] seconds, so the phases of every Random Party
+//     end on epoch boundaries (each phase lasts at least [PhaseSeconds]).
+//
+//     Note: If [MaxStoredState] is set, start() reverts with
+//     [ErrStateLimitReached] if there is no room left for the result of the
+//     new Random Party and its first commitment.
+//
+//     Note: If [AutoComputeOnStart] is set, start() computes a previous Random
+//     Party that is past its reveal deadline but was never computed (and
+//     charges for it) before starting the new one, rather than reverting
+//     with [ErrRandomPartyUnderway]. The compute follows the same rules as
+//     compute() (e.g. the caller of start() receives any [ComputeBounty]).
+//     A Random Party without reveals that cannot be computed (see
+//     [NoRevealsReject]) is still replaced without a result.
 // 2) [optional] sponsor() => anyone can donate funds to an incentive pool that
 //     is distributed amongst all participants that reveal the preimage of their
-//     commitment
-// 3) commit(bytes32 encoded) => submit the hash of some preimage that will
+//     commitment (until the commit deadline or, if [SponsorUntilRevealDeadline]
+//     is set, until the reveal deadline). Reverts with [ErrRewardsDisabled] if
+//     [RewardsDisabled] is set.
+// 3) commit(bytes32 encoded) => submit the [CommitHashAlgo] hash of some preimage that will
 //     be broadcasted during the "reveal" phase ([CommitStake] tokens must be
 //     locked as part of this operation and are returned when the preimage is
 //     revealed)
+//
+//     Note: If [MaxCommitsPerAddress] is set, each address can own at most
+//     that many commitments per round.
+//
+//     Note: If [MaxTotalStake] is set, commit reverts with
+//     [ErrStakeCapReached] if the stake locked by the current Random Party
+//     (including the stake of this commitment) would exceed it.
+//
+//     Note: If [MaxStoredState] is set, commit reverts with
+//     [ErrStateLimitReached] once the stored results, the pending result, and
+//     the commitments to the current Random Party would exceed it.
+//
+//     Note: If [StakeWeighted] is set, committers can lock more than
+//     [CommitStake] (all attached value is locked) and the incentive pool is
+//     split in proportion to the stake of each reveal.
+//
+//     Note: commitSigned(bytes32 encoded, uint8 v, bytes32 r, bytes32 s) can be
+//     used by a relayer to commit on behalf of a participant that signed the
+//     EIP-712 digest of the commitment (the relayer locks the [CommitStake] and
+//     the participant receives it when the preimage is revealed).
+//
+//     Note: If [NoRevealMode] is set, there is no "reveal" phase (the reveal
+//     deadline is the commit deadline). Each commitment is treated as revealed
+//     as soon as it is made: its [CommitStake] is returned in the same call
+//     (which is charged [RevealGasCost] on top of the cost of the commit) and
+//     compute() hashes the commitments themselves (concatenated in commit
+//     order) once the commit deadline passes. This is much weaker than the
+//     commit/reveal flow: commitments are public as soon as they are made, so
+//     the last committer (or a block producer ordering commits) can choose a
+//     commitment that biases the result. Only use it where that bias is
+//     acceptable.
 // 4) reveal(uint256 index, bytes32 preimage) => reveal the preimage for some
 //     hash that was broadcast during the "commit" phase ([CommitStake] is returned
-//     at this time)
+//     at this time and at most [MaxReveals] preimages are accepted per round)
+//
+//     Note: Reveals are indexed in the order they are executed (so reveals in
+//     the same block are ordered by transaction and reveals in a batch are
+//     ordered as provided), regardless of the index of their commitment.
+//
+//     Note: If [FirstRevealBonus] is set, the first reveal of each round is
+//     also paid that bonus out of the incentive pool.
+//
+//     Note: If [RefundRevealGas] is set, that much gas (at most [RevealGasCost])
+//     is returned to the caller for each successful reveal by reveal(),
+//     revealBatch(), and revealAndClaim(). The refund never exceeds the gas
+//     charged by the call, so a reveal can be made free but never pays the
+//     caller gas.
+//
+//     Note: revealBatch(uint256[] indices, bytes32[] preimages) can be used to
+//     reveal multiple preimages at once (if any reveal is invalid, the entire
+//     batch is reverted). It charges [RevealBatchGasCost] plus [RevealGasCost]
+//     for each reveal.
+//
+//     Note: revealAndClaim(uint256 index, bytes32 preimage) (since version 13)
+//     reveals like reveal() and withdraws the rewards credited to the caller
+//     (see claim()) in the same call, returning the amount withdrawn. The
+//     caller's share of the current round is not known until compute() and is
+//     paid (or credited) by compute() as usual, so every reward is paid exactly
+//     once: rewards credited before the reveal are withdrawn by revealAndClaim
+//     and the share of the current round is settled by compute().
 //
 //     Note: If someone that posted a commitment does not reveal that
 //     commitment, they will not be able to retrieve their [CommitState].
 //     This mechanism is a naive deterrent for participants that may try to
-//     game the result of the computation.
+//     game the result of the computation. Forfeited stakes are routed to
+//     [ForfeitDestination] when the next Random Party is started (if it is
+//     [ForfeitToRecipient], they are sent to [ForfeitRecipient] and a
+//     StakesForfeited log is emitted).
 // 5) compute() => after the "commit" and "reveal" phases have passed, anyone
-//     can pay to compute the hash of all preimages (any balance in the
+//     can pay to compute the hash of all preimages (concatenated in reveal
+//     order) (any balance in the
 //     incentive pool is distributed equally to everyone that broadcast a preimage)
 //
+//     Note: If [ComputeBounty] is set, the caller of compute() is paid the
+//     bounty out of the incentive pool before it is distributed (the bounty is
+//     reduced by [BountyDecayRate] for each second since the reveal deadline).
+//
+//     Note: If [RevealBonus] is set, each revealer is also paid a bonus out of
+//     the stakes forfeited in the round.
+//
+//     Note: If [MaxPayoutsPerCompute] is set, compute only pays that many
+//     revealers and credits the rewards of the rest, which can be withdrawn
+//     with claim() (claimable(address account) returns the amount credited
+//     to [account]).
+//
+//     Note: If [ComputeByRevealersOnly] is set, only participants that revealed
+//     a preimage can compute a round (unless no one revealed).
+//
+//     Note: If any reward or bonus is paid, compute emits a RewardPerRevealer
+//     log with the amount owed to each revealer (the incentive pool, less any
+//     bounty, divided by the number of reveals, plus any [RevealBonus]). The
+//     amount is also returned by rewardPerRevealer(uint256 round). If
+//     [StakeWeighted] is set, it is the average amount owed to each revealer.
+//
+//     Note: If [RewardsDisabled] is set, compute only produces the result (no
+//     bounty, rewards, or bonuses are paid and the incentive pool is left as
+//     is), so it only charges for hashing the reveals. [FirstRevealBonus] is
+//     not paid either (revealers still get their [CommitStake] back) and
+//     forfeited stakes routed to revealers are added to the incentive pool
+//     instead.
+//
+//     Note: If no one revealed, compute fails with [ErrNoReveals] (and start()
+//     can be called to replace the round) unless [NoRevealsBehavior] is
+//     [NoRevealsBlockHash], in which case the result is the hash of the parent
+//     block (which can be influenced by block producers). The incentive pool
+//     (less any [ComputeBounty]) of a round without reveals is not paid to
+//     anyone, so it rolls over to the next round.
+//
+//     Note: If [ResultCallback] is set, compute calls
+//     onRandomPartyResult(uint256 round, bytes32 result) on it once the round
+//     is finalized and every reward has been paid (with at most
+//     [ResultCallbackGasCost] gas, which is charged up front and any unused
+//     gas is returned). Failures of the callback are ignored, so it can never
+//     prevent a round from being computed. The callback is made last, so it
+//     can safely call back into the precompile (e.g. to start() the next
+//     Random Party). If the round is computed by start() (see
+//     [AutoComputeOnStart]), the callback is made once the next Random Party
+//     has been started.
+//
 // Contracts use the following methods to access the state of an ongoing/completed Random Party:
-// 1) reward() => returns the amount in the current incentive pool
+// 1) reward() => returns the amount in the current incentive pool (if
+//     [RestrictRewardView] is set, only addresses enabled on the Random Party
+//     allow list can call it and others revert with [ErrCannotReadReward])
 // 2) result(uint256 round) => returns the computed hash of preimages of a given Random Party
-//     round
+//     round (reverts with [ErrRoundNotComputed] if the result of the round has not
+//     been computed yet, so a result of zero is never ambiguous)
+//
+//     Note: If [EmitResultConsumed] is set, result() emits a ResultConsumed log
+//     (and charges for it) unless it is called in a static context.
 // 3) next() => returns the number of the next Random Party round (this
 //     number-1 is used to query the latest result)
+// 4) startTime() => returns the time at which the latest Random Party was
+//     started
+// 5) computableAt(uint256 timestamp) => returns true if compute() can be called
+//     on the current Random Party at [timestamp]
+// 6) commitFee() => returns the [CommitStake] that must be locked to commit
+//     to the current Random Party
+// 7) latestResult() => returns the result of the latest computed Random Party
+//     round (reverts with [ErrRoundNotComputed] if no round has been computed)
+// 8) lockedStake(address committer) => returns the [CommitStake] locked by
+//     [committer] in commitments to the current Random Party that have not
+//     been revealed
+// 9) timeRemaining() => returns the number of seconds until the deadline of the
+//     current phase ("commit" or "reveal") or zero if there is no Random Party
+//     underway or it can be computed
+// 10) sponsoredTotal(uint256 round) => returns the total amount donated with
+//     sponsor() to the incentive pool of [round] (unlike reward(), this is not
+//     reset when the round is computed)
+// 11) nextDeadline() => returns the deadline of the current phase ("commit" or
+//     "reveal") or zero if there is no Random Party underway or it can be
+//     computed
+// 12) wasRevealed(bytes32 preimage) => returns true if [preimage] was revealed
+//     in the current (or latest computed) Random Party
+// 13) version() => returns [RandomPartyVersion], which is incremented whenever
+//     functions are added to the Random Party precompile (functions documented
+//     here are available since version 1 unless noted otherwise)
+// 14) participants(uint256 offset, uint256 limit) => returns up to [limit]
+//     distinct owners of commitments to the current Random Party (in the order
+//     they first committed), starting at [offset]
+//     Available since version 2.
+// 15) commitFeeCollected() => returns the total stake locked by commitments to
+//     the current Random Party that have not been revealed (stakes that are
+//     forfeited are no longer counted once the next Random Party is started)
+//     Available since version 4.
+// 16) snapshot() => returns the phase ([RandomPartyPhase]), the number of
+//     commitments, the number of reveals, the commit and reveal deadlines, the
+//     incentive pool, and the round of the current Random Party in one call
+//     (the counts and round of the latest Random Party are returned until the
+//     next one is started). The incentive pool is returned as 0 to callers
+//     that cannot call reward().
+//     Available since version 5.
+// 17) phaseDuration() => returns [PhaseSeconds], the length of the "commit"
+//     and "reveal" phases of each Random Party
+//     Available since version 6.
+// 18) isFinalized(uint256 round) => returns true if the result of [round] has
+//     been computed (i.e. [round] is less than next()), so it can never change
+//     Available since version 7.
+// 19) commitFeeOf(uint256 round) => returns the [CommitStake] that was required
+//     to commit in [round] (recorded when the round is started and updated by
+//     setCommitFee before its first commitment), or 0 if [round] was never
+//     started
+//     Available since version 9.
+// 20) resultFraction(uint256 round, uint256 precision) => returns the result of
+//     [round] modulo [precision], a fixed-point fraction in [0, 1) with
+//     denominator [precision] (reverts with [ErrRoundNotComputed] like result()
+//     and with [ErrZeroPrecision] if [precision] is 0). Reducing the 256-bit
+//     result biases the fraction by less than precision/2^256, which is
+//     negligible for any practical [precision], but the fraction should not be
+//     reduced again by a denominator that does not divide [precision] (e.g. use
+//     resultFraction(round, n) rather than resultFraction(round, 1000) % n)
+//     Available since version 10.
+// 21) getCommitDeadlineRemaining() and getRevealDeadlineRemaining() => return the
+//     number of seconds until the commit and reveal deadlines of the current
+//     Random Party respectively (both count down from start, so the reveal
+//     countdown can be shown during the "commit" phase), or zero if that
+//     deadline has passed or there is no Random Party underway
+//     Available since version 11.
+// 22) schemaVersion() => returns the version of the storage layout stored in
+//     the state of the Random Party ([RandomPartySchemaVersion] when it was
+//     configured), which differs from [RandomPartySchemaVersion] until the state
+//     is migrated
+//     Available since version 12.
+// 23) canCommit() => returns true if the caller could commit right now: a Random
+//     Party is in its "commit" phase, the caller owns fewer than
+//     [MaxCommitsPerAddress] commitments (if set), the commitment fits in
+//     [MaxStoredState] (if set), locking [CommitStake] would not exceed
+//     [MaxTotalStake] (if set), and (in [NoRevealMode]) [MaxReveals] has not
+//     been reached. Whether the caller can pay [CommitStake] is not checked.
+//     Available since version 14.
+// 24) lastForfeitCount() => returns the number of commitments to the previous
+//     Random Party that were never revealed (recorded when the next Random
+//     Party is started, so commitments refunded by abort() are not counted)
+//     Available since version 15.
+// 25) rewardPerRevealer(uint256 round) => returns the amount owed to each
+//     revealer of [round] when it was computed (see compute()), or 0 if no
+//     reward or bonus was paid in [round] or it has not been computed
+//     Available since version 16.
+// 26) recentResults(uint256 n) => returns the results of the latest [n] computed
+//     rounds as a bytes32[], newest first (fewer if fewer rounds have been
+//     computed, and gas is charged per result returned)
+//     Available since version 17.
+// 27) sponsorCount() => returns the number of distinct addresses that called
+//     sponsor() in the current (or latest computed) Random Party (reset to 0
+//     when the next Random Party is started)
+//     Available since version 18.
+// 28) isSolvent() => returns true if the balance of the precompile covers the
+//     incentive pool and the stake locked by unrevealed commitments (rewards
+//     credited to claimable() are not counted), so monitoring can detect
+//     accounting drift
+//     Available since version 19.
+// 29) paused() => returns the [PauseFlags] of the methods paused by admins
+//     Available since version 20.
+// 30) participationParams() => returns the [CommitStake] required to commit,
+//     the minimum number of reveals needed to compute a round (1, or 0 if
+//     [NoRevealsBehavior] is [NoRevealsBlockHash]), and
+//     [MaxCommitsPerAddress] (0 if there is no cap) in one call
+//     Available since version 21.
+//
+// Methods check their arguments in a consistent order: the base gas cost is
+// charged first (so ErrOutOfGas takes precedence over all errors other than
+// [ErrUnexpectedValue], [ErrPrecompileDisabled], and [ErrMethodPaused], which
+// are checked before a method runs), then the phase of the Random Party is
+// checked (e.g. [ErrTooLate]), then the input is decoded, and then the call
+// itself is validated (e.g. [ErrInsufficientFunds]).
+//
+// Only sponsor(), commit(), and commitSigned() accept value. All other methods
+// fail with [ErrUnexpectedValue] if any value is sent (so funds are never
+// absorbed by the precompile by accident).
+//
+// Deadlines are derived from the time of the block that executes start(), so
+// if a re-org re-executes start() in a block with a different time, both
+// deadlines shift with it (the phases always last [PhaseSeconds], or until
+// the following epoch boundary if [AlignToEpoch] is set). Every other call
+// only depends on the state and the time of its own block, so re-executed
+// commits and reveals that no longer fall within their phase revert (e.g.
+// with [ErrTooLate]) rather than corrupting the Random Party, which can
+// always be computed (or replaced) once its reveal deadline has passed.
+// Contracts should wait for the result of a round rather than act on the
+// deadlines of a Random Party that may still be re-orged.
+//
+// If [DisableBlockTimestamp] is set, start(), sponsor(), commit(),
+// commitSigned(), and setCommitFee() fail with [ErrPrecompileDisabled] from
+// that time onwards (reveals, compute(), claim(), and all views keep working
+// so a Random Party that is underway can be completed).
+//
+// Admins of the Random Party allow list (see [AllowListAdmins]) can use
+// setCommitFee(uint256 fee) to update [CommitStake] when there are no
+// commitments in the current round (the allow list is managed with the same
+// methods as other allow lists, and enabledAddresses(uint256 offset, uint256
+// limit) is available since version 3). Fees below [MinCommitFee] revert with
+// [ErrCommitFeeBelowMin].
+//
+// Admins can also use abort() (since version 8) to end the current Random Party
+// without computing it (e.g. if it failed). Every commitment that was not
+// revealed is refunded to its owner (emitting a StakeRefunded log) instead of
+// being forfeited, and the next Random Party reuses the round of the aborted
+// one.
+//
+// Admins can also use setPaused(uint256 flags) (since version 20) to pause
+// start() ([PauseStart]), sponsor() ([PauseSponsor]), and commit() and
+// commitSigned() ([PauseCommit]) independently (e.g. during an incident).
+// Paused methods fail with [ErrMethodPaused] until the flag is cleared by
+// another call to setPaused. Reveals, compute(), claim(), and all views are
+// never paused, so a Random Party that is underway can always be completed.
 //
 // In short, anyone can start a Random Party on the
 // chain, anyone can sponsor a reward for contributors, anyone can
 // participate in providing randomness, and anyone can use the round results
 // in their smart contract.
 interface RandomPartyInterface {
+    // Emitted when the [result] of [round] is computed (the round is indexed
+    // so the result of a given round can be filtered for)
+    event ResultComputed(uint256 indexed round, bytes32 result);
+
+    // Emitted when [caller] queries the result of [round] (only if
+    // [EmitResultConsumed] is set)
+    event ResultConsumed(uint256 round, address caller);
+
+    // Emitted when the unrevealed [amount] staked by [committer] in [round] is
+    // refunded by abort()
+    event StakeRefunded(uint256 indexed round, address indexed committer, uint256 amount);
+
+    // Emitted when the [amount] forfeited in [round] is sent to [recipient]
+    // (only if [ForfeitDestination] is [ForfeitToRecipient])
+    event StakesForfeited(uint256 indexed round, address indexed recipient, uint256 amount);
+
+    // Emitted when [round] is computed with the [amount] owed to each
+    // revealer (only if a reward or bonus is paid)
+    event RewardPerRevealer(uint256 indexed round, uint256 amount);
+
     // Start Random Party round
     function start() external;
 
@@ -58,17 +363,162 @@ interface RandomPartyInterface {
     // Commit to the hash of some preimage (requires locking [CommitStake])
     function commit(bytes32 encoded) payable external returns (uint256);
 
+    // Commit to the hash of some preimage on behalf of the signer of the
+    // EIP-712 digest of the commitment (requires locking [CommitStake])
+    function commitSigned(bytes32 encoded, uint8 v, bytes32 r, bytes32 s) payable external returns (uint256);
+
     // Reveal the preimage of a previously committed hash (receive locked
     // [CommitStake])
     function reveal(uint256 index, bytes32 preimage) external;
 
+    // Reveal the preimages of multiple previously committed hashes (reverts
+    // if any reveal is invalid)
+    function revealBatch(uint256[] calldata indices, bytes32[] calldata preimages) external;
+
     // Generate the hash of all revealed preimages and distribute any funds in
     // the incentive pool to all participants equally
     function compute() external;
 
-    // Query the hash of all preimages in [round]
+    // Query the hash of all preimages in [round] (reverts if [round] has not
+    // been computed)
     function result(uint256 round) external view returns (bytes32);
 
     // Query the index of the next Random Party Round
     function next() external view returns (uint256);
+
+    // Query the time at which the latest Random Party was started
+    function startTime() external view returns (uint256);
+
+    // Query whether the current Random Party can be computed at [timestamp]
+    function computableAt(uint256 timestamp) external view returns (bool);
+
+    // Query the [CommitStake] required to commit
+    function commitFee() external view returns (uint256);
+
+    // Query the hash of all preimages in the latest computed round (reverts
+    // if no round has been computed)
+    function latestResult() external view returns (bytes32);
+
+    // Query the [CommitStake] locked by [committer] in unrevealed commitments
+    // to the current Random Party
+    function lockedStake(address committer) external view returns (uint256);
+
+    // Query the number of seconds until the deadline of the current phase
+    function timeRemaining() external view returns (uint256);
+
+    // Query the total amount sponsored in [round]
+    function sponsoredTotal(uint256 round) external view returns (uint256);
+
+    // Query the deadline of the current phase
+    function nextDeadline() external view returns (uint256);
+
+    // Query whether [preimage] was revealed in the current Random Party
+    function wasRevealed(bytes32 preimage) external view returns (bool);
+
+    // Query the version of the Random Party precompile
+    function version() external view returns (uint256);
+
+    // Query up to [limit] distinct committers to the current Random Party,
+    // starting at [offset]
+    function participants(uint256 offset, uint256 limit) external view returns (address[] memory);
+
+    // Query the total stake locked by unrevealed commitments to the current
+    // Random Party
+    function commitFeeCollected() external view returns (uint256);
+
+    // Query the phase, commitment count, reveal count, deadlines, incentive
+    // pool, and round of the current Random Party
+    function snapshot() external view returns (uint256 phase, uint256 commits, uint256 reveals, uint256 commitDeadline, uint256 revealDeadline, uint256 reward, uint256 round);
+
+    // Query the length of the "commit" and "reveal" phases ([PhaseSeconds])
+    function phaseDuration() external view returns (uint256);
+
+    // Query whether the result of [round] has been computed
+    function isFinalized(uint256 round) external view returns (bool);
+
+    // Query the commit fee that was required to commit in [round]
+    function commitFeeOf(uint256 round) external view returns (uint256);
+
+    // Query the result of [round] as a fraction with denominator [precision]
+    // (i.e. the result modulo [precision])
+    function resultFraction(uint256 round, uint256 precision) external view returns (uint256);
+
+    // Query the number of seconds until the commit deadline of the current
+    // Random Party (zero once it has passed)
+    function getCommitDeadlineRemaining() external view returns (uint256);
+
+    // Query the number of seconds until the reveal deadline of the current
+    // Random Party (zero once it has passed)
+    function getRevealDeadlineRemaining() external view returns (uint256);
+
+    // Query the version of the storage layout of the Random Party
+    function schemaVersion() external view returns (uint256);
+
+    // Reveal [preimage] for the commitment at [index] and withdraw any rewards
+    // credited to the caller (returns the amount withdrawn)
+    function revealAndClaim(uint256 index, bytes32 preimage) external returns (uint256);
+
+    // Query whether the caller could commit to the current Random Party
+    function canCommit() external view returns (bool);
+
+    // Query the number of commitments to the previous Random Party that were
+    // never revealed
+    function lastForfeitCount() external view returns (uint256);
+
+    // Query the amount owed to each revealer of [round] when it was computed
+    function rewardPerRevealer(uint256 round) external view returns (uint256);
+
+    // Query the results of the latest [n] computed rounds (newest first)
+    function recentResults(uint256 n) external view returns (bytes32[] memory);
+
+    // Query the number of distinct sponsors of the current Random Party
+    function sponsorCount() external view returns (uint256);
+
+    // Query whether the balance of the precompile covers the incentive pool and locked stakes
+    function isSolvent() external view returns (bool);
+
+    // Query the [PauseFlags] of the methods paused by admins
+    function paused() external view returns (uint256);
+
+    // Query the commit fee, the minimum number of reveals needed to compute a
+    // round, and [MaxCommitsPerAddress] (0 if there is no cap)
+    function participationParams() external view returns (uint256 commitFee, uint256 minReveals, uint256 maxCommitsPerAddress);
+
+    // Withdraw any rewards credited to the caller by compute (returns the
+    // amount withdrawn)
+    function claim() external returns (uint256);
+
+    // Query the rewards credited to [account] that can be withdrawn with claim()
+    function claimable(address account) external view returns (uint256);
+
+    // Update the [CommitStake] required to commit (only callable by admins
+    // when there are no commitments in the current round and never below
+    // [MinCommitFee])
+    function setCommitFee(uint256 fee) external;
+
+    // End the current Random Party without computing it and refund all
+    // unrevealed stakes (only callable by admins)
+    function abort() external;
+
+    // Pause start(), sponsor(), and commit() independently with [flags], a
+    // combination of [PauseStart] (1), [PauseSponsor] (2), and [PauseCommit]
+    // (4) (only callable by admins)
+    function setPaused(uint256 flags) external;
+
+    // Set [addr] to have the admin role over the Random Party allow list
+    function setAdmin(address addr) external;
+
+    // Set [addr] to be enabled on the Random Party allow list
+    function setEnabled(address addr) external;
+
+    // Set [addr] to have no role over the Random Party allow list
+    function setNone(address addr) external;
+
+    // Read the status of [addr]
+    function readAllowList(address addr) external view returns (uint256);
+
+    // Query up to [limit] addresses with a role (enabled or admin), starting
+    // at [offset] (addresses granted a role before this function was added
+    // are only listed once they are granted a role again)
+    function enabledAddresses(uint256 offset, uint256 limit) external view returns (address[] memory);
 }
@@ -0,0 +1,115 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// benchParticipants are the round sizes each Random Party benchmark is run
+// against.
+var benchParticipants = []int{1, 10, 100, 1000}
+
+// benchRandomParty wraps a [countingStateDB] populated with a Random Party
+// round.
+type benchRandomParty struct {
+	b     *testing.B
+	state *countingStateDB
+}
+
+func benchParticipant(i int) (common.Address, common.Hash) {
+	return common.BigToAddress(big.NewInt(int64(i + 1))), common.BigToHash(big.NewInt(int64(i + 1)))
+}
+
+// newBenchRandomParty starts a Random Party at time 10 (committing until 13
+// and revealing until 16) with [commits] commitments, [reveals] of which are
+// revealed.
+func newBenchRandomParty(b *testing.B, commits int, reveals int) *benchRandomParty {
+	p := &benchRandomParty{b: b, state: newCountingStateDB()}
+	SetPhaseSeconds(p.state, big.NewInt(3))
+	SetCommitStake(p.state, big.NewInt(1000))
+	p.run(10, common.Address{}, StartSignature, common.Big0)
+	for i := 0; i < commits; i++ {
+		addr, preimage := benchParticipant(i)
+		p.run(11, addr, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000))
+	}
+	for i := 0; i < reveals; i++ {
+		addr, preimage := benchParticipant(i)
+		p.run(14, addr, PackReveal(big.NewInt(int64(i)), preimage), common.Big0)
+	}
+	return p
+}
+
+func (p *benchRandomParty) run(btime int64, caller common.Address, input []byte, value *big.Int) {
+	p.state.AddBalance(RandomPartyAddress, value)
+	accessibleState := &countingAccessibleState{state: p.state, blockTime: big.NewInt(btime)}
+	if _, _, err := RandomPartyPrecompile.Run(accessibleState, caller, RandomPartyAddress, input, 1<<62, value, false); err != nil {
+		p.b.Fatal(err)
+	}
+}
+
+// benchRandomPartyCall measures [call] against a fresh round created by
+// [setup] for each round size, reporting the storage accesses per call.
+func benchRandomPartyCall(b *testing.B, setup func(b *testing.B, n int) *benchRandomParty, call func(p *benchRandomParty, n int)) {
+	for _, n := range benchParticipants {
+		b.Run(fmt.Sprintf("participants=%d", n), func(b *testing.B) {
+			reads, writes := 0, 0
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				p := setup(b, n)
+				p.state.reads, p.state.writes = 0, 0
+				b.StartTimer()
+
+				call(p, n)
+				reads += p.state.reads
+				writes += p.state.writes
+			}
+			b.ReportMetric(float64(reads)/float64(b.N), "reads/op")
+			b.ReportMetric(float64(writes)/float64(b.N), "writes/op")
+		})
+	}
+}
+
+func BenchmarkRandomPartyCommit(b *testing.B) {
+	benchRandomPartyCall(b, func(b *testing.B, n int) *benchRandomParty {
+		return newBenchRandomParty(b, n-1, 0)
+	}, func(p *benchRandomParty, n int) {
+		addr, preimage := benchParticipant(n - 1)
+		p.run(11, addr, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000))
+	})
+}
+
+func BenchmarkRandomPartyReveal(b *testing.B) {
+	benchRandomPartyCall(b, func(b *testing.B, n int) *benchRandomParty {
+		return newBenchRandomParty(b, n, n-1)
+	}, func(p *benchRandomParty, n int) {
+		addr, preimage := benchParticipant(n - 1)
+		p.run(14, addr, PackReveal(big.NewInt(int64(n-1)), preimage), common.Big0)
+	})
+}
+
+func BenchmarkRandomPartyCompute(b *testing.B) {
+	benchRandomPartyCall(b, func(b *testing.B, n int) *benchRandomParty {
+		return newBenchRandomParty(b, n, n)
+	}, func(p *benchRandomParty, n int) {
+		p.run(16, common.Address{}, ComputeSignature, common.Big0)
+	})
+}
+
+// BenchmarkRandomPartyStartCleanup measures starting a new Random Party,
+// which clears the commitments and reveals of the computed prior round.
+func BenchmarkRandomPartyStartCleanup(b *testing.B) {
+	benchRandomPartyCall(b, func(b *testing.B, n int) *benchRandomParty {
+		p := newBenchRandomParty(b, n, n)
+		p.run(16, common.Address{}, ComputeSignature, common.Big0)
+		return p
+	}, func(p *benchRandomParty, n int) {
+		p.run(20, common.Address{}, StartSignature, common.Big0)
+	})
+}
@@ -0,0 +1,95 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build go1.18
+// +build go1.18
+
+package precompile
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"gotest.tools/assert"
+)
+
+var (
+	fuzzZeroWord = make([]byte, common.HashLength)
+	fuzzMaxWord  = math.MaxBig256.Bytes()
+)
+
+func FuzzCommitRoundTrip(f *testing.F) {
+	f.Add(fuzzZeroWord)
+	f.Add(fuzzMaxWord)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if len(b) > common.HashLength {
+			b = b[:common.HashLength]
+		}
+		hash := common.BytesToHash(b)
+		packed := PackCommit(hash)
+		assert.Assert(t, bytes.Equal(packed[:selectorLen], CommitSignature))
+
+		unpacked, err := UnpackCommit(packed[selectorLen:])
+		assert.NilError(t, err)
+		assert.Equal(t, unpacked, hash)
+	})
+}
+
+func FuzzRevealRoundTrip(f *testing.F) {
+	f.Add(fuzzZeroWord, fuzzZeroWord)
+	f.Add(fuzzMaxWord, fuzzMaxWord)
+	f.Fuzz(func(t *testing.T, idx []byte, b []byte) {
+		if len(idx) > common.HashLength {
+			idx = idx[:common.HashLength]
+		}
+		if len(b) > common.HashLength {
+			b = b[:common.HashLength]
+		}
+		v := new(big.Int).SetBytes(idx)
+		hash := common.BytesToHash(b)
+		packed := PackReveal(v, hash)
+		assert.Assert(t, bytes.Equal(packed[:selectorLen], RevealSignature))
+
+		unpackedV, unpackedHash, err := UnpackReveal(packed[selectorLen:])
+		assert.NilError(t, err)
+		assert.Assert(t, unpackedV.Cmp(v) == 0)
+		assert.Equal(t, unpackedHash, hash)
+	})
+}
+
+func FuzzResultRoundTrip(f *testing.F) {
+	f.Add(fuzzZeroWord)
+	f.Add(fuzzMaxWord)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if len(b) > common.HashLength {
+			b = b[:common.HashLength]
+		}
+		v := new(big.Int).SetBytes(b)
+		packed := PackResult(v)
+		assert.Assert(t, bytes.Equal(packed[:selectorLen], ResultSignature))
+
+		unpacked, err := UnpackResult(packed[selectorLen:])
+		assert.NilError(t, err)
+		assert.Assert(t, unpacked.Cmp(v) == 0)
+	})
+}
+
+func FuzzUnpackMalformed(f *testing.F) {
+	f.Add([]byte{})
+	f.Add(fuzzZeroWord)
+	f.Add(append(fuzzMaxWord, fuzzMaxWord...))
+	f.Add(append(fuzzMaxWord, 0x1))
+	f.Fuzz(func(t *testing.T, input []byte) {
+		_, err := UnpackCommit(input)
+		assert.Equal(t, err == nil, len(input) == common.HashLength)
+
+		_, _, err = UnpackReveal(input)
+		assert.Equal(t, err == nil, len(input) == 2*common.HashLength)
+
+		_, err = UnpackResult(input)
+		assert.Equal(t, err == nil, len(input) == common.HashLength)
+	})
+}
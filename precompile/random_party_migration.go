@@ -0,0 +1,90 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RandomPartySchemaVersion is the version of the storage layout used by the
+// Random Party precompile.
+//
+// Version 0 stored the per-index entries of the latest Random Party directly
+// under their prefix. Version 1 namespaces them by the round of the Random
+// Party (see [partyKeys]).
+const RandomPartySchemaVersion uint64 = 1
+
+var ErrInvalidMigration = errors.New("invalid random party migration")
+
+// migrations[v] migrates the storage layout of the Random Party precompile
+// from version v to version v+1.
+var migrations = []func(StateDB){
+	migrateV0ToV1,
+}
+
+// SetRandomPartySchemaVersion persists the storage layout version used by
+// the Random Party precompile to the [StateDB].
+func SetRandomPartySchemaVersion(state StateDB, version uint64) {
+	setBig(state, schemaVersionKey, new(big.Int).SetUint64(version))
+}
+
+// GetRandomPartySchemaVersion returns the storage layout version stored in
+// [state] (0 if the Random Party was configured before versions were stored),
+// so a node can detect whether [Migrate] must be called at an upgrade.
+func GetRandomPartySchemaVersion(state StateDB) uint64 {
+	return getBig(state, schemaVersionKey).Uint64()
+}
+
+// Migrate relocates the Random Party entries in [state] from the storage
+// layout of [fromVersion] to the storage layout of [toVersion].
+//
+// Migrate should be called at the network upgrade that activates a new
+// layout and fails if the layout stored in [state] is not [fromVersion].
+func Migrate(state StateDB, fromVersion, toVersion uint64) error {
+	if stored := GetRandomPartySchemaVersion(state); stored != fromVersion {
+		return fmt.Errorf("%w: stored schema version is %d but migrating from %d", ErrInvalidMigration, stored, fromVersion)
+	}
+	if toVersion < fromVersion || toVersion > RandomPartySchemaVersion {
+		return fmt.Errorf("%w: cannot migrate from %d to %d", ErrInvalidMigration, fromVersion, toVersion)
+	}
+	for version := fromVersion; version < toVersion; version++ {
+		migrations[version](state)
+		SetRandomPartySchemaVersion(state, version+1)
+	}
+	return nil
+}
+
+// migrateV0ToV1 moves the entries of the latest Random Party under the
+// namespace of its round.
+func migrateV0ToV1(state StateDB) {
+	// The latest Random Party will store its result at the next round while
+	// it is underway and has stored it at the previous round once computed.
+	round := getBig(state, resultPrefix)
+	if getBig(state, commitDeadlineKey).Sign() == 0 && round.Sign() > 0 {
+		round.Sub(round, common.Big1)
+	}
+	keys := newPartyKeys(round)
+	moveCounterEntries(state, commitPrefix, keys.commits, commitOwnerPrefix, keys.owners)
+	moveCounterEntries(state, revealPrefix, keys.reveals, rewardPrefix, keys.recipients)
+	setBig(state, partyRoundKey, round)
+}
+
+// moveCounterEntries moves the counter stored at [pfx] and each of the hashes
+// it counts (alongside the address stored at the same index under [addrPfx])
+// to [newPfx] and [newAddrPfx].
+func moveCounterEntries(state StateDB, pfx, newPfx, addrPfx, newAddrPfx []byte) {
+	count := getBig(state, pfx)
+	for i := common.Big0; i.Cmp(count) < 0; i = new(big.Int).Add(i, common.Big1) {
+		state.SetState(RandomPartyAddress, fastKey(newPfx, i), getCounterHash(state, pfx, i))
+		setIdxAddress(state, newAddrPfx, i, getIdxAddress(state, addrPfx, i))
+		deleteCounterHash(state, pfx, i)
+		deleteIdxAddress(state, addrPfx, i)
+	}
+	setBig(state, newPfx, count)
+	setBig(state, pfx, common.Big0)
+}
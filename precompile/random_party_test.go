@@ -0,0 +1,6228 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/constants"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/assert"
+)
+
+func TestParticipationCost(t *testing.T) {
+	type test struct {
+		stake         *big.Int
+		expectedStake *big.Int
+	}
+
+	for name, test := range map[string]test{
+		"stake": {
+			stake:         big.NewInt(1000),
+			expectedStake: big.NewInt(1000),
+		},
+		"no stake": {
+			stake:         nil,
+			expectedStake: big.NewInt(0),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := &RandomPartyConfig{CommitStake: test.stake}
+			stake, gas := ParticipationCost(config)
+			assert.Assert(t, stake.Cmp(test.expectedStake) == 0)
+			assert.Equal(t, gas, uint64(CommitGasCost+RevealGasCost))
+
+			// Modifying the returned stake must not modify the config
+			stake.SetUint64(1)
+			if test.stake != nil {
+				assert.Assert(t, test.stake.Cmp(test.expectedStake) == 0)
+			}
+		})
+	}
+}
+
+func TestRandomPartyVerifyCommitHashAlgo(t *testing.T) {
+	assert.NilError(t, (&RandomPartyConfig{CommitHashAlgo: CommitHashKeccak256}).Verify())
+	assert.NilError(t, (&RandomPartyConfig{CommitHashAlgo: CommitHashSHA256}).Verify())
+	err := (&RandomPartyConfig{CommitHashAlgo: CommitHashSHA256 + 1}).Verify()
+	assert.Assert(t, errors.Is(err, ErrUnknownCommitHash), err)
+}
+
+func TestRandomPartyVerifyNoRevealsBehavior(t *testing.T) {
+	assert.NilError(t, (&RandomPartyConfig{NoRevealsBehavior: NoRevealsReject}).Verify())
+	assert.NilError(t, (&RandomPartyConfig{NoRevealsBehavior: NoRevealsBlockHash}).Verify())
+	err := (&RandomPartyConfig{NoRevealsBehavior: NoRevealsBlockHash + 1}).Verify()
+	assert.Assert(t, errors.Is(err, ErrUnknownNoReveals), err)
+}
+
+func TestRandomPartyRecentResults(t *testing.T) {
+	state := newCountingStateDB()
+	run := func(input []byte, suppliedGas uint64) ([]byte, uint64, error) {
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		return RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, input, suppliedGas, common.Big0, true)
+	}
+	recentResults := func(n *big.Int) []common.Hash {
+		ret, _, err := run(PackRecentResults(n), 1_000_000)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, ret[:common.HashLength], common.BigToHash(big.NewInt(common.HashLength)).Bytes())
+		count := new(big.Int).SetBytes(ret[common.HashLength : 2*common.HashLength]).Int64()
+		assert.Equal(t, len(ret), common.HashLength*int(2+count))
+		results := make([]common.Hash, count)
+		for i := range results {
+			results[i] = common.BytesToHash(ret[common.HashLength*(2+i) : common.HashLength*(3+i)])
+		}
+		return results
+	}
+
+	// No rounds have been computed
+	assert.Equal(t, len(recentResults(big.NewInt(3))), 0)
+
+	results := make([]common.Hash, 5)
+	for i := range results {
+		results[i] = crypto.Keccak256Hash([]byte{byte(i)})
+		addCounterHash(state, resultPrefix, results[i])
+	}
+	assert.Equal(t, len(recentResults(common.Big0)), 0)
+	assert.DeepEqual(t, recentResults(big.NewInt(3)), []common.Hash{results[4], results[3], results[2]})
+
+	// Larger windows are clamped to the computed rounds
+	all := []common.Hash{results[4], results[3], results[2], results[1], results[0]}
+	assert.DeepEqual(t, recentResults(big.NewInt(5)), all)
+	assert.DeepEqual(t, recentResults(big.NewInt(10)), all)
+	assert.DeepEqual(t, recentResults(math.MaxBig256), all)
+
+	// Gas is charged per result returned
+	_, remainingGas, err := run(PackRecentResults(big.NewInt(3)), RecentResultsGasCost+3*RecentResultsItemCost)
+	assert.NilError(t, err)
+	assert.Equal(t, remainingGas, uint64(0))
+	_, _, err = run(PackRecentResults(big.NewInt(3)), RecentResultsGasCost+3*RecentResultsItemCost-1)
+	assert.Assert(t, errors.Is(err, vmerrs.ErrOutOfGas), err)
+
+	_, _, err = run(append(PackRecentResults(common.Big1), 0x1), 1_000_000)
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "invalid input length for recentResults"), err)
+}
+
+func TestRandomPartySelectors(t *testing.T) {
+	expected := map[string]struct{}{}
+	for _, signature := range []string{
+		"start()",
+		"sponsor()",
+		"reward()",
+		"commit(bytes32)",
+		"reveal(uint256,bytes32)",
+		"compute()",
+		"result(uint256)",
+		"next()",
+		"startTime()",
+		"computableAt(uint256)",
+		"commitFee()",
+		"latestResult()",
+		"revealBatch(uint256[],bytes32[])",
+		"lockedStake(address)",
+		"commitSigned(bytes32,uint8,bytes32,bytes32)",
+		"setCommitFee(uint256)",
+		"timeRemaining()",
+		"sponsoredTotal(uint256)",
+		"nextDeadline()",
+		"claim()",
+		"claimable(address)",
+		"wasRevealed(bytes32)",
+		"version()",
+		"participants(uint256,uint256)",
+		"commitFeeCollected()",
+		"snapshot()",
+		"phaseDuration()",
+		"isFinalized(uint256)",
+		"abort()",
+		"commitFeeOf(uint256)",
+		"resultFraction(uint256,uint256)",
+		"getCommitDeadlineRemaining()",
+		"getRevealDeadlineRemaining()",
+		"schemaVersion()",
+		"revealAndClaim(uint256,bytes32)",
+		"canCommit()",
+		"lastForfeitCount()",
+		"rewardPerRevealer(uint256)",
+		"recentResults(uint256)",
+		"sponsorCount()",
+		"isSolvent()",
+		"setPaused(uint256)",
+		"paused()",
+		"participationParams()",
+		"setAdmin(address)",
+		"setEnabled(address)",
+		"setNone(address)",
+		"readAllowList(address)",
+		"enabledAddresses(uint256,uint256)",
+	} {
+		expected[string(CalculateFunctionSelector(signature))] = struct{}{}
+	}
+
+	selectors := RandomPartyPrecompile.Selectors()
+	assert.Equal(t, len(selectors), len(expected))
+	for i, selector := range selectors {
+		_, ok := expected[string(selector)]
+		assert.Assert(t, ok, "unexpected selector %#x", selector)
+		if i > 0 {
+			assert.Assert(t, bytes.Compare(selectors[i-1], selector) < 0)
+		}
+
+		// Every reported selector must be dispatched to a registered function
+		_, _, err := RandomPartyPrecompile.Run(&countingAccessibleState{state: newCountingStateDB(), blockTime: common.Big0}, common.Address{}, RandomPartyAddress, selector, 0, common.Big0, true)
+		assert.Assert(t, err == nil || !strings.Contains(err.Error(), "invalid function selector"), err)
+	}
+}
+
+func TestRandomPartyPrecompileRecipient(t *testing.T) {
+	state := newCountingStateDB()
+	SetPhaseSeconds(state, big.NewInt(3))
+	SetCommitStake(state, big.NewInt(1000))
+	caller := common.Address{0x1}
+	preimage := common.Hash{0x1}
+
+	run := func(btime int64, input []byte, value *big.Int) error {
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+		_, _, err := RandomPartyPrecompile.Run(accessibleState, caller, RandomPartyAddress, input, 1_000_000, value, false)
+		return err
+	}
+
+	assert.NilError(t, run(10, StartSignature, common.Big0))
+	state.AddBalance(RandomPartyAddress, big.NewInt(1000))
+	assert.NilError(t, run(11, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000)))
+
+	// Overwrite the owner of the commitment with a stateful precompile
+	setIdxAddress(state, currentPartyKeys(state).owners, common.Big0, ContractNativeMinterAddress)
+
+	err := run(14, PackReveal(common.Big0, preimage), common.Big0)
+	assert.Assert(t, errors.Is(err, ErrInvalidRecipient), err)
+	assert.Equal(t, state.GetBalance(ContractNativeMinterAddress).Sign(), 0)
+
+	// Transfers to stateful precompiles fail
+	for _, addr := range UsedAddresses {
+		balance := state.GetBalance(addr)
+		err := transfer(state, addr, big.NewInt(1))
+		assert.Assert(t, errors.Is(err, ErrInvalidRecipient), err)
+		assert.Assert(t, state.GetBalance(addr).Cmp(balance) == 0)
+	}
+}
+
+func TestPackNoArgumentMethods(t *testing.T) {
+	for signature, pack := range map[string]func() []byte{
+		"sponsor()": PackSponsor,
+		"reward()":  PackReward,
+		"next()":    PackNext,
+		"compute()": PackCompute,
+	} {
+		input := pack()
+		assert.DeepEqual(t, input, CalculateFunctionSelector(signature))
+		assert.Equal(t, len(input), selectorLen, signature)
+
+		// Packed inputs never share the selector
+		input[0]++
+		assert.DeepEqual(t, pack(), CalculateFunctionSelector(signature))
+	}
+
+	// The packed inputs are accepted by the precompile and reward() can be
+	// unpacked
+	state := newCountingStateDB()
+	SetPhaseSeconds(state, big.NewInt(3))
+	SetCommitStake(state, big.NewInt(1000))
+	run := func(input []byte, value *big.Int) []byte {
+		state.AddBalance(RandomPartyAddress, value)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(10)}
+		ret, _, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, input, 1_000_000, value, false)
+		assert.NilError(t, err)
+		return ret
+	}
+	run(StartSignature, common.Big0)
+	run(PackSponsor(), big.NewInt(100))
+	reward, err := UnpackReward(run(PackReward(), common.Big0))
+	assert.NilError(t, err)
+	assert.Equal(t, reward.Int64(), int64(100))
+	assert.DeepEqual(t, run(PackNext(), common.Big0), HBigBytes(common.Big0))
+
+	_, err = UnpackReward(make([]byte, common.HashLength+1))
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "invalid output length for reward"), err)
+}
+
+func TestRandomPartyOneSidedDeadline(t *testing.T) {
+	for name, key := range map[string][]byte{
+		"only commit deadline": commitDeadlineKey,
+		"only reveal deadline": revealDeadlineKey,
+	} {
+		key := key
+		t.Run(name, func(t *testing.T) {
+			state := newCountingStateDB()
+			SetPhaseSeconds(state, big.NewInt(3))
+			setBig(state, key, big.NewInt(13))
+
+			run := func(btime int64, input []byte) error {
+				accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+				_, _, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, input, 1_000_000, common.Big0, false)
+				return err
+			}
+
+			for _, input := range [][]byte{
+				SponsorSignature,
+				RewardSignature,
+				PackCommit(common.Hash{0x1}),
+				PackReveal(common.Big0, common.Hash{0x1}),
+				ComputeSignature,
+			} {
+				assert.Equal(t, run(14, input), ErrNoRandomPartyStarted)
+			}
+
+			// A corrupted party does not prevent a new party from starting
+			assert.NilError(t, run(14, StartSignature))
+			assert.Assert(t, getBig(state, commitDeadlineKey).Cmp(big.NewInt(17)) == 0)
+			assert.Assert(t, getBig(state, revealDeadlineKey).Cmp(big.NewInt(20)) == 0)
+		})
+	}
+}
+
+func TestRandomPartyAllowListRoleKeys(t *testing.T) {
+	admin := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	// The hashes of these addresses are the keys of the commit deadline and
+	// the commit stake
+	low1 := common.BytesToAddress(commitDeadlineKey)
+	low7 := common.BytesToAddress(commitStakeKey)
+	state := newCountingStateDB()
+	(&RandomPartyConfig{
+		PhaseSeconds:    big.NewInt(3),
+		CommitStake:     big.NewInt(1000),
+		AllowListAdmins: []common.Address{admin},
+	}).Configure(state)
+	setBig(state, commitDeadlineKey, big.NewInt(13))
+
+	run := func(input []byte, suppliedGas uint64) []byte {
+		t.Helper()
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		ret, _, err := RandomPartyPrecompile.Run(accessibleState, admin, RandomPartyAddress, input, suppliedGas, common.Big0, false)
+		assert.NilError(t, err)
+		return ret
+	}
+	setRole := func(addr common.Address, role AllowListRole) {
+		t.Helper()
+		input, err := PackModifyAllowList(addr, role)
+		assert.NilError(t, err)
+		run(input, ModifyAllowListGasCost)
+	}
+
+	// Reading the role of an address does not read Random Party state
+	assert.DeepEqual(t, run(PackReadAllowList(low7), ReadAllowListGasCost), common.Hash(AllowListNoRole).Bytes())
+
+	// Granting and revoking a role does not overwrite Random Party state
+	setRole(low1, AllowListEnabled)
+	assert.Equal(t, GetRandomPartyStatus(state, low1), AllowListEnabled)
+	setRole(low1, AllowListNoRole)
+	assert.Equal(t, GetRandomPartyStatus(state, low1), AllowListNoRole)
+	assert.Equal(t, getBig(state, commitDeadlineKey).Int64(), int64(13))
+	assert.Equal(t, getBig(state, commitStakeKey).Int64(), int64(1000))
+}
+
+func TestRandomPartySponsoredTotalLargeRound(t *testing.T) {
+	admin := common.Address{0x1}
+	state := newCountingStateDB()
+	(&RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000), AllowListAdmins: []common.Address{admin}}).Configure(state)
+
+	// A round as wide as a storage key must not read any other slot (e.g. the
+	// role of [admin])
+	round := allowListRoleKey(RandomPartyAddress, admin).Big()
+	accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+	ret, _, err := RandomPartyPrecompile.Run(accessibleState, admin, RandomPartyAddress, PackSponsoredTotal(round), SponsoredTotalGasCost, common.Big0, false)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ret, HBigBytes(common.Big0))
+}
+
+func TestPackRevealBatch(t *testing.T) {
+	indices := []*big.Int{big.NewInt(0), big.NewInt(7)}
+	preimages := []common.Hash{{0x1}, {0x2}}
+
+	input := PackRevealBatch(indices, preimages)
+	assert.Assert(t, bytes.Equal(input[:selectorLen], RevealBatchSignature))
+	assert.Equal(t, len(input), selectorLen+common.HashLength*8)
+
+	unpackedIndices, unpackedPreimages, err := UnpackRevealBatch(input[selectorLen:])
+	assert.NilError(t, err)
+	assert.Equal(t, len(unpackedIndices), len(indices))
+	for i := range indices {
+		assert.Assert(t, unpackedIndices[i].Cmp(indices[i]) == 0)
+		assert.Equal(t, unpackedPreimages[i], preimages[i])
+	}
+
+	for name, input := range map[string][]byte{
+		"empty":              {},
+		"empty arrays":       PackRevealBatch(nil, nil)[selectorLen:],
+		"mismatched lengths": PackRevealBatch(indices, preimages[:1])[selectorLen:],
+		"truncated":          input[selectorLen : len(input)-1],
+		"offset overflow":    append(math.MaxBig256.Bytes(), input[selectorLen+common.HashLength:]...),
+	} {
+		_, _, err := UnpackRevealBatch(input)
+		assert.Assert(t, err != nil, name)
+	}
+}
+
+// payoutObservingStateDB calls [onAddBalance] before any balance is credited.
+type payoutObservingStateDB struct {
+	*countingStateDB
+	onAddBalance func(addr common.Address)
+}
+
+func (s *payoutObservingStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	if s.onAddBalance != nil {
+		s.onAddBalance(addr)
+	}
+	s.countingStateDB.AddBalance(addr, amount)
+}
+
+type payoutObservingAccessibleState struct {
+	state     *payoutObservingStateDB
+	blockTime *big.Int
+}
+
+func (s *payoutObservingAccessibleState) GetStateDB() StateDB   { return s.state }
+func (s *payoutObservingAccessibleState) BlockTime() *big.Int   { return s.blockTime }
+func (s *payoutObservingAccessibleState) BlockNumber() *big.Int { return common.Big0 }
+func (s *payoutObservingAccessibleState) BlockHash(uint64) common.Hash {
+	return common.Hash{}
+}
+func (s *payoutObservingAccessibleState) CallFromPrecompile(_, _ common.Address, _ []byte, gas uint64) ([]byte, uint64, error) {
+	return nil, gas, nil
+}
+
+func TestVerifyReveal(t *testing.T) {
+	preimage := common.Hash{0x1}
+	keccak := crypto.Keccak256Hash(preimage.Bytes())
+	sha := common.Hash(sha256.Sum256(preimage.Bytes()))
+
+	assert.Assert(t, VerifyReveal(keccak, preimage))
+	assert.Assert(t, !VerifyReveal(keccak, common.Hash{0x2}))
+	assert.Assert(t, !VerifyReveal(sha, preimage))
+
+	assert.Assert(t, CommitHashSHA256.VerifyReveal(sha, preimage))
+	assert.Assert(t, !CommitHashSHA256.VerifyReveal(sha, common.Hash{0x2}))
+	assert.Assert(t, !CommitHashSHA256.VerifyReveal(keccak, preimage))
+	assert.Assert(t, !CommitHashAlgo(2).VerifyReveal(keccak, preimage))
+
+	// Pairs accepted by VerifyReveal are accepted by reveal
+	for _, algo := range []CommitHashAlgo{CommitHashKeccak256, CommitHashSHA256} {
+		state := newCountingStateDB()
+		(&RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000), CommitHashAlgo: algo}).Configure(state)
+		commitment, err := algo.Hash(preimage.Bytes())
+		assert.NilError(t, err)
+		assert.Assert(t, GetCommitHashAlgo(state).VerifyReveal(commitment, preimage))
+
+		for _, call := range []struct {
+			btime int64
+			input []byte
+			value *big.Int
+		}{
+			{10, StartSignature, common.Big0},
+			{11, PackCommit(commitment), big.NewInt(1000)},
+			{14, PackReveal(common.Big0, preimage), common.Big0},
+		} {
+			state.AddBalance(RandomPartyAddress, call.value)
+			accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(call.btime)}
+			_, _, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, call.input, 1_000_000, call.value, false)
+			assert.NilError(t, err)
+		}
+	}
+}
+
+func TestRandomPartyComputeFinalizesBeforePayouts(t *testing.T) {
+	state := &payoutObservingStateDB{countingStateDB: newCountingStateDB()}
+	SetPhaseSeconds(state, big.NewInt(3))
+	SetCommitStake(state, big.NewInt(1000))
+	revealer := common.Address{0x1}
+	preimage := common.Hash{0x1}
+
+	run := func(btime int64, input []byte, value *big.Int) error {
+		// Value is transferred to the precompile before it runs (like the EVM)
+		if value.Sign() > 0 {
+			state.AddBalance(RandomPartyAddress, value)
+		}
+		accessibleState := &payoutObservingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+		_, _, err := RandomPartyPrecompile.Run(accessibleState, revealer, RandomPartyAddress, input, 1_000_000, value, false)
+		return err
+	}
+	assert.NilError(t, run(10, StartSignature, common.Big0))
+	assert.NilError(t, run(11, SponsorSignature, big.NewInt(100)))
+	assert.NilError(t, run(11, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000)))
+	assert.NilError(t, run(14, PackReveal(common.Big0, preimage), common.Big0))
+
+	payouts := 0
+	state.onAddBalance = func(addr common.Address) {
+		payouts++
+		assert.Equal(t, addr, revealer)
+		_, _, ok := getDeadlines(state)
+		assert.Assert(t, !ok, "party still underway during payout")
+		assert.Assert(t, getBig(state, resultPrefix).Cmp(common.Big1) == 0, "result not recorded before payout")
+		assert.Equal(t, getBig(state, rewardPrefix).Sign(), 0)
+	}
+	assert.NilError(t, run(16, ComputeSignature, common.Big0))
+	assert.Equal(t, payouts, 1)
+}
+
+func TestRandomPartySchemaVersion(t *testing.T) {
+	schemaVersion := func(state *countingStateDB) uint64 {
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		ret, remainingGas, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, SchemaVersionSignature, SchemaVersionGasCost, common.Big0, true)
+		assert.NilError(t, err)
+		assert.Equal(t, remainingGas, uint64(0))
+		return new(big.Int).SetBytes(ret).Uint64()
+	}
+
+	// State that was never configured has the legacy layout
+	state := newCountingStateDB()
+	assert.Equal(t, GetRandomPartySchemaVersion(state), uint64(0))
+	assert.Equal(t, schemaVersion(state), uint64(0))
+
+	(&RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000)}).Configure(state)
+	assert.Equal(t, GetRandomPartySchemaVersion(state), RandomPartySchemaVersion)
+	assert.Equal(t, schemaVersion(state), RandomPartySchemaVersion)
+
+	SetRandomPartySchemaVersion(state, 0)
+	assert.Equal(t, GetRandomPartySchemaVersion(state), uint64(0))
+	assert.NilError(t, Migrate(state, 0, RandomPartySchemaVersion))
+	assert.Equal(t, schemaVersion(state), RandomPartySchemaVersion)
+}
+
+func TestRandomPartyPhaseDurationUnset(t *testing.T) {
+	start := func(state *countingStateDB) error {
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(10)}
+		_, _, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, StartSignature, StartGasCost, common.Big0, false)
+		return err
+	}
+
+	// Un-configured state
+	state := newCountingStateDB()
+	assert.Assert(t, errors.Is(start(state), ErrPhaseDurationUnset))
+	_, _, ok := getDeadlines(state)
+	assert.Assert(t, !ok)
+
+	// Configured without a phase duration
+	state = newCountingStateDB()
+	(&RandomPartyConfig{CommitStake: big.NewInt(1000)}).Configure(state)
+	assert.Assert(t, errors.Is(start(state), ErrPhaseDurationUnset))
+
+	SetPhaseSeconds(state, big.NewInt(3))
+	assert.NilError(t, start(state))
+	commitDeadline, revealDeadline, ok := getDeadlines(state)
+	assert.Assert(t, ok)
+	assert.Equal(t, commitDeadline.Int64(), int64(13))
+	assert.Equal(t, revealDeadline.Int64(), int64(16))
+}
+
+func TestRandomPartyResultFraction(t *testing.T) {
+	state := newCountingStateDB()
+	SetPhaseSeconds(state, big.NewInt(3))
+	SetCommitStake(state, big.NewInt(1000))
+	committer := common.Address{0x1}
+	preimage := common.Hash{0x1}
+
+	run := func(btime int64, input []byte, value *big.Int) ([]byte, error) {
+		state.AddBalance(RandomPartyAddress, value)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+		ret, _, err := RandomPartyPrecompile.Run(accessibleState, committer, RandomPartyAddress, input, 1_000_000, value, false)
+		return ret, err
+	}
+	fraction := func(round int64, precision *big.Int) ([]byte, error) {
+		return run(16, PackResultFraction(big.NewInt(round), precision), common.Big0)
+	}
+
+	for _, step := range []struct {
+		btime int64
+		input []byte
+		value *big.Int
+	}{
+		{10, StartSignature, common.Big0},
+		{11, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000)},
+		{14, PackReveal(common.Big0, preimage), common.Big0},
+		{16, ComputeSignature, common.Big0},
+	} {
+		_, err := run(step.btime, step.input, step.value)
+		assert.NilError(t, err)
+	}
+	ret, err := run(16, PackResult(common.Big0), common.Big0)
+	assert.NilError(t, err)
+	result := new(big.Int).SetBytes(ret)
+
+	for _, precision := range []*big.Int{
+		common.Big1,
+		common.Big2,
+		big.NewInt(100),
+		big.NewInt(1_000_000_007),
+		math.MaxBig256,
+	} {
+		ret, err := fraction(0, precision)
+		assert.NilError(t, err)
+		expected := new(big.Int).Mod(result, precision)
+		assert.Assert(t, new(big.Int).SetBytes(ret).Cmp(expected) == 0, "precision %d", precision)
+		assert.Assert(t, new(big.Int).SetBytes(ret).Cmp(precision) < 0)
+	}
+
+	_, err = fraction(0, common.Big0)
+	assert.Assert(t, errors.Is(err, ErrZeroPrecision))
+	_, err = fraction(1, big.NewInt(100))
+	assert.Assert(t, errors.Is(err, ErrRoundNotComputed))
+}
+
+func TestRandomPartyKeysDoNotAlias(t *testing.T) {
+	// A prefix with spare capacity would be overwritten in place by a key
+	// built with append
+	pfx := make([]byte, 1, 64)
+	pfx[0] = 0x3
+	addr := common.Address{0x1}
+
+	round1 := partyPrefix(pfx, common.Big1)
+	round2 := partyPrefix(pfx, common.Big2)
+	assert.Assert(t, !bytes.Equal(round1, round2))
+	assert.DeepEqual(t, round1, []byte{0x3, delim, 0, 0, 0, 0, 0, 0, 0, 1})
+	assert.DeepEqual(t, pfx, []byte{0x3})
+
+	// Entries written by different helpers under the same prefix never share
+	// a slot
+	state := newCountingStateDB()
+	idx := addCounterHash(state, round1, common.Hash{0xa})
+	setAddrBig(state, round1, addr, big.NewInt(7))
+	setIdxAddress(state, round2, common.Big0, addr)
+	assert.Assert(t, fastKey(round1, idx) != addrKey(round1, addr))
+	assert.Equal(t, getCounterHash(state, round1, idx), common.Hash{0xa})
+	assert.Equal(t, getAddrBig(state, round1, addr).Int64(), int64(7))
+	assert.Equal(t, getIdxAddress(state, round2, common.Big0), addr)
+	assert.Equal(t, getBig(state, round1).Int64(), int64(1))
+	assert.DeepEqual(t, pfx, []byte{0x3})
+}
+
+func TestRandomPartyPrefixesUnchanged(t *testing.T) {
+	prefixes := map[string][]byte{
+		"commitPrefix":      commitPrefix,
+		"revealPrefix":      revealPrefix,
+		"resultPrefix":      resultPrefix,
+		"commitOwnerPrefix": commitOwnerPrefix,
+		"rewardPrefix":      rewardPrefix,
+		"commitStakePrefix": commitStakePrefix,
+	}
+	original := make(map[string][]byte, len(prefixes))
+	for name, pfx := range prefixes {
+		original[name] = common.CopyBytes(pfx)
+	}
+
+	state := newCountingStateDB()
+	for i := 0; i < 100; i++ {
+		for _, pfx := range prefixes {
+			addCounterHash(state, pfx, common.BigToHash(big.NewInt(int64(i+1))))
+			addCounterHash(state, partyPrefix(pfx, big.NewInt(int64(i))), common.Hash{0x1})
+		}
+	}
+	for name, pfx := range prefixes {
+		assert.DeepEqual(t, pfx, original[name])
+		assert.Equal(t, getBig(state, pfx).Int64(), int64(100), name)
+		assert.Equal(t, getCounterHash(state, pfx, big.NewInt(99)), common.BigToHash(big.NewInt(100)), name)
+	}
+
+	// Packing inputs never writes to the shared function selectors, so earlier
+	// inputs are not modified by later ones
+	first := PackCommit(common.Hash{0x1})
+	PackCommit(common.Hash{0x2})
+	PackReveal(common.Big1, common.Hash{0x3})
+	PackResult(common.Big2)
+	PackComputableAt(common.Big3)
+	assert.DeepEqual(t, first[selectorLen:], common.Hash{0x1}.Bytes())
+	assert.DeepEqual(t, first[:selectorLen], CommitSignature)
+}
+
+func TestRandomPartyStorageKey(t *testing.T) {
+	state := newCountingStateDB()
+	SetPhaseSeconds(state, big.NewInt(3))
+	SetCommitStake(state, big.NewInt(1000))
+	SetNoRevealsBehavior(state, NoRevealsBlockHash)
+	committer, revealer := common.Address{0x1}, common.Address{0x2}
+	preimages := []common.Hash{{0x1}, {0x2}}
+
+	run := func(caller common.Address, btime int64, input []byte, value *big.Int) []byte {
+		state.AddBalance(RandomPartyAddress, value)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+		ret, _, err := RandomPartyPrecompile.Run(accessibleState, caller, RandomPartyAddress, input, 1_000_000, value, false)
+		assert.NilError(t, err)
+		return ret
+	}
+	slot := func(list RandomPartyList, round int64, idx int64) common.Hash {
+		key, err := RandomPartyStorageKey(list, big.NewInt(round), big.NewInt(idx))
+		assert.NilError(t, err)
+		return state.GetState(RandomPartyAddress, key)
+	}
+	length := func(list RandomPartyList, round int64) *big.Int {
+		key, err := RandomPartyLengthKey(list, big.NewInt(round))
+		assert.NilError(t, err)
+		return state.GetState(RandomPartyAddress, key).Big()
+	}
+
+	// Compute an empty round first so the party below is not round 0
+	run(committer, 0, StartSignature, common.Big0)
+	run(committer, 6, ComputeSignature, common.Big0)
+
+	round := new(big.Int).SetBytes(run(committer, 10, NextSignature, common.Big0)).Int64()
+	assert.Equal(t, round, int64(1))
+	run(committer, 10, StartSignature, common.Big0)
+	for _, preimage := range preimages {
+		run(committer, 11, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000))
+	}
+	assert.Equal(t, length(RandomPartyCommitments, round).Int64(), int64(2))
+	for i, preimage := range preimages {
+		assert.Equal(t, slot(RandomPartyCommitments, round, int64(i)), crypto.Keccak256Hash(preimage.Bytes()))
+		assert.Equal(t, slot(RandomPartyCommitOwners, round, int64(i)), committer.Hash())
+	}
+	// Entries of other rounds are not visible at the same indices
+	assert.Equal(t, slot(RandomPartyCommitments, round+1, 0), common.Hash{})
+
+	run(revealer, 14, PackReveal(common.Big1, preimages[1]), common.Big0)
+	assert.Equal(t, slot(RandomPartyCommitments, round, 1), common.Hash{})
+	assert.Equal(t, slot(RandomPartyCommitOwners, round, 1), common.Hash{})
+	assert.Equal(t, length(RandomPartyReveals, round).Int64(), int64(1))
+	assert.Equal(t, slot(RandomPartyReveals, round, 0), preimages[1])
+	// Rewards for a reveal go to the owner of the commitment
+	assert.Equal(t, slot(RandomPartyRevealRecipients, round, 0), committer.Hash())
+
+	// The results list is shared by all rounds
+	run(committer, 16, ComputeSignature, common.Big0)
+	latest := run(committer, 16, LatestResultSignature, common.Big0)
+	assert.Equal(t, length(RandomPartyResults, round).Int64(), int64(2))
+	assert.Equal(t, slot(RandomPartyResults, 0, 1), common.BytesToHash(latest))
+	assert.Equal(t, slot(RandomPartyResults, round, 1), common.BytesToHash(latest))
+
+	_, err := RandomPartyStorageKey(RandomPartyResults+1, common.Big0, common.Big0)
+	assert.Assert(t, err != nil)
+}
+
+func TestRandomPartyMaxStoredState(t *testing.T) {
+	state := newCountingStateDB()
+	(&RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000), MaxStoredState: 3}).Configure(state)
+	committer := common.Address{0x1}
+
+	run := func(btime int64, input []byte, value *big.Int) error {
+		state.AddBalance(RandomPartyAddress, value)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+		_, _, err := RandomPartyPrecompile.Run(accessibleState, committer, RandomPartyAddress, input, 1_000_000, value, false)
+		return err
+	}
+	commit := func(btime int64, preimage common.Hash) error {
+		return run(btime, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000))
+	}
+
+	// Round 0: no results stored, so 2 commitments fit
+	assert.NilError(t, run(10, StartSignature, common.Big0))
+	assert.NilError(t, commit(11, common.Hash{0x1}))
+	assert.NilError(t, commit(11, common.Hash{0x2}))
+	assert.Assert(t, errors.Is(commit(11, common.Hash{0x3}), ErrStateLimitReached))
+	assert.NilError(t, run(14, PackReveal(common.Big0, common.Hash{0x1}), common.Big0))
+	assert.NilError(t, run(16, ComputeSignature, common.Big0))
+
+	// Round 1: 1 result stored, so only 1 commitment fits
+	assert.NilError(t, run(20, StartSignature, common.Big0))
+	assert.NilError(t, commit(21, common.Hash{0x4}))
+	assert.Assert(t, errors.Is(commit(21, common.Hash{0x5}), ErrStateLimitReached))
+	assert.NilError(t, run(24, PackReveal(common.Big0, common.Hash{0x4}), common.Big0))
+	assert.NilError(t, run(26, ComputeSignature, common.Big0))
+
+	// Round 2: no room for another result and commitment
+	assert.Assert(t, errors.Is(run(30, StartSignature, common.Big0), ErrStateLimitReached))
+	_, _, ok := getDeadlines(state)
+	assert.Assert(t, !ok)
+
+	// Lifting the limit allows starting again
+	SetMaxStoredState(state, 0)
+	assert.NilError(t, run(30, StartSignature, common.Big0))
+	assert.NilError(t, commit(31, common.Hash{0x6}))
+	assert.NilError(t, commit(31, common.Hash{0x7}))
+}
+
+func TestRandomPartyMinCommitFee(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		minFee      *big.Int
+		fee         *big.Int
+		expectedErr error
+	}{
+		{name: "no floor", fee: common.Big0},
+		{name: "no floor and no fee"},
+		{name: "zero floor", minFee: common.Big0, fee: common.Big0},
+		{name: "at floor", minFee: big.NewInt(1000), fee: big.NewInt(1000)},
+		{name: "above floor", minFee: big.NewInt(1000), fee: big.NewInt(1001)},
+		{name: "below floor", minFee: big.NewInt(1000), fee: big.NewInt(999), expectedErr: ErrCommitFeeBelowMin},
+		{name: "no fee with floor", minFee: big.NewInt(1000), expectedErr: ErrCommitFeeBelowMin},
+	} {
+		err := (&RandomPartyConfig{CommitStake: test.fee, MinCommitFee: test.minFee}).Verify()
+		if test.expectedErr == nil {
+			assert.NilError(t, err, test.name)
+		} else {
+			assert.Assert(t, errors.Is(err, test.expectedErr), test.name)
+		}
+	}
+
+	// setCommitFee is held to the same floor
+	admin := common.Address{0x1}
+	state := newCountingStateDB()
+	(&RandomPartyConfig{
+		PhaseSeconds:    big.NewInt(3),
+		CommitStake:     big.NewInt(1000),
+		MinCommitFee:    big.NewInt(500),
+		AllowListAdmins: []common.Address{admin},
+	}).Configure(state)
+	setCommitFee := func(fee int64) error {
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(5)}
+		_, _, err := RandomPartyPrecompile.Run(accessibleState, admin, RandomPartyAddress, PackSetCommitFee(big.NewInt(fee)), SetCommitFeeGasCost, common.Big0, false)
+		return err
+	}
+	assert.Assert(t, errors.Is(setCommitFee(499), ErrCommitFeeBelowMin))
+	assert.Assert(t, errors.Is(setCommitFee(0), ErrCommitFeeBelowMin))
+	assert.Assert(t, getBig(state, commitStakeKey).Cmp(big.NewInt(1000)) == 0)
+	assert.NilError(t, setCommitFee(500))
+	assert.Assert(t, getBig(state, commitStakeKey).Cmp(big.NewInt(500)) == 0)
+}
+
+func TestRandomPartyReexecution(t *testing.T) {
+	committer := common.Address{0x1}
+	preimage := common.Hash{0x1}
+	parent := newCountingStateDB()
+	(&RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000)}).Configure(parent)
+	parent.AddBalance(committer, big.NewInt(1000))
+
+	run := func(state *countingStateDB, btime int64, input []byte, value *big.Int) ([]byte, error) {
+		state.SubBalance(committer, value)
+		state.AddBalance(RandomPartyAddress, value)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+		ret, _, err := RandomPartyPrecompile.Run(accessibleState, committer, RandomPartyAddress, input, 1_000_000, value, false)
+		return ret, err
+	}
+	view := func(state *countingStateDB, btime int64, input []byte) int64 {
+		ret, err := run(state, btime, input, common.Big0)
+		assert.NilError(t, err)
+		return new(big.Int).SetBytes(ret).Int64()
+	}
+	// assertConsistent checks that the views of [state] at [btime] agree with
+	// a Random Party started at [start]
+	assertConsistent := func(state *countingStateDB, start int64, btime int64) {
+		commitDeadline, revealDeadline, ok := getDeadlines(state)
+		assert.Assert(t, ok)
+		assert.Equal(t, commitDeadline.Int64(), start+3)
+		assert.Equal(t, revealDeadline.Int64(), start+6)
+		assert.Equal(t, view(state, btime, StartTimeSignature), start)
+		commitRemaining := view(state, btime, GetCommitDeadlineRemainingSignature)
+		revealRemaining := view(state, btime, GetRevealDeadlineRemainingSignature)
+		assert.Assert(t, commitRemaining >= 0 && commitRemaining <= 3)
+		assert.Assert(t, revealRemaining >= 0 && revealRemaining <= 6)
+		assert.Assert(t, revealRemaining >= commitRemaining)
+		assert.Equal(t, view(state, btime, PackComputableAt(revealDeadline)), int64(1))
+		assert.Equal(t, view(state, btime, PackComputableAt(new(big.Int).Sub(revealDeadline, common.Big1))), int64(0))
+	}
+
+	// The same start and commit are executed in blocks with different times
+	// on each side of a re-org (the commit is always executed in a block at
+	// time 12)
+	for _, test := range []struct {
+		start       int64
+		expectedErr error
+	}{
+		{start: 10},
+		{start: 11},
+		// The commit deadline has passed by the time the commit is re-executed
+		{start: 9, expectedErr: ErrTooLate},
+		{start: 8, expectedErr: ErrTooLate},
+	} {
+		state := copyState(parent)
+		_, err := run(state, test.start, StartSignature, common.Big0)
+		assert.NilError(t, err)
+		assertConsistent(state, test.start, test.start)
+
+		_, err = run(state, 12, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), big.NewInt(1000))
+		if test.expectedErr != nil {
+			assert.Assert(t, errors.Is(err, test.expectedErr), "start %d: %v", test.start, err)
+			// Reverted like the EVM would
+			state.SubBalance(RandomPartyAddress, big.NewInt(1000))
+			state.AddBalance(committer, big.NewInt(1000))
+		} else {
+			assert.NilError(t, err, "start %d", test.start)
+		}
+		assertConsistent(state, test.start, 12)
+
+		// The party is never stuck: it can always be finished (or replaced if
+		// no one committed) once its reveal deadline passes
+		end := test.start + 6
+		if test.expectedErr == nil {
+			_, err = run(state, test.start+3, PackReveal(common.Big0, preimage), common.Big0)
+			assert.NilError(t, err)
+			_, err = run(state, end, ComputeSignature, common.Big0)
+			assert.NilError(t, err)
+		}
+		_, err = run(state, end, StartSignature, common.Big0)
+		assert.NilError(t, err, "start %d", test.start)
+		assertConsistent(state, end, end)
+		assert.Assert(t, state.GetBalance(committer).Cmp(big.NewInt(1000)) == 0)
+	}
+}
+
+func TestRandomPartyVerifyForfeitDestination(t *testing.T) {
+	for _, dest := range []ForfeitDestination{ForfeitToRevealers, ForfeitToPool, ForfeitToBurn} {
+		assert.NilError(t, (&RandomPartyConfig{ForfeitDestination: dest}).Verify())
+	}
+	err := (&RandomPartyConfig{ForfeitDestination: ForfeitToRecipient + 1}).Verify()
+	assert.Assert(t, errors.Is(err, ErrUnknownForfeitDest), err)
+}
+
+func TestRandomPartyVerifyForfeitRecipient(t *testing.T) {
+	// The recipient is only required for [ForfeitToRecipient]
+	assert.NilError(t, (&RandomPartyConfig{ForfeitDestination: ForfeitToPool}).Verify())
+	assert.NilError(t, (&RandomPartyConfig{ForfeitDestination: ForfeitToRecipient, ForfeitRecipient: common.Address{0x1}}).Verify())
+
+	err := (&RandomPartyConfig{ForfeitDestination: ForfeitToRecipient}).Verify()
+	assert.Assert(t, errors.Is(err, ErrNoForfeitRecipient), err)
+	err = (&RandomPartyConfig{ForfeitDestination: ForfeitToRecipient, ForfeitRecipient: RandomPartyAddress}).Verify()
+	assert.Assert(t, errors.Is(err, ErrInvalidRecipient), err)
+}
+
+func TestRandomPartyVerifyResultCallback(t *testing.T) {
+	assert.NilError(t, (&RandomPartyConfig{ResultCallback: common.Address{0x1}}).Verify())
+	err := (&RandomPartyConfig{ResultCallback: RandomPartyAddress}).Verify()
+	assert.Assert(t, errors.Is(err, ErrInvalidRecipient), err)
+}
+
+func TestRandomPartyStartCleanupAtomic(t *testing.T) {
+	committers := []common.Address{{0x1}, {0x2}, {0x3}}
+	recipient := common.Address{0x4}
+	preimage := common.Hash{0x1}
+
+	for _, test := range []struct {
+		name        string
+		config      RandomPartyConfig
+		cleanupCost uint64
+	}{
+		{
+			name:        "revealers",
+			config:      RandomPartyConfig{ForfeitDestination: ForfeitToRevealers},
+			cleanupCost: DeleteGasCost*4 + ComputeRewardCost,
+		},
+		{
+			name:        "recipient",
+			config:      RandomPartyConfig{ForfeitDestination: ForfeitToRecipient, ForfeitRecipient: recipient},
+			cleanupCost: DeleteGasCost*4 + LogGasCost(3, common.HashLength),
+		},
+	} {
+		state := newCountingStateDB()
+		test.config.PhaseSeconds = big.NewInt(3)
+		test.config.CommitStake = big.NewInt(1000)
+		test.config.MaxCommitsPerAddress = 1
+		test.config.Configure(state)
+		run := func(caller common.Address, btime int64, input []byte, suppliedGas uint64, value *big.Int) error {
+			state.SubBalance(caller, value)
+			state.AddBalance(RandomPartyAddress, value)
+			accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+			_, remainingGas, err := RandomPartyPrecompile.Run(accessibleState, caller, RandomPartyAddress, input, suppliedGas, value, false)
+			if err == nil {
+				assert.Equal(t, remainingGas, uint64(0), test.name)
+			}
+			return err
+		}
+
+		// Only the first of three commitments is revealed
+		assert.NilError(t, run(committers[0], 10, StartSignature, StartGasCost, common.Big0))
+		for i, committer := range committers {
+			state.AddBalance(committer, big.NewInt(1000))
+			h := crypto.Keccak256Hash(common.Hash{byte(i + 1)}.Bytes())
+			assert.NilError(t, run(committer, 11, PackCommit(h), CommitGasCost, big.NewInt(1000)))
+		}
+		assert.NilError(t, run(committers[0], 14, PackReveal(common.Big0, preimage), RevealGasCost, common.Big0))
+		assert.NilError(t, run(committers[0], 17, ComputeSignature, ComputeGasCost+ComputeItemCost+LogGasCost(2, common.HashLength), common.Big0))
+
+		// Running out of gas at any point of the cleanup leaves the previous
+		// Random Party untouched
+		before := copyState(state)
+		for _, missing := range []uint64{1, DeleteGasCost, test.cleanupCost} {
+			err := run(committers[0], 17, StartSignature, StartGasCost+test.cleanupCost-missing, common.Big0)
+			assert.Assert(t, errors.Is(err, vmerrs.ErrOutOfGas), "%s: %v", test.name, err)
+			assert.DeepEqual(t, state.storage, before.storage)
+			assert.Equal(t, len(state.balances), len(before.balances))
+			for addr, balance := range before.balances {
+				assert.Equal(t, state.GetBalance(addr).Cmp(balance), 0, "%s: %s", test.name, addr)
+			}
+		}
+
+		assert.NilError(t, run(committers[0], 17, StartSignature, StartGasCost+test.cleanupCost, common.Big0))
+		assert.Equal(t, getBig(state, lastForfeitCountKey).Int64(), int64(2), test.name)
+		assert.Equal(t, getBig(state, currentPartyKeys(state).commits).Sign(), 0, test.name)
+	}
+}
+
+func TestRandomPartyForfeitRemainder(t *testing.T) {
+	committers := []common.Address{{0x11}, {0x12}, {0x13}, {0x14}}
+	state := newCountingStateDB()
+	(&RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000), ForfeitDestination: ForfeitToRevealers}).Configure(state)
+	run := func(caller common.Address, btime int64, input []byte, value *big.Int) {
+		state.SubBalance(caller, value)
+		state.AddBalance(RandomPartyAddress, value)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+		_, _, err := RandomPartyPrecompile.Run(accessibleState, caller, RandomPartyAddress, input, 10_000_000, value, false)
+		assert.NilError(t, err)
+	}
+
+	// Three of four commitments are revealed, so the forfeited stake of 1000
+	// cannot be split equally amongst the revealers
+	run(committers[0], 10, StartSignature, common.Big0)
+	for i, committer := range committers {
+		state.AddBalance(committer, big.NewInt(1000))
+		run(committer, 11, PackCommit(crypto.Keccak256Hash(common.Hash{byte(i + 1)}.Bytes())), big.NewInt(1000))
+	}
+	for i := range committers[:3] {
+		run(committers[i], 14, PackReveal(big.NewInt(int64(i)), common.Hash{byte(i + 1)}), common.Big0)
+	}
+	run(committers[0], 17, ComputeSignature, common.Big0)
+	run(committers[0], 17, StartSignature, common.Big0)
+
+	for _, committer := range committers[:3] {
+		assert.Equal(t, state.GetBalance(committer).Int64(), int64(1333))
+	}
+	assert.Equal(t, state.GetBalance(committers[3]).Sign(), 0)
+	assert.Equal(t, getBig(state, rewardPrefix).Int64(), int64(1))
+	assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(1))
+}
+
+func TestRandomPartyRefundRevealGas(t *testing.T) {
+	committer := common.Address{0x1}
+	preimages := []common.Hash{{0x1}, {0x2}, {0x3}, {0x4}}
+
+	for _, test := range []struct {
+		name     string
+		refund   uint64
+		expected uint64 // refund of a single reveal
+	}{
+		{name: "disabled", refund: 0, expected: 0},
+		{name: "partial", refund: 4_000, expected: 4_000},
+		// Refunds are capped at the cost of a reveal
+		{name: "capped", refund: 1_000_000, expected: RevealGasCost},
+	} {
+		state := newCountingStateDB()
+		(&RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000), RefundRevealGas: test.refund}).Configure(state)
+		run := func(btime int64, input []byte, suppliedGas uint64, value *big.Int) (uint64, error) {
+			state.SubBalance(committer, value)
+			state.AddBalance(RandomPartyAddress, value)
+			accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+			_, remainingGas, err := RandomPartyPrecompile.Run(accessibleState, committer, RandomPartyAddress, input, suppliedGas, value, false)
+			return remainingGas, err
+		}
+
+		_, err := run(10, StartSignature, StartGasCost, common.Big0)
+		assert.NilError(t, err)
+		for _, preimage := range preimages {
+			state.AddBalance(committer, big.NewInt(1000))
+			_, err := run(11, PackCommit(crypto.Keccak256Hash(preimage.Bytes())), CommitGasCost, big.NewInt(1000))
+			assert.NilError(t, err)
+		}
+
+		// A failed reveal is not refunded
+		remainingGas, err := run(14, PackReveal(common.Big0, common.Hash{0xff}), RevealGasCost+100, common.Big0)
+		assert.Assert(t, err != nil, test.name)
+		assert.Equal(t, remainingGas, uint64(100), test.name)
+
+		remainingGas, err = run(14, PackReveal(common.Big0, preimages[0]), RevealGasCost+100, common.Big0)
+		assert.NilError(t, err, test.name)
+		assert.Equal(t, remainingGas, 100+test.expected, test.name)
+		assert.Assert(t, remainingGas <= RevealGasCost+100, test.name)
+
+		// Each reveal of a batch is refunded
+		remainingGas, err = run(14, PackRevealBatch([]*big.Int{common.Big1, common.Big2}, []common.Hash{preimages[1], preimages[2]}), RevealBatchGasCost+2*RevealGasCost, common.Big0)
+		assert.NilError(t, err, test.name)
+		assert.Equal(t, remainingGas, 2*test.expected, test.name)
+
+		remainingGas, err = run(14, PackRevealAndClaim(big.NewInt(3), preimages[3]), RevealAndClaimGasCost, common.Big0)
+		assert.NilError(t, err, test.name)
+		assert.Equal(t, remainingGas, test.expected, test.name)
+	}
+}
+
+func TestRandomPartyIsSolvent(t *testing.T) {
+	committer := common.Address{0x1}
+	sponsor := common.Address{0x2}
+	state := newCountingStateDB()
+	config := RandomPartyConfig{PhaseSeconds: big.NewInt(3), CommitStake: big.NewInt(1000)}
+	config.Configure(state)
+	run := func(caller common.Address, input []byte, suppliedGas uint64, value *big.Int) ([]byte, error) {
+		state.SubBalance(caller, value)
+		state.AddBalance(RandomPartyAddress, value)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(11)}
+		ret, _, err := RandomPartyPrecompile.Run(accessibleState, caller, RandomPartyAddress, input, suppliedGas, value, false)
+		return ret, err
+	}
+	isSolvent := func() bool {
+		ret, err := run(committer, IsSolventSignature, IsSolventGasCost, common.Big0)
+		assert.NilError(t, err)
+		return new(big.Int).SetBytes(ret).Sign() != 0
+	}
+
+	assert.Assert(t, isSolvent())
+	_, err := run(committer, StartSignature, StartGasCost, common.Big0)
+	assert.NilError(t, err)
+	state.AddBalance(sponsor, big.NewInt(500))
+	_, err = run(sponsor, SponsorSignature, SponsorGasCost, big.NewInt(500))
+	assert.NilError(t, err)
+	state.AddBalance(committer, big.NewInt(1000))
+	_, err = run(committer, PackCommit(crypto.Keccak256Hash(common.Hash{0x1}.Bytes())), CommitGasCost, big.NewInt(1000))
+	assert.NilError(t, err)
+	assert.Assert(t, isSolvent())
+
+	// The precompile owes the incentive pool and the locked stake
+	state.SubBalance(RandomPartyAddress, common.Big1)
+	assert.Assert(t, !isSolvent())
+	state.AddBalance(RandomPartyAddress, common.Big1)
+	assert.Assert(t, isSolvent())
+
+	_, _, err = RandomPartyPrecompile.Run(&countingAccessibleState{state: state, blockTime: common.Big0}, committer, RandomPartyAddress, IsSolventSignature, IsSolventGasCost-1, common.Big0, true)
+	assert.Assert(t, errors.Is(err, vmerrs.ErrOutOfGas), err)
+	_, err = run(committer, append(IsSolventSignature, 0x1), IsSolventGasCost, common.Big0)
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "invalid input length for isSolvent"), err)
+}
+
+func TestRandomPartyAlignToEpoch(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		phaseSeconds   int64
+		epoch          uint64
+		startTime      int64
+		commitDeadline int64
+		revealDeadline int64
+		noRevealMode   bool
+	}{
+		{name: "disabled", phaseSeconds: 3, startTime: 12, commitDeadline: 15, revealDeadline: 18},
+		{name: "rounded up", phaseSeconds: 3, epoch: 10, startTime: 12, commitDeadline: 20, revealDeadline: 30},
+		{name: "on boundary", phaseSeconds: 5, epoch: 10, startTime: 15, commitDeadline: 20, revealDeadline: 30},
+		{name: "longer than epoch", phaseSeconds: 25, epoch: 10, startTime: 1, commitDeadline: 30, revealDeadline: 60},
+		{name: "no reveal mode", phaseSeconds: 3, epoch: 10, startTime: 12, commitDeadline: 20, revealDeadline: 20, noRevealMode: true},
+	} {
+		state := newCountingStateDB()
+		config := RandomPartyConfig{
+			PhaseSeconds: big.NewInt(test.phaseSeconds),
+			CommitStake:  big.NewInt(1000),
+			AlignToEpoch: test.epoch,
+			NoRevealMode: test.noRevealMode,
+		}
+		config.Configure(state)
+		accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(test.startTime)}
+		_, _, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, StartSignature, StartGasCost, common.Big0, false)
+		assert.NilError(t, err, test.name)
+		commitDeadline, revealDeadline, ok := getDeadlines(state)
+		assert.Assert(t, ok, test.name)
+		assert.Equal(t, commitDeadline.Int64(), test.commitDeadline, test.name)
+		assert.Equal(t, revealDeadline.Int64(), test.revealDeadline, test.name)
+	}
+}
+
+func TestRandomPartyGetResult(t *testing.T) {
+	state := newCountingStateDB()
+	assert.Equal(t, GetResult(state, common.Big0), common.Hash{})
+
+	results := []common.Hash{crypto.Keccak256Hash([]byte{0x1}), crypto.Keccak256Hash([]byte{0x2})}
+	for _, h := range results {
+		addCounterHash(state, resultPrefix, h)
+	}
+	accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+	for i, h := range results {
+		round := big.NewInt(int64(i))
+		assert.Equal(t, GetResult(state, round), h)
+
+		// GetResult matches result()
+		ret, _, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, PackResult(round), ResultCost, common.Big0, true)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, ret, h.Bytes())
+	}
+
+	// Rounds that have not been computed are empty
+	assert.Equal(t, GetResult(state, big.NewInt(2)), common.Hash{})
+	assert.Equal(t, GetResult(state, math.MaxBig256), common.Hash{})
+}
+
+// callbackRecordingAccessibleState records the calls made by the precompile
+// (each call uses [gasUsed] and fails with [err]).
+type callbackRecordingAccessibleState struct {
+	*countingAccessibleState
+	gasUsed uint64
+	err     error
+	calls   []callbackCall
+}
+
+type callbackCall struct {
+	caller, addr   common.Address
+	input          []byte
+	gas            uint64
+	commitDeadline *big.Int
+}
+
+func (s *callbackRecordingAccessibleState) CallFromPrecompile(caller, addr common.Address, input []byte, gas uint64) ([]byte, uint64, error) {
+	commitDeadline, _, _ := getDeadlines(s.state)
+	s.calls = append(s.calls, callbackCall{caller, addr, input, gas, commitDeadline})
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	return nil, gas - s.gasUsed, nil
+}
+
+func TestRandomPartyResultCallback(t *testing.T) {
+	callback := common.Address{0xc}
+	committer := common.Address{0x1}
+	preimage := common.Hash{0x1}
+	result := crypto.Keccak256Hash(preimage.Bytes())
+	computeGas := ComputeGasCost + ComputeItemCost + LogGasCost(2, common.HashLength)
+
+	for _, test := range []struct {
+		name      string
+		callback  common.Address
+		gasUsed   uint64
+		err       error
+		autoStart bool
+	}{
+		{name: "no callback"},
+		{name: "callback", callback: callback, gasUsed: 30_000},
+		{name: "failed callback", callback: callback, err: vmerrs.ErrExecutionReverted},
+		{name: "auto compute on start", callback: callback, gasUsed: 30_000, autoStart: true},
+	} {
+		state := newCountingStateDB()
+		config := RandomPartyConfig{
+			PhaseSeconds:       big.NewInt(3),
+			CommitStake:        big.NewInt(1000),
+			ResultCallback:     test.callback,
+			AutoComputeOnStart: test.autoStart,
+		}
+		config.Configure(state)
+		evm := &callbackRecordingAccessibleState{
+			countingAccessibleState: &countingAccessibleState{state: state},
+			gasUsed:                 test.gasUsed,
+			err:                     test.err,
+		}
+		run := func(btime int64, input []byte, suppliedGas uint64, value *big.Int) (uint64, error) {
+			state.SubBalance(committer, value)
+			state.AddBalance(RandomPartyAddress, value)
+			evm.blockTime = big.NewInt(btime)
+			_, remainingGas, err := RandomPartyPrecompile.Run(evm, committer, RandomPartyAddress, input, suppliedGas, value, false)
+			return remainingGas, err
+		}
+
+		_, err := run(10, StartSignature, StartGasCost, common.Big0)
+		assert.NilError(t, err, test.name)
+		state.AddBalance(committer, big.NewInt(1000))
+		_, err = run(11, PackCommit(result), CommitGasCost, big.NewInt(1000))
+		assert.NilError(t, err, test.name)
+		_, err = run(14, PackReveal(common.Big0, preimage), RevealGasCost, common.Big0)
+		assert.NilError(t, err, test.name)
+
+		input, gas := ComputeSignature, computeGas
+		if test.autoStart {
+			input, gas = StartSignature, StartGasCost+computeGas+DeleteGasCost*2
+		}
+		if test.callback == (common.Address{}) {
+			remainingGas, err := run(16, input, gas, common.Big0)
+			assert.NilError(t, err, test.name)
+			assert.Equal(t, remainingGas, uint64(0), test.name)
+			assert.Equal(t, len(evm.calls), 0, test.name)
+			continue
+		}
+
+		// The gas of the callback is charged up front (the failed call runs
+		// against a copy of the state, since this state is never reverted)
+		evm.state = copyState(state)
+		_, err = run(16, input, gas+ResultCallbackGasCost-1, common.Big0)
+		assert.Assert(t, errors.Is(err, vmerrs.ErrOutOfGas), "%s: %v", test.name, err)
+		assert.Equal(t, len(evm.calls), 0, test.name)
+		evm.state = state
+
+		// Unused gas is returned (and failures are ignored)
+		remainingGas, err := run(16, input, gas+ResultCallbackGasCost, common.Big0)
+		assert.NilError(t, err, test.name)
+		assert.Equal(t, GetResult(state, common.Big0), result, test.name)
+		expectedRemaining := ResultCallbackGasCost - test.gasUsed
+		if test.err != nil {
+			expectedRemaining = 0
+		}
+		assert.Equal(t, remainingGas, expectedRemaining, test.name)
+
+		assert.Equal(t, len(evm.calls), 1, test.name)
+		call := evm.calls[0]
+		assert.Equal(t, call.caller, RandomPartyAddress, test.name)
+		assert.Equal(t, call.addr, callback, test.name)
+		assert.Equal(t, call.gas, uint64(ResultCallbackGasCost), test.name)
+		assert.DeepEqual(t, call.input, append(append(ResultCallbackSignature, HBigBytes(common.Big0)...), result.Bytes()...))
+		// The callback is only made once the next Random Party is started
+		if test.autoStart {
+			assert.Equal(t, call.commitDeadline.Int64(), int64(19), test.name)
+		} else {
+			assert.Assert(t, call.commitDeadline == nil, test.name)
+		}
+	}
+}
+
+// reentrantAccessibleState calls the Random Party with [input] from each call
+// made by the precompile (like a [ResultCallback] calling back into it).
+type reentrantAccessibleState struct {
+	*countingAccessibleState
+	input []byte
+	err   error
+}
+
+func (s *reentrantAccessibleState) CallFromPrecompile(_, addr common.Address, _ []byte, gas uint64) ([]byte, uint64, error) {
+	var ret []byte
+	ret, gas, s.err = RandomPartyPrecompile.Run(s, addr, RandomPartyAddress, s.input, gas, common.Big0, false)
+	return ret, gas, s.err
+}
+
+func TestRandomPartyResultCallbackReentrancy(t *testing.T) {
+	committer := common.Address{0x1}
+	preimage := common.Hash{0x1}
+	result := crypto.Keccak256Hash(preimage.Bytes())
+	state := newCountingStateDB()
+	(&RandomPartyConfig{
+		PhaseSeconds:   big.NewInt(3),
+		CommitStake:    big.NewInt(1000),
+		ResultCallback: common.Address{0xc},
+	}).Configure(state)
+	evm := &reentrantAccessibleState{countingAccessibleState: &countingAccessibleState{state: state}}
+	run := func(btime int64, input []byte, value *big.Int) {
+		t.Helper()
+		state.SubBalance(committer, value)
+		state.AddBalance(RandomPartyAddress, value)
+		evm.blockTime = big.NewInt(btime)
+		_, _, err := RandomPartyPrecompile.Run(evm, committer, RandomPartyAddress, input, 1_000_000, value, false)
+		assert.NilError(t, err)
+	}
+
+	run(10, StartSignature, common.Big0)
+	state.AddBalance(committer, big.NewInt(1000))
+	run(11, PackCommit(result), big.NewInt(1000))
+	run(14, PackReveal(common.Big0, preimage), common.Big0)
+
+	// The callback starts the next Random Party from within compute
+	evm.input = StartSignature
+	run(16, ComputeSignature, common.Big0)
+	assert.NilError(t, evm.err)
+	assert.Equal(t, GetResult(state, common.Big0), result)
+	commitDeadline, revealDeadline, ok := getDeadlines(state)
+	assert.Assert(t, ok)
+	assert.Equal(t, commitDeadline.Int64(), int64(19))
+	assert.Equal(t, revealDeadline.Int64(), int64(22))
+	assert.Equal(t, getBig(state, partyRoundKey).Int64(), int64(1))
+	assert.Equal(t, state.GetBalance(committer).Int64(), int64(1000))
+}
+
+func TestRandomPartyParticipationParams(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		config RandomPartyConfig
+		params RandomPartyParticipationParams
+	}{
+		{
+			name:   "defaults",
+			config: RandomPartyConfig{CommitStake: common.Big0},
+			params: RandomPartyParticipationParams{CommitFee: common.Big0, MinReveals: common.Big1, MaxCommitsPerAddress: common.Big0},
+		},
+		{
+			name:   "configured",
+			config: RandomPartyConfig{CommitStake: big.NewInt(1000), MaxCommitsPerAddress: 2},
+			params: RandomPartyParticipationParams{CommitFee: big.NewInt(1000), MinReveals: common.Big1, MaxCommitsPerAddress: common.Big2},
+		},
+		{
+			name:   "block hash without reveals",
+			config: RandomPartyConfig{CommitStake: big.NewInt(500), NoRevealsBehavior: NoRevealsBlockHash},
+			params: RandomPartyParticipationParams{CommitFee: big.NewInt(500), MinReveals: common.Big0, MaxCommitsPerAddress: common.Big0},
+		},
+	} {
+		state := newCountingStateDB()
+		test.config.PhaseSeconds = big.NewInt(3)
+		test.config.Configure(state)
+		accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+		ret, remainingGas, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, ParticipationParamsSignature, ParticipationParamsGasCost, common.Big0, true)
+		assert.NilError(t, err, test.name)
+		assert.Equal(t, remainingGas, uint64(0), test.name)
+		params, err := UnpackParticipationParams(ret)
+		assert.NilError(t, err, test.name)
+		assert.Assert(t, params.CommitFee.Cmp(test.params.CommitFee) == 0, test.name)
+		assert.Assert(t, params.MinReveals.Cmp(test.params.MinReveals) == 0, test.name)
+		assert.Assert(t, params.MaxCommitsPerAddress.Cmp(test.params.MaxCommitsPerAddress) == 0, test.name)
+	}
+
+	state := newCountingStateDB()
+	accessibleState := &countingAccessibleState{state: state, blockTime: common.Big0}
+	_, _, err := RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, ParticipationParamsSignature, ParticipationParamsGasCost-1, common.Big0, true)
+	assert.Assert(t, errors.Is(err, vmerrs.ErrOutOfGas), err)
+	_, _, err = RandomPartyPrecompile.Run(accessibleState, common.Address{0x1}, RandomPartyAddress, append(ParticipationParamsSignature, 0x1), ParticipationParamsGasCost, common.Big0, true)
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "invalid input length for participationParams"), err)
+	_, err = UnpackParticipationParams(make([]byte, common.HashLength))
+	assert.Assert(t, err != nil)
+}
+
+// expectedBlockHashResult returns the result of a round without reveals
+// computed at [blockNumber] (if [NoRevealsBlockHash] is set).
+func expectedBlockHashResult(blockNumber uint64) []byte {
+	return crypto.Keccak256((&countingAccessibleState{}).BlockHash(blockNumber - 1).Bytes())
+}
+
+var (
+	// Gas charged for the logs emitted by compute
+	resultComputedLogGasCost    = LogGasCost(2, common.HashLength)
+	rewardPerRevealerLogGasCost = LogGasCost(2, common.HashLength)
+)
+
+// newRandomPartyState returns a state with 3 second phases and a commit
+// stake of 1000.
+func newRandomPartyState() *countingStateDB {
+	state := newCountingStateDB()
+	SetPhaseSeconds(state, big.NewInt(3))
+	SetCommitStake(state, big.NewInt(1000))
+	return state
+}
+
+// randomPartyTest is a single call to the Random Party precompile. Calls are
+// executed in order against the same state.
+type randomPartyTest struct {
+	name   string
+	caller common.Address // defaults to the caller passed to runRandomPartyTests
+	btime  *big.Int
+
+	input       func() []byte
+	suppliedGas uint64
+	value       *big.Int
+	readOnly    bool
+
+	expectedRes []byte
+	expectedErr string
+
+	assertState func(t *testing.T, state *countingStateDB)
+}
+
+// runRandomPartyTests executes [tests] in order against [s]. Like the EVM,
+// any attached value is transferred to the precompile before it is run and
+// all state changes are reverted if the call returns an error (before
+// [assertState] is checked).
+func runRandomPartyTests(t *testing.T, s *countingStateDB, caller common.Address, tests []randomPartyTest) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from := caller
+			if test.caller != (common.Address{}) {
+				from = test.caller
+			}
+			snapshot := copyState(s)
+			if test.value != nil {
+				s.SubBalance(from, test.value)
+				s.AddBalance(RandomPartyAddress, test.value)
+			}
+			accessibleState := &countingAccessibleState{state: s, blockTime: test.btime, blockNumber: test.btime}
+			ret, remainingGas, err := RandomPartyPrecompile.Run(accessibleState, from, RandomPartyAddress, test.input(), test.suppliedGas, test.value, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				*s = *snapshot
+				assert.Assert(t, err != nil && strings.Contains(err.Error(), test.expectedErr), "expected error (%v) to contain substring (%s)", err, test.expectedErr)
+			} else {
+				assert.NilError(t, err)
+				assert.Equal(t, remainingGas, uint64(0))
+				assert.Assert(t, bytes.Equal(ret, test.expectedRes), "expected %x, got %x", test.expectedRes, ret)
+			}
+
+			if test.assertState != nil {
+				test.assertState(t, s)
+			}
+		})
+	}
+}
+
+func TestRandomParty(t *testing.T) {
+	anyAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	s := newRandomPartyState()
+	SetNoRevealsBehavior(s, NoRevealsBlockHash)
+	s.AddBalance(anyAddr, big.NewInt(100000))
+
+	runRandomPartyTests(t, s, anyAddr, []randomPartyTest{
+		{
+			name:  "next",
+			btime: common.Big0,
+			input: func() []byte {
+				return NextSignature
+			},
+			suppliedGas: NextCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		{
+			name:  "start party",
+			btime: big.NewInt(10),
+			input: func() []byte {
+				return StartSignature
+			},
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:  "start party again",
+			btime: big.NewInt(10),
+			input: func() []byte {
+				return StartSignature
+			},
+			suppliedGas: StartGasCost,
+			expectedErr: ErrRandomPartyUnderway.Error(),
+		},
+		{
+			name:  "commit",
+			btime: big.NewInt(10),
+			value: big.NewInt(1000),
+			input: func() []byte {
+				preimage := common.BytesToHash([]byte{0x1}).Bytes()
+				return PackCommit(crypto.Keccak256Hash(preimage))
+			},
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		{
+			name:  "commit insufficient",
+			btime: big.NewInt(10),
+			value: big.NewInt(999),
+			input: func() []byte {
+				preimage := common.BytesToHash([]byte{0x2}).Bytes()
+				return PackCommit(crypto.Keccak256Hash(preimage))
+			},
+			suppliedGas: CommitGasCost,
+			expectedErr: ErrInsufficientFunds.Error(),
+		},
+		{
+			name:  "commit 2",
+			btime: big.NewInt(10),
+			value: big.NewInt(1001),
+			input: func() []byte {
+				preimage := common.BytesToHash([]byte{0x2}).Bytes()
+				return PackCommit(crypto.Keccak256Hash(preimage))
+			},
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big1),
+		},
+		{
+			name:  "check reward before",
+			btime: big.NewInt(10),
+			input: func() []byte {
+				return RewardSignature
+			},
+			suppliedGas: RewardGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		{
+			name:  "reveal",
+			btime: big.NewInt(10),
+			input: func() []byte {
+				return PackReveal(common.Big0, crypto.Keccak256Hash([]byte{0x1}))
+			},
+			suppliedGas: RevealGasCost,
+			expectedErr: ErrTooEarly.Error(),
+		},
+		{
+			name:  "sponsor",
+			btime: big.NewInt(11),
+			value: big.NewInt(10),
+			input: func() []byte {
+				return SponsorSignature
+			},
+			suppliedGas: SponsorGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:  "check reward after",
+			btime: big.NewInt(10),
+			input: func() []byte {
+				return RewardSignature
+			},
+			suppliedGas: RewardGasCost,
+			expectedRes: HBigBytes(big.NewInt(10)),
+		},
+		{
+			name:  "commit later",
+			btime: big.NewInt(14),
+			input: func() []byte {
+				return PackCommit(crypto.Keccak256Hash([]byte{0x1}))
+			},
+			suppliedGas: CommitGasCost,
+			expectedErr: ErrTooLate.Error(),
+		},
+		{
+			name:  "reveal later",
+			btime: big.NewInt(14),
+			input: func() []byte {
+				return PackReveal(common.Big0, common.BytesToHash([]byte{0x1}))
+			},
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:  "duplicate reveal",
+			btime: big.NewInt(14),
+			input: func() []byte {
+				return PackReveal(common.Big0, common.BytesToHash([]byte{0x1}))
+			},
+			suppliedGas: RevealGasCost,
+			expectedErr: ErrDuplicateReveal.Error(),
+		},
+		{
+			name:  "compute early",
+			btime: big.NewInt(10),
+			input: func() []byte {
+				return ComputeSignature
+			},
+			suppliedGas: ComputeGasCost,
+			expectedErr: ErrTooEarly.Error(),
+		},
+		{
+			name:  "compute still early",
+			btime: big.NewInt(14),
+			input: func() []byte {
+				return ComputeSignature
+			},
+			suppliedGas: ComputeGasCost,
+			expectedErr: ErrTooEarly.Error(),
+		},
+		{
+			name:  "compute without gas for log",
+			btime: big.NewInt(20),
+			input: func() []byte {
+				return ComputeSignature
+			},
+			suppliedGas: ComputeGasCost + ComputeItemCost + ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		{
+			name:  "compute",
+			btime: big.NewInt(20),
+			input: func() []byte {
+				return ComputeSignature
+			},
+			suppliedGas: ComputeGasCost + ComputeItemCost + ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				logs := state.Logs()
+				assert.Equal(t, len(logs), 2)
+				assert.Equal(t, logs[0].Address, RandomPartyAddress)
+				assert.DeepEqual(t, logs[0].Topics, []common.Hash{ResultComputedTopic, common.BigToHash(common.Big0)})
+				assert.DeepEqual(t, logs[0].Data, crypto.Keccak256(common.BytesToHash([]byte{0x1}).Bytes()))
+				// The single revealer is owed the whole incentive pool
+				assert.DeepEqual(t, logs[1].Topics, []common.Hash{RewardPerRevealerTopic, common.BigToHash(common.Big0)})
+				assert.DeepEqual(t, logs[1].Data, HBigBytes(big.NewInt(10)))
+			},
+		},
+		{
+			name:  "result",
+			btime: big.NewInt(20),
+			input: func() []byte {
+				return PackResult(common.Big0)
+			},
+			suppliedGas: ResultCost,
+			expectedRes: crypto.Keccak256(common.BytesToHash([]byte{0x1}).Bytes()),
+		},
+		{
+			name:  "next",
+			btime: big.NewInt(20),
+			input: func() []byte {
+				return NextSignature
+			},
+			suppliedGas: NextCost,
+			expectedRes: common.BigToHash(big.NewInt(1)).Bytes(),
+		},
+		{
+			name:  "compute again",
+			btime: big.NewInt(20),
+			input: func() []byte {
+				return ComputeSignature
+			},
+			suppliedGas: ComputeGasCost + ComputeItemCost,
+			expectedErr: ErrNoRandomPartyStarted.Error(),
+		},
+		{
+			name:  "check reward before next party",
+			btime: big.NewInt(20),
+			input: func() []byte {
+				return RewardSignature
+			},
+			suppliedGas: RewardGasCost,
+			expectedErr: ErrNoRandomPartyStarted.Error(),
+		},
+		{
+			name:  "start second party",
+			btime: big.NewInt(20),
+			input: func() []byte {
+				return StartSignature
+			},
+			suppliedGas: StartGasCost + DeleteGasCost*3 + ComputeRewardCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:  "commit second party",
+			btime: big.NewInt(20),
+			value: big.NewInt(1001),
+			input: func() []byte {
+				preimage := common.BytesToHash([]byte{0x1}).Bytes()
+				return PackCommit(crypto.Keccak256Hash(preimage))
+			},
+			suppliedGas: CommitGasCost,
+			expectedRes: common.BigToHash(common.Big0).Bytes(),
+		},
+		{
+			name:  "check reward later",
+			btime: big.NewInt(21),
+			input: func() []byte {
+				return RewardSignature
+			},
+			suppliedGas: RewardGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		{
+			name:  "reveal old key",
+			btime: big.NewInt(24),
+			input: func() []byte {
+				return PackReveal(big.NewInt(1), common.BytesToHash([]byte{0x2}))
+			},
+			suppliedGas: RevealGasCost,
+			expectedErr: "no hash with index 1",
+		},
+		{
+			name:  "start third party",
+			btime: big.NewInt(30),
+			input: func() []byte {
+				return StartSignature
+			},
+			suppliedGas: StartGasCost + DeleteGasCost,
+			expectedErr: ErrRandomPartyUnderway.Error(),
+		},
+		{
+			name:  "compute old party",
+			btime: big.NewInt(40),
+			input: func() []byte {
+				return ComputeSignature
+			},
+			suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:  "next after reset",
+			btime: big.NewInt(100),
+			input: func() []byte {
+				return NextSignature
+			},
+			suppliedGas: NextCost,
+			expectedRes: HBigBytes(big.NewInt(2)),
+		},
+	})
+}
+
+func TestRandomPartyForfeitDestination(t *testing.T) {
+	revealer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	forfeiter := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	treasury := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+	preimage := common.BytesToHash([]byte{0x1})
+
+	for _, test := range []struct {
+		name           string
+		destination    ForfeitDestination
+		recipient      common.Address
+		startGas       uint64
+		expectedReward *big.Int
+		assertState    func(t *testing.T, state *countingStateDB)
+	}{
+		{
+			name:           "revealers",
+			destination:    ForfeitToRevealers,
+			startGas:       StartGasCost + DeleteGasCost*3 + ComputeRewardCost,
+			expectedReward: common.Big0,
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(revealer).Int64(), int64(100000+1000))
+				assert.Equal(t, state.GetBalance(forfeiter).Int64(), int64(100000-1000))
+			},
+		},
+		{
+			name:           "pool",
+			destination:    ForfeitToPool,
+			startGas:       StartGasCost + DeleteGasCost*3,
+			expectedReward: big.NewInt(1000),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(revealer).Int64(), int64(100000))
+			},
+		},
+		{
+			name:           "burn",
+			destination:    ForfeitToBurn,
+			startGas:       StartGasCost + DeleteGasCost*3,
+			expectedReward: common.Big0,
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(revealer).Int64(), int64(100000))
+				assert.Equal(t, state.GetBalance(constants.BlackholeAddr).Int64(), int64(1000))
+			},
+		},
+		{
+			name:           "recipient",
+			destination:    ForfeitToRecipient,
+			recipient:      treasury,
+			startGas:       StartGasCost + DeleteGasCost*3 + LogGasCost(3, common.HashLength),
+			expectedReward: common.Big0,
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(revealer).Int64(), int64(100000))
+				assert.Equal(t, state.GetBalance(treasury).Int64(), int64(1000))
+
+				logs := state.Logs()
+				log := logs[len(logs)-1]
+				assert.DeepEqual(t, log.Topics, []common.Hash{StakesForfeitedTopic, common.BigToHash(common.Big0), treasury.Hash()})
+				assert.DeepEqual(t, log.Data, HBigBytes(big.NewInt(1000)))
+			},
+		},
+		{
+			// Forfeited stakes are never sent to an address that cannot
+			// spend them
+			name:           "recipient unset",
+			destination:    ForfeitToRecipient,
+			startGas:       StartGasCost + DeleteGasCost*3,
+			expectedReward: big.NewInt(1000),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(revealer).Int64(), int64(100000))
+				assert.Equal(t, state.GetBalance(common.Address{}).Sign(), 0)
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := newRandomPartyState()
+			SetForfeitDestination(s, test.destination)
+			SetForfeitRecipient(s, test.recipient)
+			s.AddBalance(revealer, big.NewInt(100000))
+			s.AddBalance(forfeiter, big.NewInt(100000))
+
+			runRandomPartyTests(t, s, revealer, []randomPartyTest{
+				{
+					name:        "start",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: StartGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "commit revealer",
+					btime:       big.NewInt(10),
+					value:       big.NewInt(1000),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big0),
+				},
+				{
+					name:        "commit forfeiter",
+					caller:      forfeiter,
+					btime:       big.NewInt(10),
+					value:       big.NewInt(1000),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash([]byte{0x2})) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big1),
+				},
+				{
+					name:        "reveal",
+					btime:       big.NewInt(14),
+					input:       func() []byte { return PackReveal(common.Big0, preimage) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "compute",
+					btime:       big.NewInt(20),
+					input:       func() []byte { return ComputeSignature },
+					suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "start next",
+					btime:       big.NewInt(20),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: test.startGas,
+					expectedRes: []byte{},
+					assertState: test.assertState,
+				},
+				{
+					name:        "reward",
+					btime:       big.NewInt(21),
+					input:       func() []byte { return RewardSignature },
+					suppliedGas: RewardGasCost,
+					expectedRes: HBigBytes(test.expectedReward),
+				},
+			})
+		})
+	}
+}
+
+func TestRandomPartyMigrateV0ToV1(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	forfeiter := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	preimage := common.BytesToHash([]byte{0x1})
+
+	// Keys of the version 0 storage layout
+	singletonKey := func(key byte) common.Hash {
+		return common.BytesToHash([]byte{key})
+	}
+	legacyKey := func(pfx byte, idx int64) common.Hash {
+		return common.BytesToHash(append([]byte{pfx, '/'}, big.NewInt(idx).Bytes()...))
+	}
+	setLegacyCommits := func(s *countingStateDB) {
+		s.SetState(RandomPartyAddress, singletonKey(0x3), common.BigToHash(big.NewInt(2)))
+		s.SetState(RandomPartyAddress, legacyKey(0x3, 0), crypto.Keccak256Hash(preimage.Bytes()))
+		s.SetState(RandomPartyAddress, legacyKey(0x8, 0), committer.Hash())
+		s.SetState(RandomPartyAddress, legacyKey(0x3, 1), crypto.Keccak256Hash([]byte{0x2}))
+		s.SetState(RandomPartyAddress, legacyKey(0x8, 1), forfeiter.Hash())
+	}
+	assertLegacyCleared := func(t *testing.T, s *countingStateDB) {
+		for _, key := range []common.Hash{
+			singletonKey(0x3), singletonKey(0x4),
+			legacyKey(0x3, 0), legacyKey(0x3, 1), legacyKey(0x8, 0), legacyKey(0x8, 1),
+			legacyKey(0x4, 0), legacyKey(0x9, 0),
+		} {
+			assert.Equal(t, s.GetState(RandomPartyAddress, key), common.Hash{}, "expected legacy key %s to be cleared", key)
+		}
+	}
+
+	t.Run("party underway", func(t *testing.T) {
+		s := newRandomPartyState()
+		s.SetState(RandomPartyAddress, singletonKey(0x1), common.BigToHash(big.NewInt(13)))
+		s.SetState(RandomPartyAddress, singletonKey(0x2), common.BigToHash(big.NewInt(16)))
+		setLegacyCommits(s)
+		s.AddBalance(RandomPartyAddress, big.NewInt(2000))
+
+		assert.NilError(t, Migrate(s, 0, RandomPartySchemaVersion))
+		assertLegacyCleared(t, s)
+		assert.Assert(t, errors.Is(Migrate(s, 0, RandomPartySchemaVersion), ErrInvalidMigration))
+
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			{
+				name:        "reveal migrated commit",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					assert.Equal(t, state.GetBalance(committer).Int64(), int64(1000))
+				},
+			},
+			{
+				name:        "duplicate reveal",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedErr: ErrDuplicateReveal.Error(),
+			},
+			{
+				name:        "compute",
+				btime:       big.NewInt(20),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "result",
+				btime:       big.NewInt(20),
+				input:       func() []byte { return PackResult(common.Big0) },
+				suppliedGas: ResultCost,
+				expectedRes: crypto.Keccak256(preimage.Bytes()),
+			},
+		})
+	})
+
+	t.Run("party computed", func(t *testing.T) {
+		s := newRandomPartyState()
+		setLegacyCommits(s)
+		s.SetState(RandomPartyAddress, legacyKey(0x3, 0), common.Hash{})
+		s.SetState(RandomPartyAddress, legacyKey(0x8, 0), common.Hash{})
+		s.SetState(RandomPartyAddress, singletonKey(0x4), common.BigToHash(common.Big1))
+		s.SetState(RandomPartyAddress, legacyKey(0x4, 0), preimage)
+		s.SetState(RandomPartyAddress, legacyKey(0x9, 0), committer.Hash())
+		s.SetState(RandomPartyAddress, singletonKey(0x5), common.BigToHash(common.Big1))
+		s.SetState(RandomPartyAddress, legacyKey(0x5, 0), crypto.Keccak256Hash(preimage.Bytes()))
+		s.AddBalance(RandomPartyAddress, big.NewInt(1000))
+
+		assert.NilError(t, Migrate(s, 0, RandomPartySchemaVersion))
+		assertLegacyCleared(t, s)
+
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			{
+				name:        "result",
+				btime:       big.NewInt(20),
+				input:       func() []byte { return PackResult(common.Big0) },
+				suppliedGas: ResultCost,
+				expectedRes: crypto.Keccak256(preimage.Bytes()),
+			},
+			{
+				name:        "start cleans up migrated party",
+				btime:       big.NewInt(20),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost + DeleteGasCost*3 + ComputeRewardCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					assert.Equal(t, state.GetBalance(committer).Int64(), int64(1000))
+				},
+			},
+		})
+	})
+}
+
+func TestRandomPartyStakeFromBalance(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	commitInput := func() []byte { return PackCommit(crypto.Keccak256Hash([]byte{0x1})) }
+	start := randomPartyTest{
+		name:        "start",
+		btime:       big.NewInt(10),
+		input:       func() []byte { return StartSignature },
+		suppliedGas: StartGasCost,
+		expectedRes: []byte{},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		s := newRandomPartyState()
+		s.AddBalance(committer, big.NewInt(100000))
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			start,
+			{
+				name:        "commit without value",
+				btime:       big.NewInt(10),
+				input:       commitInput,
+				suppliedGas: CommitGasCost,
+				expectedErr: ErrInsufficientFunds.Error(),
+			},
+		})
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetStakeFromBalance(s, true)
+		s.AddBalance(committer, big.NewInt(2000))
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			start,
+			{
+				name:        "commit without value",
+				btime:       big.NewInt(10),
+				input:       commitInput,
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big0),
+				assertState: func(t *testing.T, state *countingStateDB) {
+					assert.Equal(t, state.GetBalance(committer).Int64(), int64(1000))
+					assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(1000))
+				},
+			},
+			{
+				name:        "commit with partial value",
+				btime:       big.NewInt(10),
+				value:       big.NewInt(400),
+				input:       commitInput,
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big1),
+				assertState: func(t *testing.T, state *countingStateDB) {
+					assert.Equal(t, state.GetBalance(committer).Sign(), 0)
+					assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(2000))
+				},
+			},
+			{
+				name:        "commit with insufficient balance",
+				btime:       big.NewInt(10),
+				input:       commitInput,
+				suppliedGas: CommitGasCost,
+				expectedErr: ErrInsufficientFunds.Error(),
+			},
+		})
+	})
+}
+
+func TestRandomPartyResult(t *testing.T) {
+	anyAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+
+	t.Run("views", func(t *testing.T) {
+		preimage := common.Hash{0x1}
+		s := newRandomPartyState()
+		s.AddBalance(anyAddr, big.NewInt(1000))
+
+		result := func(name string, btime int64, round *big.Int, expectedRes []byte, expectedErr string) randomPartyTest {
+			return randomPartyTest{
+				name:        name,
+				btime:       big.NewInt(btime),
+				input:       func() []byte { return PackResult(round) },
+				suppliedGas: ResultCost,
+				expectedRes: expectedRes,
+				expectedErr: expectedErr,
+			}
+		}
+		latestResult := func(name string, btime int64, expectedRes []byte, expectedErr string) randomPartyTest {
+			return randomPartyTest{
+				name:        name,
+				btime:       big.NewInt(btime),
+				input:       func() []byte { return LatestResultSignature },
+				suppliedGas: LatestResultGasCost,
+				expectedRes: expectedRes,
+				expectedErr: expectedErr,
+			}
+		}
+		runRandomPartyTests(t, s, anyAddr, []randomPartyTest{
+			result("result before any party", 5, common.Big0, nil, ErrRoundNotComputed.Error()),
+			latestResult("latest result before any compute", 5, nil, ErrRoundNotComputed.Error()),
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "commit",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "reveal",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			},
+			result("result before compute", 15, common.Big0, nil, ErrRoundNotComputed.Error()),
+			latestResult("latest result before compute", 15, nil, ErrRoundNotComputed.Error()),
+			{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+			latestResult("latest result", 16, crypto.Keccak256(preimage.Bytes()), ""),
+			result("result of completed round", 16, common.Big0, crypto.Keccak256(preimage.Bytes()), ""),
+			{
+				name:        "start next",
+				btime:       big.NewInt(20),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost + DeleteGasCost*2,
+				expectedRes: []byte{},
+			},
+			result("result of pending round", 21, common.Big1, nil, ErrRoundNotComputed.Error()),
+			result("result of far future round", 21, math.MaxBig256, nil, fmt.Sprintf("%s: round %d is not below next round 1", ErrRoundNotComputed, math.MaxBig256)),
+			latestResult("latest result during next round", 21, crypto.Keccak256(preimage.Bytes()), ""),
+		})
+	})
+
+	t.Run("result consumed", func(t *testing.T) {
+		resultConsumedLogGasCost := LogGasCost(1, 2*common.HashLength)
+		setup := []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+		}
+		expectedResult := expectedBlockHashResult(16)
+
+		t.Run("disabled", func(t *testing.T) {
+			s := newRandomPartyState()
+			SetNoRevealsBehavior(s, NoRevealsBlockHash)
+			runRandomPartyTests(t, s, anyAddr, append(setup, randomPartyTest{
+				name:        "result",
+				btime:       big.NewInt(17),
+				input:       func() []byte { return PackResult(common.Big0) },
+				suppliedGas: ResultCost,
+				expectedRes: expectedResult,
+				assertState: func(t *testing.T, state *countingStateDB) {
+					// Only the ResultComputed log is emitted
+					assert.Equal(t, len(state.Logs()), 1)
+				},
+			}))
+		})
+
+		t.Run("enabled", func(t *testing.T) {
+			s := newRandomPartyState()
+			SetNoRevealsBehavior(s, NoRevealsBlockHash)
+			SetEmitResultConsumed(s, true)
+			runRandomPartyTests(t, s, anyAddr, append(setup, []randomPartyTest{
+				{
+					name:        "result without log gas",
+					btime:       big.NewInt(17),
+					input:       func() []byte { return PackResult(common.Big0) },
+					suppliedGas: ResultCost,
+					expectedErr: vmerrs.ErrOutOfGas.Error(),
+				},
+				{
+					name:        "result read only",
+					btime:       big.NewInt(17),
+					input:       func() []byte { return PackResult(common.Big0) },
+					suppliedGas: ResultCost,
+					readOnly:    true,
+					expectedRes: expectedResult,
+					assertState: func(t *testing.T, state *countingStateDB) {
+						assert.Equal(t, len(state.Logs()), 1)
+					},
+				},
+				{
+					name:        "result",
+					btime:       big.NewInt(17),
+					input:       func() []byte { return PackResult(common.Big0) },
+					suppliedGas: ResultCost + resultConsumedLogGasCost,
+					expectedRes: expectedResult,
+					assertState: func(t *testing.T, state *countingStateDB) {
+						logs := state.Logs()
+						assert.Equal(t, len(logs), 2)
+						assert.Equal(t, logs[1].Address, RandomPartyAddress)
+						assert.DeepEqual(t, logs[1].Topics, []common.Hash{ResultConsumedTopic})
+						assert.DeepEqual(t, logs[1].Data, append(HBigBytes(common.Big0), anyAddr.Hash().Bytes()...))
+					},
+				},
+			}...))
+		})
+	})
+
+	t.Run("result computed round topic", func(t *testing.T) {
+		s := newRandomPartyState()
+		s.AddBalance(anyAddr, big.NewInt(10000))
+
+		// playRound starts a round at [btime] with a single commitment and checks
+		// that the ResultComputed log is indexed by the round its result is
+		// stored under
+		playRound := func(round int64, btime int64, startGas uint64) []randomPartyTest {
+			preimage := common.Hash{byte(round + 1)}
+			return []randomPartyTest{
+				{
+					name:        fmt.Sprintf("start %d", round),
+					btime:       big.NewInt(btime),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: startGas,
+					expectedRes: []byte{},
+				},
+				{
+					name:        fmt.Sprintf("commit %d", round),
+					btime:       big.NewInt(btime + 1),
+					value:       big.NewInt(1000),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big0),
+				},
+				{
+					name:        fmt.Sprintf("reveal %d", round),
+					btime:       big.NewInt(btime + 4),
+					input:       func() []byte { return PackReveal(common.Big0, preimage) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        fmt.Sprintf("compute %d", round),
+					btime:       big.NewInt(btime + 6),
+					input:       func() []byte { return ComputeSignature },
+					suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+					expectedRes: []byte{},
+					assertState: func(t *testing.T, state *countingStateDB) {
+						logs := state.Logs()
+						log := logs[len(logs)-1]
+						assert.DeepEqual(t, log.Topics, []common.Hash{ResultComputedTopic, common.BigToHash(big.NewInt(round))})
+
+						accessibleState := &countingAccessibleState{blockTime: big.NewInt(btime + 6), state: state}
+						ret, _, err := RandomPartyPrecompile.Run(accessibleState, anyAddr, RandomPartyAddress, PackResult(big.NewInt(round)), ResultCost, common.Big0, true)
+						assert.NilError(t, err)
+						assert.DeepEqual(t, log.Data, ret)
+					},
+				},
+			}
+		}
+		tests := playRound(0, 10, StartGasCost)
+		tests = append(tests, playRound(1, 20, StartGasCost+DeleteGasCost*2)...)
+		runRandomPartyTests(t, s, anyAddr, tests)
+	})
+}
+
+func TestRandomPartyCommitLimits(t *testing.T) {
+	commit := func(name string, caller common.Address, btime int64, preimage byte, idx int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			caller:      caller,
+			btime:       big.NewInt(btime),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(common.Hash{preimage}.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(idx)),
+		}
+	}
+	canCommit := func(name string, caller common.Address, btime int64, expected bool) randomPartyTest {
+		res := common.Big0
+		if expected {
+			res = common.Big1
+		}
+		return randomPartyTest{
+			name:        name,
+			caller:      caller,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return CanCommitSignature },
+			suppliedGas: CanCommitGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(res),
+		}
+	}
+
+	t.Run("max reveals", func(t *testing.T) {
+		committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		s := newRandomPartyState()
+		SetMaxReveals(s, 2)
+
+		preimages := []common.Hash{{0x1}, {0x2}, {0x3}}
+		tests := []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+		}
+		for i, preimage := range preimages {
+			tests = append(tests, commit(fmt.Sprintf("commit %d", i), committer, 11, preimage[0], int64(i)))
+		}
+		for i, preimage := range preimages[:2] {
+			i, preimage := i, preimage
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("reveal %d", i),
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(big.NewInt(int64(i)), preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			})
+		}
+		tests = append(tests, randomPartyTest{
+			name:        "reveal beyond cap",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(big.NewInt(2), preimages[2]) },
+			suppliedGas: RevealGasCost,
+			expectedErr: ErrRevealCapReached.Error(),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				// The stake of the rejected reveal remains locked
+				assert.Equal(t, state.GetBalance(committer).Int64(), int64(2000))
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(1000))
+			},
+		})
+
+		s.AddBalance(committer, big.NewInt(3000))
+		runRandomPartyTests(t, s, committer, tests)
+	})
+
+	t.Run("max commits per address", func(t *testing.T) {
+		whale := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		other := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+		s := newRandomPartyState()
+		SetMaxCommitsPerAddress(s, 2)
+		s.AddBalance(whale, big.NewInt(100000))
+		s.AddBalance(other, big.NewInt(100000))
+
+		overLimit := commit("commit over limit", whale, 11, 0x3, 0)
+		overLimit.expectedRes = nil
+		overLimit.expectedErr = ErrCommitLimitReached.Error()
+		runRandomPartyTests(t, s, whale, []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			commit("commit first", whale, 11, 0x1, 0),
+			commit("commit at limit", whale, 11, 0x2, 1),
+			overLimit,
+			commit("commit from other address", other, 11, 0x4, 2),
+			{
+				name:        "reveal",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, common.Hash{0x1}) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "start next",
+				btime:       big.NewInt(20),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost + DeleteGasCost*4 + ComputeRewardCost,
+				expectedRes: []byte{},
+			},
+			commit("commit first in next round", whale, 21, 0x1, 0),
+			commit("commit at limit in next round", whale, 21, 0x2, 1),
+			func() randomPartyTest {
+				test := overLimit
+				test.name = "commit over limit in next round"
+				test.btime = big.NewInt(21)
+				return test
+			}(),
+		})
+	})
+
+	t.Run("max total stake", func(t *testing.T) {
+		committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		s := newRandomPartyState()
+		SetMaxTotalStake(s, big.NewInt(3000))
+		s.AddBalance(committer, big.NewInt(100000))
+
+		overCap := commit("commit over cap", committer, 11, 0x4, 0)
+		overCap.expectedRes = nil
+		overCap.expectedErr = ErrStakeCapReached.Error()
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			commit("commit first", committer, 11, 0x1, 0),
+			commit("commit second", committer, 11, 0x2, 1),
+			canCommit("can commit below cap", committer, 11, true),
+			commit("commit at cap", committer, 11, 0x3, 2),
+			canCommit("cannot commit at cap", committer, 11, false),
+			overCap,
+			{
+				name:        "commit fee collected",
+				btime:       big.NewInt(11),
+				input:       func() []byte { return CommitFeeCollectedSignature },
+				suppliedGas: CommitFeeCollectedGasCost,
+				readOnly:    true,
+				expectedRes: HBigBytes(big.NewInt(3000)),
+			},
+		})
+	})
+
+	t.Run("can commit", func(t *testing.T) {
+		committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		other := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+		s := newRandomPartyState()
+		SetMaxCommitsPerAddress(s, 1)
+		SetMaxStoredState(s, 3)
+		s.AddBalance(committer, big.NewInt(1000))
+		s.AddBalance(other, big.NewInt(1000))
+
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			canCommit("not started", committer, 5, false),
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			canCommit("commit phase", committer, 10, true),
+			commit("commit", committer, 11, 0x1, 0),
+			// [MaxCommitsPerAddress] is reached by the committer only
+			canCommit("commit limit reached", committer, 11, false),
+			canCommit("other under commit limit", other, 11, true),
+			commit("other commit", other, 11, 0x2, 1),
+			// The next commitment would exceed [MaxStoredState]
+			canCommit("state limit reached", other, 11, false),
+			canCommit("reveal phase", committer, 13, false),
+			{
+				name:        "invalid input",
+				btime:       big.NewInt(13),
+				input:       func() []byte { return append(CanCommitSignature, 0x1) },
+				suppliedGas: CanCommitGasCost,
+				expectedErr: "invalid input length for canCommit",
+			},
+			{
+				name:        "insufficient gas",
+				btime:       big.NewInt(13),
+				input:       func() []byte { return CanCommitSignature },
+				suppliedGas: CanCommitGasCost - 1,
+				expectedErr: vmerrs.ErrOutOfGas.Error(),
+			},
+		})
+	})
+}
+
+func TestRandomPartyCommitHashAlgo(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	preimage := common.Hash{0x1}
+	keccakCommit := crypto.Keccak256Hash(preimage.Bytes())
+	sha256Commit := common.Hash(sha256.Sum256(preimage.Bytes()))
+
+	for name, test := range map[string]struct {
+		algo        CommitHashAlgo
+		commit      common.Hash
+		expectedErr string
+	}{
+		"keccak256": {
+			algo:   CommitHashKeccak256,
+			commit: keccakCommit,
+		},
+		"keccak256 mismatch": {
+			algo:        CommitHashKeccak256,
+			commit:      sha256Commit,
+			expectedErr: "expected",
+		},
+		"sha256": {
+			algo:   CommitHashSHA256,
+			commit: sha256Commit,
+		},
+		"sha256 mismatch": {
+			algo:        CommitHashSHA256,
+			commit:      keccakCommit,
+			expectedErr: "expected",
+		},
+		"unknown": {
+			algo:        CommitHashSHA256 + 1,
+			commit:      keccakCommit,
+			expectedErr: ErrUnknownCommitHash.Error(),
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			s := newRandomPartyState()
+			SetCommitHashAlgo(s, test.algo)
+			s.AddBalance(committer, big.NewInt(1000))
+
+			reveal := randomPartyTest{
+				name:        "reveal",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedErr: test.expectedErr,
+			}
+			if len(test.expectedErr) == 0 {
+				reveal.expectedRes = []byte{}
+			}
+			runRandomPartyTests(t, s, committer, []randomPartyTest{
+				{
+					name:        "start",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: StartGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "commit",
+					btime:       big.NewInt(11),
+					value:       big.NewInt(1000),
+					input:       func() []byte { return PackCommit(test.commit) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big0),
+				},
+				reveal,
+			})
+		})
+	}
+}
+
+func TestRandomPartyCommitFee(t *testing.T) {
+	adminAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	noRoleAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	newAdminState := func() *countingStateDB {
+		s := newCountingStateDB()
+		(&RandomPartyConfig{
+			PhaseSeconds:    big.NewInt(3),
+			CommitStake:     big.NewInt(1000),
+			AllowListAdmins: []common.Address{adminAddr},
+		}).Configure(s)
+		return s
+	}
+
+	commitFee := func(name string, expected *big.Int) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(10),
+			input:       func() []byte { return CommitFeeSignature },
+			suppliedGas: CommitFeeGasCost,
+			expectedRes: HBigBytes(expected),
+		}
+	}
+	commitFeeOf := func(name string, round int64, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(40),
+			input:       func() []byte { return PackCommitFeeOf(big.NewInt(round)) },
+			suppliedGas: CommitFeeOfGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+	setCommitFee := func(name string, btime int64, fee int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackSetCommitFee(big.NewInt(fee)) },
+			suppliedGas: SetCommitFeeGasCost,
+			expectedRes: []byte{},
+		}
+	}
+	start := func(btime int64, suppliedGas uint64) randomPartyTest {
+		return randomPartyTest{
+			name:        "start",
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: suppliedGas,
+			expectedRes: []byte{},
+		}
+	}
+
+	for _, test := range []struct {
+		name     string
+		state    func() *countingStateDB
+		expected *big.Int
+	}{
+		{
+			name:     "default",
+			state:    newCountingStateDB,
+			expected: common.Big0,
+		},
+		{
+			name:     "configured",
+			state:    newRandomPartyState,
+			expected: big.NewInt(1000),
+		},
+		{
+			name: "overridden",
+			state: func() *countingStateDB {
+				s := newRandomPartyState()
+				SetCommitStake(s, big.NewInt(2500))
+				return s
+			},
+			expected: big.NewInt(2500),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			runRandomPartyTests(t, test.state(), adminAddr, []randomPartyTest{
+				commitFee("commit fee", test.expected),
+				{
+					name:        "commit fee with input",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return append(CommitFeeSignature[:4:4], 0x1) },
+					suppliedGas: CommitFeeGasCost,
+					expectedErr: "invalid input length for commitFee",
+				},
+			})
+		})
+	}
+
+	t.Run("set commit fee", func(t *testing.T) {
+		s := newAdminState()
+		assert.Equal(t, GetRandomPartyStatus(s, adminAddr), AllowListAdmin)
+		assert.Equal(t, GetRandomPartyStatus(s, noRoleAddr), AllowListNoRole)
+		s.AddBalance(adminAddr, big.NewInt(2000))
+
+		runRandomPartyTests(t, s, adminAddr, []randomPartyTest{
+			{
+				name:        "set commit fee from non-admin",
+				caller:      noRoleAddr,
+				btime:       big.NewInt(5),
+				input:       func() []byte { return PackSetCommitFee(big.NewInt(1)) },
+				suppliedGas: SetCommitFeeGasCost,
+				expectedErr: ErrCannotSetCommitFee.Error(),
+			},
+			{
+				name:        "set commit fee read only",
+				btime:       big.NewInt(5),
+				input:       func() []byte { return PackSetCommitFee(big.NewInt(1)) },
+				suppliedGas: SetCommitFeeGasCost,
+				readOnly:    true,
+				expectedErr: vmerrs.ErrWriteProtection.Error(),
+			},
+			{
+				name:        "set commit fee insufficient gas",
+				btime:       big.NewInt(5),
+				input:       func() []byte { return PackSetCommitFee(big.NewInt(1)) },
+				suppliedGas: SetCommitFeeGasCost - 1,
+				expectedErr: vmerrs.ErrOutOfGas.Error(),
+			},
+			commitFee("commit fee unchanged", big.NewInt(1000)),
+			setCommitFee("set commit fee from admin", 5, 500),
+			commitFee("commit fee updated", big.NewInt(500)),
+			start(10, StartGasCost),
+			{
+				name:        "commit with old fee",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash([]byte{0x1})) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "commit with new fee",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(500),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash([]byte{0x2})) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big1),
+			},
+			{
+				name:        "set commit fee with commitments",
+				btime:       big.NewInt(11),
+				input:       func() []byte { return PackSetCommitFee(big.NewInt(100)) },
+				suppliedGas: SetCommitFeeGasCost,
+				expectedErr: ErrRandomPartyUnderway.Error(),
+			},
+		})
+	})
+
+	t.Run("commit fee of", func(t *testing.T) {
+		s := newAdminState()
+		s.AddBalance(adminAddr, big.NewInt(1500))
+
+		// playRound starts a round at [btime], optionally updates the commit
+		// fee before the first commitment, and commits, reveals, and computes
+		// it
+		playRound := func(btime int64, startGas uint64, fee int64, stake int64, preimage common.Hash) []randomPartyTest {
+			tests := []randomPartyTest{start(btime, startGas)}
+			if fee != 0 {
+				tests = append(tests, setCommitFee("set commit fee", btime, fee))
+			}
+			return append(tests,
+				randomPartyTest{
+					name:        "commit",
+					btime:       big.NewInt(btime + 1),
+					value:       big.NewInt(stake),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big0),
+				},
+				randomPartyTest{
+					name:        "reveal",
+					btime:       big.NewInt(btime + 4),
+					input:       func() []byte { return PackReveal(common.Big0, preimage) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+				},
+				randomPartyTest{
+					name:        "compute",
+					btime:       big.NewInt(btime + 6),
+					input:       func() []byte { return ComputeSignature },
+					suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+					expectedRes: []byte{},
+				},
+			)
+		}
+
+		tests := []randomPartyTest{commitFeeOf("not started", 0, 0)}
+		tests = append(tests, playRound(10, StartGasCost, 0, 1000, common.Hash{0x1})...)
+		tests = append(tests, playRound(20, StartGasCost+DeleteGasCost*2, 500, 500, common.Hash{0x2})...)
+		tests = append(tests,
+			start(30, StartGasCost+DeleteGasCost*2),
+			commitFeeOf("started with previous fee", 2, 500),
+			setCommitFee("set commit fee", 30, 2000),
+			// Earlier rounds keep the fee their commitments were made with
+			commitFeeOf("first round", 0, 1000),
+			commitFeeOf("fee updated after start", 1, 500),
+			commitFeeOf("fee updated before first commitment", 2, 2000),
+			commitFeeOf("future round", 3, 0),
+			randomPartyTest{
+				name:        "invalid input",
+				btime:       big.NewInt(40),
+				input:       func() []byte { return CommitFeeOfSignature },
+				suppliedGas: CommitFeeOfGasCost,
+				readOnly:    true,
+				expectedErr: "invalid input length for commitFeeOf",
+			},
+		)
+		runRandomPartyTests(t, s, adminAddr, tests)
+	})
+}
+
+func TestRandomPartyCommitErrorPrecedence(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	s := newRandomPartyState()
+	s.AddBalance(committer, big.NewInt(1000))
+
+	// Each commit is invalid in several ways and fails with the error of the
+	// first check
+	commit := func(name string, btime int64, suppliedGas uint64, value int64, expectedErr error) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			value:       big.NewInt(value),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(common.Hash{0x1}.Bytes())) },
+			suppliedGas: suppliedGas,
+			expectedErr: expectedErr.Error(),
+		}
+	}
+	runRandomPartyTests(t, s, committer, []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		commit("out of gas before insufficient funds", 11, CommitGasCost-1, 999, vmerrs.ErrOutOfGas),
+		commit("out of gas before too late", 13, CommitGasCost-1, 1000, vmerrs.ErrOutOfGas),
+		commit("out of gas before too late and insufficient funds", 13, CommitGasCost-1, 999, vmerrs.ErrOutOfGas),
+		commit("too late before insufficient funds", 13, CommitGasCost, 999, ErrTooLate),
+		commit("insufficient funds", 11, CommitGasCost, 999, ErrInsufficientFunds),
+	})
+}
+
+func TestRandomPartyComputeByRevealersOnly(t *testing.T) {
+	revealer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	bystander := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+	preimage := common.Hash{0x1}
+	start := randomPartyTest{
+		name:        "start",
+		btime:       big.NewInt(10),
+		input:       func() []byte { return StartSignature },
+		suppliedGas: StartGasCost,
+		expectedRes: []byte{},
+	}
+	computeGas := ComputeGasCost + ComputeItemCost + resultComputedLogGasCost
+
+	t.Run("with reveals", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+		SetComputeByRevealersOnly(s, true)
+		s.AddBalance(revealer, big.NewInt(1000))
+		runRandomPartyTests(t, s, revealer, []randomPartyTest{
+			start,
+			{
+				name:        "commit",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "reveal",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "compute by non-revealer",
+				caller:      bystander,
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: computeGas,
+				expectedErr: ErrNotRevealer.Error(),
+			},
+			{
+				name:        "compute by revealer",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: computeGas,
+				expectedRes: []byte{},
+			},
+		})
+	})
+
+	t.Run("without reveals", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+		SetComputeByRevealersOnly(s, true)
+		runRandomPartyTests(t, s, bystander, []randomPartyTest{
+			start,
+			{
+				name:        "compute by anyone",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+		})
+	})
+}
+
+func TestRandomPartyGasCostOverrides(t *testing.T) {
+	anyAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	costs := RandomPartyGasCosts{
+		Next:    1_000,
+		Result:  2_000,
+		Reward:  3_000,
+		Sponsor: 4_000,
+	}
+	start := randomPartyTest{
+		name:        "start",
+		btime:       big.NewInt(10),
+		input:       func() []byte { return StartSignature },
+		suppliedGas: StartGasCost,
+		expectedRes: []byte{},
+	}
+	compute := randomPartyTest{
+		name:        "compute",
+		btime:       big.NewInt(16),
+		input:       func() []byte { return ComputeSignature },
+		suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+		expectedRes: []byte{},
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+		runRandomPartyTests(t, s, anyAddr, []randomPartyTest{
+			start,
+			{
+				name:        "sponsor",
+				btime:       big.NewInt(11),
+				value:       common.Big0,
+				input:       func() []byte { return SponsorSignature },
+				suppliedGas: SponsorGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "reward",
+				btime:       big.NewInt(11),
+				input:       func() []byte { return RewardSignature },
+				suppliedGas: RewardGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			compute,
+			{
+				name:        "result",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return PackResult(common.Big0) },
+				suppliedGas: ResultCost,
+				expectedRes: expectedBlockHashResult(16),
+			},
+			{
+				name:        "next",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return NextSignature },
+				suppliedGas: NextCost,
+				expectedRes: HBigBytes(common.Big1),
+			},
+		})
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+		SetGasCosts(s, costs)
+		runRandomPartyTests(t, s, anyAddr, []randomPartyTest{
+			start,
+			{
+				name:        "sponsor",
+				btime:       big.NewInt(11),
+				value:       common.Big0,
+				input:       func() []byte { return SponsorSignature },
+				suppliedGas: costs.Sponsor,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "reward",
+				btime:       big.NewInt(11),
+				input:       func() []byte { return RewardSignature },
+				suppliedGas: costs.Reward,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "reward with insufficient gas",
+				btime:       big.NewInt(11),
+				input:       func() []byte { return RewardSignature },
+				suppliedGas: costs.Reward - 1,
+				expectedErr: vmerrs.ErrOutOfGas.Error(),
+			},
+			compute,
+			{
+				name:        "result",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return PackResult(common.Big0) },
+				suppliedGas: costs.Result,
+				expectedRes: expectedBlockHashResult(16),
+			},
+			{
+				name:        "next",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return NextSignature },
+				suppliedGas: costs.Next,
+				expectedRes: HBigBytes(common.Big1),
+			},
+		})
+	})
+}
+
+func TestRandomPartyRevealBatch(t *testing.T) {
+	relayer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	committers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x2Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	preimages := []common.Hash{{0x1}, {0x2}}
+	indices := []*big.Int{common.Big0, common.Big1}
+
+	setup := func(t *testing.T) *countingStateDB {
+		s := newRandomPartyState()
+		tests := []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+		}
+		for i, committer := range committers {
+			preimage := preimages[i]
+			s.AddBalance(committer, big.NewInt(1000))
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("commit %d", i),
+				caller:      committer,
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(big.NewInt(int64(i))),
+			})
+		}
+		runRandomPartyTests(t, s, relayer, tests)
+		return s
+	}
+
+	t.Run("valid batch", func(t *testing.T) {
+		s := setup(t)
+		runRandomPartyTests(t, s, relayer, []randomPartyTest{
+			{
+				name:        "reveal batch without base gas",
+				btime:       big.NewInt(12),
+				input:       func() []byte { return PackRevealBatch(indices, preimages) },
+				suppliedGas: RevealBatchGasCost - 1,
+				expectedErr: vmerrs.ErrOutOfGas.Error(),
+			},
+			{
+				name:        "reveal batch too early",
+				btime:       big.NewInt(12),
+				input:       func() []byte { return PackRevealBatch(indices, preimages) },
+				suppliedGas: RevealBatchGasCost + 2*RevealGasCost,
+				expectedErr: ErrTooEarly.Error(),
+			},
+			{
+				name:        "reveal batch with insufficient gas",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackRevealBatch(indices, preimages) },
+				suppliedGas: RevealBatchGasCost + 2*RevealGasCost - 1,
+				expectedErr: vmerrs.ErrOutOfGas.Error(),
+			},
+			{
+				name:        "reveal batch",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackRevealBatch(indices, preimages) },
+				suppliedGas: RevealBatchGasCost + 2*RevealGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					// Stakes are returned to the committers (not the relayer)
+					for _, committer := range committers {
+						assert.Equal(t, state.GetBalance(committer).Int64(), int64(1000))
+					}
+					assert.Equal(t, state.GetBalance(relayer).Sign(), 0)
+				},
+			},
+			{
+				name:        "reveal batch again",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackRevealBatch(indices[:1], preimages[:1]) },
+				suppliedGas: RevealBatchGasCost + RevealGasCost,
+				expectedErr: ErrDuplicateReveal.Error(),
+			},
+		})
+	})
+
+	t.Run("batch with bad preimage", func(t *testing.T) {
+		s := setup(t)
+		runRandomPartyTests(t, s, relayer, []randomPartyTest{
+			{
+				name:        "reveal batch",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackRevealBatch(indices, []common.Hash{preimages[0], {0x3}}) },
+				suppliedGas: RevealBatchGasCost + 2*RevealGasCost,
+				expectedErr: "reveal 1 of batch failed",
+				assertState: func(t *testing.T, state *countingStateDB) {
+					// The valid reveal in the batch is reverted
+					for _, committer := range committers {
+						assert.Equal(t, state.GetBalance(committer).Sign(), 0)
+					}
+				},
+			},
+			{
+				name:        "reveal valid preimage",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimages[0]) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			},
+		})
+	})
+}
+
+func TestRandomPartyReveal(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	revealed, unrevealed := common.Hash{0x1}, common.Hash{0x2}
+	s := newRandomPartyState()
+	s.AddBalance(committer, big.NewInt(2000))
+
+	wasRevealed := func(name string, btime int64, preimage common.Hash, reveals uint64, expected bool) randomPartyTest {
+		res := common.Big0
+		if expected {
+			res = common.Big1
+		}
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackWasRevealed(preimage) },
+			suppliedGas: WasRevealedGasCost + reveals*WasRevealedItemCost,
+			expectedRes: HBigBytes(res),
+		}
+	}
+	revealTooLarge := func(name string, idx *big.Int) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(idx, revealed) },
+			suppliedGas: RevealGasCost,
+			expectedErr: ErrRevealIndexTooLarge.Error(),
+		}
+	}
+	runRandomPartyTests(t, s, committer, []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "commit revealed",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(revealed.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		{
+			name:        "commit unrevealed",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(unrevealed.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big1),
+		},
+		wasRevealed("before reveal", 12, revealed, 0, false),
+		// Truncating 2^64 to a uint64 would refer to the commitment at index 0
+		revealTooLarge("reveal index 2^64", new(big.Int).Lsh(common.Big1, 64)),
+		revealTooLarge("reveal max index", math.MaxBig256),
+		{
+			name:        "reveal batch with oversized index",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackRevealBatch([]*big.Int{math.MaxBig256}, []common.Hash{revealed}) },
+			suppliedGas: RevealBatchGasCost + RevealGasCost,
+			expectedErr: ErrRevealIndexTooLarge.Error(),
+		},
+		{
+			name:        "reveal",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, revealed) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		wasRevealed("revealed preimage", 14, revealed, 1, true),
+		wasRevealed("unrevealed preimage", 14, unrevealed, 1, false),
+		{
+			name:        "was revealed insufficient gas",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackWasRevealed(unrevealed) },
+			suppliedGas: WasRevealedGasCost,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	})
+
+	t.Run("reveal order", func(t *testing.T) {
+		committers := []common.Address{
+			common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+			common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+			common.HexToAddress("0x2Fa8EA536Be85F32724D57A37758761B86416123"),
+		}
+		preimages := []common.Hash{{0x1}, {0x2}, {0x3}}
+		// Commitments are revealed (in the same block) in a different order than
+		// they were committed
+		revealOrder := []int{2, 0, 1}
+		s := newRandomPartyState()
+
+		tests := []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+		}
+		for i, committer := range committers {
+			preimage := preimages[i]
+			s.AddBalance(committer, big.NewInt(1000))
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("commit %d", i),
+				caller:      committer,
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(big.NewInt(int64(i))),
+			})
+		}
+		var expectedPreimages []byte
+		for _, i := range revealOrder {
+			idx, preimage := big.NewInt(int64(i)), preimages[i]
+			expectedPreimages = append(expectedPreimages, preimage.Bytes()...)
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("reveal %d", i),
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(idx, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			})
+		}
+		tests = append(tests, randomPartyTest{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + 3*ComputeItemCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				// Reveal indices follow the order in which reveals were executed
+				for ri, i := range revealOrder {
+					preimageKey, err := RandomPartyStorageKey(RandomPartyReveals, common.Big0, big.NewInt(int64(ri)))
+					assert.NilError(t, err)
+					assert.Equal(t, state.GetState(RandomPartyAddress, preimageKey), preimages[i])
+					recipientKey, err := RandomPartyStorageKey(RandomPartyRevealRecipients, common.Big0, big.NewInt(int64(ri)))
+					assert.NilError(t, err)
+					assert.Equal(t, state.GetState(RandomPartyAddress, recipientKey), committers[i].Hash())
+				}
+			},
+		}, randomPartyTest{
+			name:        "result",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return PackResult(common.Big0) },
+			suppliedGas: ResultCost,
+			expectedRes: crypto.Keccak256(expectedPreimages),
+		})
+		runRandomPartyTests(t, s, committers[0], tests)
+	})
+}
+
+func TestRandomPartyRevealBonus(t *testing.T) {
+	revealer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	forfeiter := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	preimage := common.BytesToHash([]byte{0x1})
+	for _, tt := range []struct {
+		name          string
+		stakeWeighted bool
+		bonus         int64
+		forfeited     int64 // stake committed by the forfeiter (if any)
+	}{
+		{
+			name:      "with forfeits",
+			bonus:     100,
+			forfeited: 1000,
+		},
+		{
+			// The bonus is paid out of the forfeited stake (3000), not the
+			// number of forfeits times [CommitStake] (1000)
+			name:          "with stake weighted forfeits",
+			stakeWeighted: true,
+			bonus:         2000,
+			forfeited:     3000,
+		},
+		{
+			name:  "without forfeits",
+			bonus: 100,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRandomPartyState()
+			SetForfeitDestination(s, ForfeitToPool)
+			SetStakeWeighted(s, tt.stakeWeighted)
+			SetRevealBonus(s, big.NewInt(tt.bonus))
+			s.AddBalance(revealer, big.NewInt(100000))
+			s.AddBalance(forfeiter, big.NewInt(100000))
+
+			tests := []randomPartyTest{
+				{
+					name:        "start",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: StartGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "commit revealer",
+					btime:       big.NewInt(10),
+					value:       big.NewInt(1000),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big0),
+				},
+			}
+			computeGas := ComputeGasCost + ComputeItemCost + resultComputedLogGasCost
+			bonus := int64(0)
+			if tt.forfeited > 0 {
+				tests = append(tests, randomPartyTest{
+					name:        "commit forfeiter",
+					caller:      forfeiter,
+					btime:       big.NewInt(10),
+					value:       big.NewInt(tt.forfeited),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash([]byte{0x2})) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big1),
+				})
+				computeGas += ComputeRewardCost + rewardPerRevealerLogGasCost
+				bonus = tt.bonus
+			}
+			tests = append(tests,
+				randomPartyTest{
+					name:        "reveal",
+					btime:       big.NewInt(14),
+					input:       func() []byte { return PackReveal(common.Big0, preimage) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+				},
+				randomPartyTest{
+					name:        "compute",
+					btime:       big.NewInt(16),
+					input:       func() []byte { return ComputeSignature },
+					suppliedGas: computeGas,
+					expectedRes: []byte{},
+					assertState: func(t *testing.T, state *countingStateDB) {
+						assert.Equal(t, state.GetBalance(revealer).Int64(), int64(100000+bonus))
+						assert.Equal(t, state.GetBalance(forfeiter).Int64(), int64(100000-tt.forfeited))
+					},
+				},
+			)
+			if tt.forfeited > 0 {
+				tests = append(tests,
+					randomPartyTest{
+						name:        "start next",
+						btime:       big.NewInt(20),
+						input:       func() []byte { return StartSignature },
+						suppliedGas: StartGasCost + DeleteGasCost*3,
+						expectedRes: []byte{},
+					},
+					randomPartyTest{
+						name:        "reward excludes paid bonus",
+						btime:       big.NewInt(21),
+						input:       func() []byte { return RewardSignature },
+						suppliedGas: RewardGasCost,
+						expectedRes: HBigBytes(big.NewInt(tt.forfeited - tt.bonus)),
+					},
+				)
+			}
+			runRandomPartyTests(t, s, revealer, tests)
+		})
+	}
+
+	sponsor := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	revealers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	for _, tt := range []struct {
+		name            string
+		sponsored       int64
+		expectedBonus   int64
+		expectedRewards int64
+	}{
+		{
+			name:            "first reveal bonus paid from pool",
+			sponsored:       300,
+			expectedBonus:   50,
+			expectedRewards: 125,
+		},
+		{
+			name:            "first reveal bonus limited to pool",
+			sponsored:       20,
+			expectedBonus:   20,
+			expectedRewards: 0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRandomPartyState()
+			SetFirstRevealBonus(s, big.NewInt(50))
+			s.AddBalance(sponsor, big.NewInt(tt.sponsored))
+
+			tests := []randomPartyTest{
+				{
+					name:        "start",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: StartGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "sponsor",
+					caller:      sponsor,
+					btime:       big.NewInt(11),
+					value:       big.NewInt(tt.sponsored),
+					input:       func() []byte { return SponsorSignature },
+					suppliedGas: SponsorGasCost,
+					expectedRes: []byte{},
+				},
+			}
+			for i, revealer := range revealers {
+				preimage := common.Hash{byte(i + 1)}
+				s.AddBalance(revealer, big.NewInt(1000))
+				tests = append(tests, randomPartyTest{
+					name:        fmt.Sprintf("commit %d", i),
+					caller:      revealer,
+					btime:       big.NewInt(11),
+					value:       big.NewInt(1000),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(big.NewInt(int64(i))),
+				})
+			}
+			tests = append(tests,
+				randomPartyTest{
+					name:        "first reveal",
+					btime:       big.NewInt(14),
+					input:       func() []byte { return PackReveal(common.Big1, common.Hash{0x2}) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+					assertState: func(t *testing.T, state *countingStateDB) {
+						assert.Equal(t, state.GetBalance(revealers[1]).Int64(), int64(1000+tt.expectedBonus))
+					},
+				},
+				randomPartyTest{
+					name:        "second reveal",
+					btime:       big.NewInt(14),
+					input:       func() []byte { return PackReveal(common.Big0, common.Hash{0x1}) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+					assertState: func(t *testing.T, state *countingStateDB) {
+						assert.Equal(t, state.GetBalance(revealers[0]).Int64(), int64(1000))
+					},
+				},
+				randomPartyTest{
+					name:        "reward",
+					btime:       big.NewInt(14),
+					input:       func() []byte { return RewardSignature },
+					suppliedGas: RewardGasCost,
+					expectedRes: HBigBytes(big.NewInt(tt.sponsored - tt.expectedBonus)),
+				},
+			)
+			computeGas := ComputeGasCost + 2*ComputeItemCost + resultComputedLogGasCost
+			if tt.expectedRewards > 0 {
+				computeGas += 2*ComputeRewardCost + rewardPerRevealerLogGasCost
+			}
+			tests = append(tests, randomPartyTest{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: computeGas,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					// The rest of the incentive pool is split equally
+					assert.Equal(t, state.GetBalance(revealers[0]).Int64(), int64(1000+tt.expectedRewards))
+					assert.Equal(t, state.GetBalance(revealers[1]).Int64(), int64(1000+tt.expectedBonus+tt.expectedRewards))
+				},
+			})
+			runRandomPartyTests(t, s, sponsor, tests)
+		})
+	}
+}
+
+func TestRandomPartyLockedStake(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	other := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	preimages := []common.Hash{{0x1}, {0x2}, {0x3}}
+	s := newRandomPartyState()
+	s.AddBalance(committer, big.NewInt(100000))
+	s.AddBalance(other, big.NewInt(100000))
+
+	lockedStake := func(name string, btime int64, addr common.Address, commits uint64, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackLockedStake(addr) },
+			suppliedGas: LockedStakeGasCost + commits*LockedStakeItemCost,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+	commit := func(name string, caller common.Address, preimage common.Hash, idx int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			caller:      caller,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(idx)),
+		}
+	}
+	runRandomPartyTests(t, s, committer, []randomPartyTest{
+		lockedStake("locked stake before start", 5, committer, 0, 0),
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		commit("commit first", committer, preimages[0], 0),
+		commit("commit other", other, preimages[1], 1),
+		commit("commit second", committer, preimages[2], 2),
+		lockedStake("locked stake with two commits", 12, committer, 3, 2000),
+		lockedStake("locked stake of other", 12, other, 3, 1000),
+		{
+			name:        "insufficient gas",
+			btime:       big.NewInt(12),
+			input:       func() []byte { return PackLockedStake(committer) },
+			suppliedGas: LockedStakeGasCost + 2*LockedStakeItemCost,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		{
+			name:        "reveal first",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimages[0]) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		lockedStake("locked stake after one reveal", 14, committer, 3, 1000),
+		{
+			name:        "reveal second",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big2, preimages[2]) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		lockedStake("locked stake after all reveals", 14, committer, 3, 0),
+		lockedStake("locked stake of other after reveals", 14, other, 3, 1000),
+	})
+}
+
+func TestRandomPartyCommitSigned(t *testing.T) {
+	relayer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	key, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	assert.NilError(t, err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	preimage := common.Hash{0x1}
+	hash := crypto.Keccak256Hash(preimage.Bytes())
+
+	sign := func(t *testing.T, hash common.Hash, round *big.Int) (uint8, common.Hash, common.Hash) {
+		sig, err := crypto.Sign(CommitDigest(hash, round).Bytes(), key)
+		assert.NilError(t, err)
+		return sig[crypto.RecoveryIDOffset] + 27, common.BytesToHash(sig[:32]), common.BytesToHash(sig[32:64])
+	}
+	start := randomPartyTest{
+		name:        "start",
+		btime:       big.NewInt(10),
+		input:       func() []byte { return StartSignature },
+		suppliedGas: StartGasCost,
+		expectedRes: []byte{},
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		s := newRandomPartyState()
+		s.AddBalance(relayer, big.NewInt(1000))
+		v, r, sig := sign(t, hash, common.Big0)
+		runRandomPartyTests(t, s, relayer, []randomPartyTest{
+			start,
+			{
+				name:        "commit signed",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommitSigned(hash, v, r, sig) },
+				suppliedGas: CommitSignedGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "locked stake of signer",
+				btime:       big.NewInt(12),
+				input:       func() []byte { return PackLockedStake(signer) },
+				suppliedGas: LockedStakeGasCost + LockedStakeItemCost,
+				expectedRes: HBigBytes(big.NewInt(1000)),
+			},
+			{
+				name:        "locked stake of relayer",
+				btime:       big.NewInt(12),
+				input:       func() []byte { return PackLockedStake(relayer) },
+				suppliedGas: LockedStakeGasCost + LockedStakeItemCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "reveal",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					assert.Equal(t, state.GetBalance(signer).Int64(), int64(1000))
+					assert.Equal(t, state.GetBalance(relayer).Sign(), 0)
+				},
+			},
+		})
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		s := newRandomPartyState()
+		s.AddBalance(relayer, big.NewInt(1000))
+		// A signature for a different round recovers some other address, so
+		// the commitment is never credited to the signer
+		v, r, sig := sign(t, hash, common.Big1)
+		runRandomPartyTests(t, s, relayer, []randomPartyTest{
+			start,
+			{
+				name:        "commit signed for another round",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommitSigned(hash, v, r, sig) },
+				suppliedGas: CommitSignedGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "locked stake of signer",
+				btime:       big.NewInt(12),
+				input:       func() []byte { return PackLockedStake(signer) },
+				suppliedGas: LockedStakeGasCost + LockedStakeItemCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "commit signed with invalid recovery id",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommitSigned(hash, 29, r, sig) },
+				suppliedGas: CommitSignedGasCost,
+				expectedErr: ErrInvalidSignature.Error(),
+			},
+			{
+				name:        "commit signed with zero signature",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommitSigned(hash, 27, common.Hash{}, common.Hash{}) },
+				suppliedGas: CommitSignedGasCost,
+				expectedErr: ErrInvalidSignature.Error(),
+			},
+		})
+	})
+}
+
+func TestRandomPartyDeadlines(t *testing.T) {
+	anyAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+
+	t.Run("deadline views", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+
+		// deadlines checks every deadline view at [btime]
+		deadlines := func(name string, btime int64, timeRemaining int64, nextDeadline int64, commitRemaining int64, revealRemaining int64) []randomPartyTest {
+			view := func(view string, input []byte, suppliedGas uint64, expected int64) randomPartyTest {
+				return randomPartyTest{
+					name:        name + " " + view,
+					btime:       big.NewInt(btime),
+					input:       func() []byte { return input },
+					suppliedGas: suppliedGas,
+					readOnly:    true,
+					expectedRes: HBigBytes(big.NewInt(expected)),
+				}
+			}
+			return []randomPartyTest{
+				view("time remaining", TimeRemainingSignature, TimeRemainingGasCost, timeRemaining),
+				view("next deadline", NextDeadlineSignature, NextDeadlineGasCost, nextDeadline),
+				view("commit deadline remaining", GetCommitDeadlineRemainingSignature, DeadlineRemainingGasCost, commitRemaining),
+				view("reveal deadline remaining", GetRevealDeadlineRemainingSignature, DeadlineRemainingGasCost, revealRemaining),
+			}
+		}
+		var tests []randomPartyTest
+		tests = append(tests, deadlines("idle before start", 5, 0, 0, 0, 0)...)
+		tests = append(tests, randomPartyTest{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		})
+		tests = append(tests, deadlines("start of commit phase", 10, 3, 13, 3, 6)...)
+		tests = append(tests, deadlines("end of commit phase", 12, 1, 13, 1, 4)...)
+		tests = append(tests, deadlines("start of reveal phase", 13, 3, 16, 0, 3)...)
+		tests = append(tests, deadlines("end of reveal phase", 15, 1, 16, 0, 1)...)
+		tests = append(tests, deadlines("computable", 16, 0, 0, 0, 0)...)
+		for _, invalid := range []struct {
+			method string
+			input  []byte
+			gas    uint64
+		}{
+			{"timeRemaining", TimeRemainingSignature, TimeRemainingGasCost},
+			{"nextDeadline", NextDeadlineSignature, NextDeadlineGasCost},
+			{"getCommitDeadlineRemaining", GetCommitDeadlineRemainingSignature, DeadlineRemainingGasCost},
+			{"getRevealDeadlineRemaining", GetRevealDeadlineRemainingSignature, DeadlineRemainingGasCost},
+		} {
+			invalid := invalid
+			tests = append(tests, randomPartyTest{
+				name:        "invalid input for " + invalid.method,
+				btime:       big.NewInt(16),
+				input:       func() []byte { return append(invalid.input[:4:4], 0x1) },
+				suppliedGas: invalid.gas,
+				expectedErr: "invalid input length for " + invalid.method,
+			})
+		}
+		tests = append(tests, randomPartyTest{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		})
+		tests = append(tests, deadlines("idle after compute", 17, 0, 0, 0, 0)...)
+		runRandomPartyTests(t, s, anyAddr, tests)
+	})
+
+	t.Run("start time", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+
+		runRandomPartyTests(t, s, anyAddr, []randomPartyTest{
+			{
+				name:        "start time before any party",
+				btime:       big.NewInt(5),
+				input:       func() []byte { return StartTimeSignature },
+				suppliedGas: StartTimeGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "start time",
+				btime:       big.NewInt(12),
+				input:       func() []byte { return StartTimeSignature },
+				suppliedGas: StartTimeGasCost,
+				expectedRes: HBigBytes(big.NewInt(10)),
+			},
+			{
+				name:        "compute",
+				btime:       big.NewInt(20),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "start again",
+				btime:       big.NewInt(25),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "start time reset",
+				btime:       big.NewInt(25),
+				input:       func() []byte { return StartTimeSignature },
+				suppliedGas: StartTimeGasCost,
+				expectedRes: HBigBytes(big.NewInt(25)),
+			},
+			{
+				name:        "start time with input",
+				btime:       big.NewInt(25),
+				input:       func() []byte { return append(StartTimeSignature, 0x1) },
+				suppliedGas: StartTimeGasCost,
+				expectedErr: "invalid input length for startTime",
+			},
+		})
+	})
+
+	t.Run("computable at", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+
+		computableAt := func(name string, timestamp int64, expected bool) randomPartyTest {
+			res := common.Big0
+			if expected {
+				res = common.Big1
+			}
+			return randomPartyTest{
+				name:        name,
+				btime:       big.NewInt(11),
+				input:       func() []byte { return PackComputableAt(big.NewInt(timestamp)) },
+				suppliedGas: ComputableAtGasCost,
+				expectedRes: HBigBytes(res),
+			}
+		}
+
+		// Party started at 10 has a reveal deadline of 16
+		runRandomPartyTests(t, s, anyAddr, []randomPartyTest{
+			computableAt("no party", 100, false),
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			computableAt("before reveal deadline", 15, false),
+			computableAt("at reveal deadline", 16, true),
+			computableAt("after reveal deadline", 17, true),
+			{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+			computableAt("computed", 17, false),
+			{
+				name:        "invalid input",
+				btime:       big.NewInt(17),
+				input:       func() []byte { return ComputableAtSignature },
+				suppliedGas: ComputableAtGasCost,
+				expectedErr: "invalid input length for computableAt",
+			},
+		})
+	})
+
+	t.Run("phase duration", func(t *testing.T) {
+		s := newRandomPartyState()
+		runRandomPartyTests(t, s, anyAddr, []randomPartyTest{
+			{
+				name:        "phase duration",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return PhaseDurationSignature },
+				suppliedGas: PhaseDurationGasCost,
+				readOnly:    true,
+				expectedRes: HBigBytes(big.NewInt(3)),
+			},
+			{
+				name:        "invalid input",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return append(PhaseDurationSignature[:4:4], 0x1) },
+				suppliedGas: PhaseDurationGasCost,
+				expectedErr: "invalid input length for phaseDuration",
+			},
+			{
+				name:        "insufficient gas",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return PhaseDurationSignature },
+				suppliedGas: PhaseDurationGasCost - 1,
+				expectedErr: vmerrs.ErrOutOfGas.Error(),
+			},
+		})
+	})
+}
+
+func TestRandomPartyLastForfeitCount(t *testing.T) {
+	committers := []common.Address{
+		common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a"),
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	preimages := []common.Hash{{0x1}, {0x2}}
+	s := newRandomPartyState()
+	for _, committer := range committers {
+		s.AddBalance(committer, big.NewInt(1000))
+	}
+
+	lastForfeitCount := func(name string, btime int64, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return LastForfeitCountSignature },
+			suppliedGas: LastForfeitCountGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+	tests := []randomPartyTest{
+		lastForfeitCount("no rounds", 5, 0),
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+	}
+	for i, committer := range committers {
+		i := i
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("commit %d", i),
+			caller:      committer,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimages[i].Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+		})
+	}
+	runRandomPartyTests(t, s, committers[0], append(tests, []randomPartyTest{
+		{
+			// Only the first committer reveals
+			name:        "reveal",
+			caller:      committers[0],
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimages[0]) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "compute",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		},
+		// The count is only recorded when the next Random Party is started
+		lastForfeitCount("before next start", 17, 0),
+		{
+			name:        "start next",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + DeleteGasCost*3 + ComputeRewardCost,
+			expectedRes: []byte{},
+		},
+		lastForfeitCount("after next start", 17, 1),
+		{
+			name:        "invalid input",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return append(LastForfeitCountSignature, 0x1) },
+			suppliedGas: LastForfeitCountGasCost,
+			expectedErr: "invalid input length for lastForfeitCount",
+		},
+		{
+			name:        "insufficient gas",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return LastForfeitCountSignature },
+			suppliedGas: LastForfeitCountGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	}...))
+}
+
+func TestRandomPartyAutoComputeOnStart(t *testing.T) {
+	committers := []common.Address{
+		common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a"),
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	preimages := []common.Hash{{0x1}, {0x2}}
+	s := newRandomPartyState()
+	SetAutoComputeOnStart(s, true)
+	for _, committer := range committers {
+		s.AddBalance(committer, big.NewInt(1000))
+	}
+
+	tests := []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+	}
+	for i, committer := range committers {
+		i := i
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("commit %d", i),
+			caller:      committer,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimages[i].Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+		})
+	}
+	// The previous Random Party is computed (with a single reveal) and then
+	// cleared, forfeiting the unrevealed commitment to the revealer
+	startGas := StartGasCost + ComputeGasCost + ComputeItemCost + resultComputedLogGasCost +
+		DeleteGasCost*3 + ComputeRewardCost
+	runRandomPartyTests(t, s, committers[0], append(tests, []randomPartyTest{
+		{
+			name:        "reveal",
+			caller:      committers[0],
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimages[0]) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			// The Random Party cannot be computed until the reveal deadline
+			name:        "start before reveal deadline",
+			btime:       big.NewInt(15),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: startGas,
+			expectedErr: ErrRandomPartyUnderway.Error(),
+		},
+		{
+			name:        "start insufficient gas",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: startGas - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		{
+			name:        "start read only",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: startGas,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		{
+			name:        "start next",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: startGas,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(committers[0]).Int64(), int64(2000))
+				assert.Equal(t, state.GetBalance(committers[1]).Sign(), 0)
+			},
+		},
+		{
+			name:        "result",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return PackResult(common.Big0) },
+			suppliedGas: ResultCost,
+			expectedRes: crypto.Keccak256(preimages[0].Bytes()),
+		},
+		{
+			// The new Random Party is stored under the next round
+			name:        "next",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return NextSignature },
+			suppliedGas: NextCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(common.Big1),
+		},
+		{
+			name:        "commit to new round",
+			caller:      committers[0],
+			btime:       big.NewInt(18),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimages[0].Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+	}...))
+}
+
+func TestRandomPartyRewardViews(t *testing.T) {
+	t.Run("reward per revealer", func(t *testing.T) {
+		revealers := []common.Address{
+			common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a"),
+			common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		}
+		sponsor := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+		preimages := []common.Hash{{0x1}, {0x2}}
+		s := newRandomPartyState()
+		s.AddBalance(sponsor, big.NewInt(1001))
+		for _, revealer := range revealers {
+			s.AddBalance(revealer, big.NewInt(1000))
+		}
+
+		rewardPerRevealer := func(name string, round *big.Int, expected int64) randomPartyTest {
+			return randomPartyTest{
+				name:        name,
+				btime:       big.NewInt(16),
+				input:       func() []byte { return PackRewardPerRevealer(round) },
+				suppliedGas: RewardPerRevealerGasCost,
+				readOnly:    true,
+				expectedRes: HBigBytes(big.NewInt(expected)),
+			}
+		}
+		tests := []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "sponsor",
+				caller:      sponsor,
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1001),
+				input:       func() []byte { return SponsorSignature },
+				suppliedGas: SponsorGasCost,
+				expectedRes: []byte{},
+			},
+		}
+		for i, revealer := range revealers {
+			i := i
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("commit %d", i),
+				caller:      revealer,
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimages[i].Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(big.NewInt(int64(i))),
+			})
+		}
+		for i, revealer := range revealers {
+			i := i
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("reveal %d", i),
+				caller:      revealer,
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(big.NewInt(int64(i)), preimages[i]) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			})
+		}
+		runRandomPartyTests(t, s, revealers[0], append(tests, []randomPartyTest{
+			rewardPerRevealer("before compute", common.Big0, 0),
+			{
+				// The incentive pool is split equally (rounding down)
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + 2*(ComputeItemCost+ComputeRewardCost) + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					logs := state.Logs()
+					assert.Equal(t, len(logs), 2)
+					assert.Equal(t, logs[1].Address, RandomPartyAddress)
+					assert.DeepEqual(t, logs[1].Topics, []common.Hash{RewardPerRevealerTopic, common.BigToHash(common.Big0)})
+					assert.DeepEqual(t, logs[1].Data, HBigBytes(big.NewInt(1001/2)))
+					for _, revealer := range revealers {
+						assert.Equal(t, state.GetBalance(revealer).Int64(), int64(1000+1001/2))
+					}
+				},
+			},
+			rewardPerRevealer("after compute", common.Big0, 1001/2),
+			rewardPerRevealer("not computed", common.Big1, 0),
+			rewardPerRevealer("round too large", new(big.Int).Lsh(common.Big1, 64), 0),
+			{
+				name:        "invalid input",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return append(PackRewardPerRevealer(common.Big0), 0x1) },
+				suppliedGas: RewardPerRevealerGasCost,
+				expectedErr: "invalid input length for rewardPerRevealer",
+			},
+			{
+				name:        "insufficient gas",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return PackRewardPerRevealer(common.Big0) },
+				suppliedGas: RewardPerRevealerGasCost - 1,
+				expectedErr: vmerrs.ErrOutOfGas.Error(),
+			},
+		}...))
+	})
+
+	t.Run("restrict reward view", func(t *testing.T) {
+		adminAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		readerAddr := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+		otherAddr := common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123")
+
+		for _, restrict := range []bool{false, true} {
+			restrict := restrict
+			t.Run(fmt.Sprintf("restrict=%t", restrict), func(t *testing.T) {
+				s := newRandomPartyState()
+				(&RandomPartyConfig{
+					PhaseSeconds:       big.NewInt(3),
+					CommitStake:        big.NewInt(1000),
+					AllowListAdmins:    []common.Address{adminAddr},
+					EnabledAddresses:   []common.Address{readerAddr},
+					RestrictRewardView: restrict,
+				}).Configure(s)
+				s.AddBalance(adminAddr, big.NewInt(100))
+
+				// Callers that cannot read the pool see it as 0 in snapshot()
+				otherPool := int64(100)
+				if restrict {
+					otherPool = 0
+				}
+
+				reward := func(name string, caller common.Address, permitted bool) randomPartyTest {
+					test := randomPartyTest{
+						name:        name,
+						caller:      caller,
+						btime:       big.NewInt(11),
+						input:       func() []byte { return RewardSignature },
+						suppliedGas: RewardGasCost,
+						readOnly:    true,
+						expectedRes: HBigBytes(big.NewInt(100)),
+					}
+					if !permitted {
+						test.expectedRes = nil
+						test.expectedErr = ErrCannotReadReward.Error()
+					}
+					return test
+				}
+				snapshotReward := func(name string, caller common.Address, expected int64) randomPartyTest {
+					var res []byte
+					for _, word := range []*big.Int{
+						new(big.Int).SetUint64(uint64(RandomPartyPhaseCommit)), common.Big0, common.Big0,
+						big.NewInt(13), big.NewInt(16), big.NewInt(expected), common.Big0,
+					} {
+						res = append(res, HBigBytes(word)...)
+					}
+					return randomPartyTest{
+						name:        name,
+						caller:      caller,
+						btime:       big.NewInt(11),
+						input:       func() []byte { return SnapshotSignature },
+						suppliedGas: SnapshotGasCost,
+						readOnly:    true,
+						expectedRes: res,
+					}
+				}
+				runRandomPartyTests(t, s, adminAddr, []randomPartyTest{
+					{
+						name:        "start",
+						btime:       big.NewInt(10),
+						input:       func() []byte { return StartSignature },
+						suppliedGas: StartGasCost,
+						expectedRes: []byte{},
+					},
+					{
+						name:        "sponsor",
+						btime:       big.NewInt(11),
+						value:       big.NewInt(100),
+						input:       func() []byte { return SponsorSignature },
+						suppliedGas: SponsorGasCost,
+						expectedRes: []byte{},
+					},
+					reward("admin", adminAddr, true),
+					reward("enabled reader", readerAddr, true),
+					reward("other reader", otherAddr, !restrict),
+					snapshotReward("enabled snapshot", readerAddr, 100),
+					snapshotReward("other snapshot", otherAddr, otherPool),
+				})
+			})
+		}
+	})
+}
+
+func TestRandomPartySponsor(t *testing.T) {
+	revealer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	sponsors := []common.Address{
+		common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B"),
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	preimage := common.Hash{0x1}
+	s := newRandomPartyState()
+	s.AddBalance(revealer, big.NewInt(1000))
+	for _, sponsor := range sponsors {
+		s.AddBalance(sponsor, big.NewInt(500))
+	}
+
+	sponsorCount := func(name string, btime int64, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return SponsorCountSignature },
+			suppliedGas: SponsorCountGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+	sponsoredTotal := func(name string, btime int64, round int64, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackSponsoredTotal(big.NewInt(round)) },
+			suppliedGas: SponsoredTotalGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+	sponsor := func(name string, sponsor common.Address, amount int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			caller:      sponsor,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(amount),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedRes: []byte{},
+		}
+	}
+	runRandomPartyTests(t, s, revealer, []randomPartyTest{
+		sponsorCount("sponsor count before start", 5, 0),
+		sponsoredTotal("sponsored total before start", 5, 0, 0),
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		sponsorCount("no sponsors", 10, 0),
+		sponsor("sponsor 0", sponsors[0], 100),
+		sponsorCount("one sponsor", 11, 1),
+		sponsor("sponsor 1", sponsors[1], 300),
+		// Sponsoring again does not count the sponsor twice
+		sponsor("sponsor 0 again", sponsors[0], 100),
+		sponsorCount("two sponsors", 11, 2),
+		sponsoredTotal("sponsored total during round", 12, 0, 500),
+		{
+			name:        "commit",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		{
+			name:        "reveal",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimage) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				// Everything that was sponsored was distributed to the revealer
+				assert.Equal(t, state.GetBalance(revealer).Int64(), int64(1000+500))
+			},
+		},
+		// The count is kept until the next Random Party is started
+		sponsorCount("sponsor count after compute", 16, 2),
+		sponsoredTotal("sponsored total after compute", 17, 0, 500),
+		sponsoredTotal("sponsored total of other round", 17, 1, 0),
+		{
+			name:        "start next",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + DeleteGasCost*2,
+			expectedRes: []byte{},
+		},
+		sponsorCount("sponsor count after next start", 17, 0),
+		{
+			name:        "invalid input",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return append(SponsorCountSignature, 0x1) },
+			suppliedGas: SponsorCountGasCost,
+			expectedErr: "invalid input length for sponsorCount",
+		},
+		{
+			name:        "insufficient gas",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return SponsorCountSignature },
+			suppliedGas: SponsorCountGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	})
+
+	for _, tt := range []struct {
+		name                       string
+		sponsorUntilRevealDeadline bool
+		expectedErr                string
+		expectedReward             int64
+	}{
+		{
+			name:        "until commit deadline",
+			expectedErr: ErrTooLate.Error(),
+		},
+		{
+			name:                       "until reveal deadline",
+			sponsorUntilRevealDeadline: true,
+			expectedReward:             100,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRandomPartyState()
+			SetSponsorUntilRevealDeadline(s, tt.sponsorUntilRevealDeadline)
+			s.AddBalance(sponsors[0], big.NewInt(200))
+
+			runRandomPartyTests(t, s, sponsors[0], []randomPartyTest{
+				{
+					name:        "start",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: StartGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "sponsor during reveal phase",
+					btime:       big.NewInt(14),
+					value:       big.NewInt(100),
+					input:       func() []byte { return SponsorSignature },
+					suppliedGas: SponsorGasCost,
+					expectedRes: []byte{},
+					expectedErr: tt.expectedErr,
+				},
+				{
+					name:        "sponsor after reveal deadline",
+					btime:       big.NewInt(16),
+					value:       big.NewInt(100),
+					input:       func() []byte { return SponsorSignature },
+					suppliedGas: SponsorGasCost,
+					expectedErr: ErrTooLate.Error(),
+				},
+				{
+					name:        "reward",
+					btime:       big.NewInt(16),
+					input:       func() []byte { return RewardSignature },
+					suppliedGas: RewardGasCost,
+					expectedRes: HBigBytes(big.NewInt(tt.expectedReward)),
+				},
+			})
+		})
+	}
+}
+
+func TestRandomPartyPause(t *testing.T) {
+	adminAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	user := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+	preimage := common.Hash{0x1}
+	s := newRandomPartyState()
+	(&RandomPartyConfig{
+		PhaseSeconds:    big.NewInt(3),
+		CommitStake:     big.NewInt(1000),
+		AllowListAdmins: []common.Address{adminAddr},
+	}).Configure(s)
+	s.AddBalance(user, big.NewInt(2000))
+
+	setPaused := func(name string, btime int64, flags PauseFlags) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackSetPaused(flags) },
+			suppliedGas: SetPausedGasCost,
+			expectedRes: []byte{},
+		}
+	}
+	paused := func(name string, btime int64, expected PauseFlags) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PausedSignature },
+			suppliedGas: PausedGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(new(big.Int).SetUint64(uint64(expected))),
+		}
+	}
+	start := func(name string, btime int64, suppliedGas uint64, expectedErr string) randomPartyTest {
+		test := randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: suppliedGas,
+			expectedErr: expectedErr,
+		}
+		if len(expectedErr) == 0 {
+			test.expectedRes = []byte{}
+		}
+		return test
+	}
+	sponsor := func(name string, expectedErr string) randomPartyTest {
+		test := randomPartyTest{
+			name:        name,
+			caller:      user,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(100),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedErr: expectedErr,
+		}
+		if len(expectedErr) == 0 {
+			test.expectedRes = []byte{}
+		}
+		return test
+	}
+	commit := func(name string, expectedErr string) randomPartyTest {
+		test := randomPartyTest{
+			name:        name,
+			caller:      user,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedErr: expectedErr,
+		}
+		if len(expectedErr) == 0 {
+			test.expectedRes = HBigBytes(common.Big0)
+		}
+		return test
+	}
+	errPaused := ErrMethodPaused.Error()
+	runRandomPartyTests(t, s, adminAddr, []randomPartyTest{
+		paused("nothing paused", 5, 0),
+		{
+			name:        "set paused from non-admin",
+			caller:      user,
+			btime:       big.NewInt(5),
+			input:       func() []byte { return PackSetPaused(PauseStart) },
+			suppliedGas: SetPausedGasCost,
+			expectedErr: ErrCannotPause.Error(),
+		},
+		{
+			name:        "set unknown flags",
+			btime:       big.NewInt(5),
+			input:       func() []byte { return PackSetPaused(PauseCommit << 1) },
+			suppliedGas: SetPausedGasCost,
+			expectedErr: ErrInvalidPauseFlags.Error(),
+		},
+		{
+			name:        "set paused read only",
+			btime:       big.NewInt(5),
+			input:       func() []byte { return PackSetPaused(PauseStart) },
+			suppliedGas: SetPausedGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		{
+			name:        "set paused insufficient gas",
+			btime:       big.NewInt(5),
+			input:       func() []byte { return PackSetPaused(PauseStart) },
+			suppliedGas: SetPausedGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+
+		// Pausing start does not pause anything else
+		setPaused("pause start", 5, PauseStart),
+		paused("start paused", 5, PauseStart),
+		start("start while paused", 10, StartGasCost, errPaused),
+		setPaused("unpause start", 10, 0),
+		start("start", 10, StartGasCost, ""),
+
+		setPaused("pause sponsor", 11, PauseSponsor),
+		sponsor("sponsor while paused", errPaused),
+		commit("commit while sponsor paused", ""),
+
+		setPaused("pause commit", 11, PauseCommit),
+		commit("commit while paused", errPaused),
+		sponsor("sponsor while commit paused", ""),
+
+		// An underway Random Party can always be completed
+		setPaused("pause all", 12, PauseStart|PauseSponsor|PauseCommit),
+		paused("all paused", 12, PauseStart|PauseSponsor|PauseCommit),
+		{
+			name:        "reveal while paused",
+			caller:      user,
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimage) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:  "compute while paused",
+			btime: big.NewInt(16),
+			input: func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + ComputeRewardCost +
+				resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+		},
+		start("start next while paused", 16, StartGasCost+DeleteGasCost*2, errPaused),
+		setPaused("unpause all", 16, 0),
+		start("start next", 16, StartGasCost+DeleteGasCost*2, ""),
+		{
+			name:        "paused invalid input",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return append(PausedSignature, 0x1) },
+			suppliedGas: PausedGasCost,
+			expectedErr: "invalid input length for paused",
+		},
+	})
+}
+
+func TestRandomPartyMaxPayoutsPerCompute(t *testing.T) {
+	sponsor := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	revealers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x2Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	s := newRandomPartyState()
+	SetMaxPayoutsPerCompute(s, 2)
+	s.AddBalance(sponsor, big.NewInt(300))
+
+	tests := []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "sponsor",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(300),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedRes: []byte{},
+		},
+	}
+	for i, revealer := range revealers {
+		preimage := common.Hash{byte(i + 1)}
+		s.AddBalance(revealer, big.NewInt(1000))
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("commit %d", i),
+			caller:      revealer,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+		})
+	}
+	for i, revealer := range revealers {
+		idx, preimage := big.NewInt(int64(i)), common.Hash{byte(i + 1)}
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("reveal %d", i),
+			caller:      revealer,
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(idx, preimage) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		})
+	}
+	claimable := func(name string, account common.Address, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(17),
+			input:       func() []byte { return PackClaimable(account) },
+			suppliedGas: ClaimableGasCost,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+	tests = append(tests, []randomPartyTest{
+		{
+			// Only the capped payouts are charged for
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + 3*ComputeItemCost + 2*ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(revealers[0]).Int64(), int64(1100))
+				assert.Equal(t, state.GetBalance(revealers[1]).Int64(), int64(1100))
+				assert.Equal(t, state.GetBalance(revealers[2]).Int64(), int64(1000))
+			},
+		},
+		{
+			name:        "result is finalized",
+			btime:       big.NewInt(17),
+			input:       func() []byte { return NextSignature },
+			suppliedGas: NextCost,
+			expectedRes: HBigBytes(common.Big1),
+		},
+		claimable("claimable of paid revealer", revealers[0], 0),
+		claimable("claimable of deferred revealer", revealers[2], 100),
+		{
+			name:        "claim without credit",
+			caller:      revealers[0],
+			btime:       big.NewInt(17),
+			input:       func() []byte { return ClaimSignature },
+			suppliedGas: ClaimGasCost,
+			expectedErr: ErrNothingToClaim.Error(),
+		},
+		{
+			name:        "claim read only",
+			caller:      revealers[2],
+			btime:       big.NewInt(17),
+			input:       func() []byte { return ClaimSignature },
+			suppliedGas: ClaimGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		{
+			name:        "claim",
+			caller:      revealers[2],
+			btime:       big.NewInt(17),
+			input:       func() []byte { return ClaimSignature },
+			suppliedGas: ClaimGasCost,
+			expectedRes: HBigBytes(big.NewInt(100)),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(revealers[2]).Int64(), int64(1100))
+			},
+		},
+		claimable("claimable after claim", revealers[2], 0),
+		{
+			name:        "claim twice",
+			caller:      revealers[2],
+			btime:       big.NewInt(17),
+			input:       func() []byte { return ClaimSignature },
+			suppliedGas: ClaimGasCost,
+			expectedErr: ErrNothingToClaim.Error(),
+		},
+	}...)
+	runRandomPartyTests(t, s, sponsor, tests)
+}
+
+func TestRandomPartyStakeWeighted(t *testing.T) {
+	sponsor := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	revealers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	for _, tt := range []struct {
+		name            string
+		stakeWeighted   bool
+		stakes          []int64
+		expectedRewards []int64
+	}{
+		{
+			name:            "unweighted equal stakes",
+			stakes:          []int64{1000, 1000},
+			expectedRewards: []int64{150, 150},
+		},
+		{
+			name:            "unweighted unequal stakes",
+			stakes:          []int64{1000, 2000},
+			expectedRewards: []int64{150, 150},
+		},
+		{
+			name:            "weighted equal stakes",
+			stakeWeighted:   true,
+			stakes:          []int64{1000, 1000},
+			expectedRewards: []int64{150, 150},
+		},
+		{
+			name:            "weighted unequal stakes",
+			stakeWeighted:   true,
+			stakes:          []int64{1000, 2000},
+			expectedRewards: []int64{100, 200},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRandomPartyState()
+			SetStakeWeighted(s, tt.stakeWeighted)
+			s.AddBalance(sponsor, big.NewInt(300))
+
+			tests := []randomPartyTest{
+				{
+					name:        "start",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: StartGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "sponsor",
+					btime:       big.NewInt(11),
+					value:       big.NewInt(300),
+					input:       func() []byte { return SponsorSignature },
+					suppliedGas: SponsorGasCost,
+					expectedRes: []byte{},
+				},
+			}
+			for i, revealer := range revealers {
+				preimage, stake := common.Hash{byte(i + 1)}, big.NewInt(tt.stakes[i])
+				s.AddBalance(revealer, stake)
+				tests = append(tests, randomPartyTest{
+					name:        fmt.Sprintf("commit %d", i),
+					caller:      revealer,
+					btime:       big.NewInt(11),
+					value:       stake,
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(big.NewInt(int64(i))),
+				})
+			}
+			// Without [StakeWeighted], only [CommitStake] is locked (and
+			// returned on reveal)
+			lockedStakes := []int64{1000, 1000}
+			if tt.stakeWeighted {
+				lockedStakes = tt.stakes
+			}
+			tests = append(tests, randomPartyTest{
+				name:        "locked stake",
+				btime:       big.NewInt(12),
+				input:       func() []byte { return PackLockedStake(revealers[1]) },
+				suppliedGas: LockedStakeGasCost + 2*LockedStakeItemCost,
+				expectedRes: HBigBytes(big.NewInt(lockedStakes[1])),
+			})
+			for i, revealer := range revealers {
+				idx, preimage := big.NewInt(int64(i)), common.Hash{byte(i + 1)}
+				tests = append(tests, randomPartyTest{
+					name:        fmt.Sprintf("reveal %d", i),
+					caller:      revealer,
+					btime:       big.NewInt(14),
+					input:       func() []byte { return PackReveal(idx, preimage) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+				})
+			}
+			tests = append(tests, randomPartyTest{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + 2*ComputeItemCost + 2*ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					for i, revealer := range revealers {
+						assert.Equal(t, state.GetBalance(revealer).Int64(), int64(lockedStakes[i]+tt.expectedRewards[i]))
+					}
+				},
+			})
+			runRandomPartyTests(t, s, sponsor, tests)
+		})
+	}
+}
+
+func TestRandomPartyVersion(t *testing.T) {
+	s := newRandomPartyState()
+	runRandomPartyTests(t, s, common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a"), []randomPartyTest{
+		{
+			name:        "version",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return VersionSignature },
+			suppliedGas: VersionGasCost,
+			expectedRes: HBigBytes(big.NewInt(RandomPartyVersion)),
+		},
+		{
+			name:        "version read only",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return VersionSignature },
+			suppliedGas: VersionGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(RandomPartyVersion)),
+		},
+		{
+			name:        "invalid input",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return append(VersionSignature[:4:4], 0x1) },
+			suppliedGas: VersionGasCost,
+			expectedErr: "invalid input length for version",
+		},
+		{
+			name:        "insufficient gas",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return VersionSignature },
+			suppliedGas: VersionGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	})
+}
+
+func TestRandomPartyNoReveals(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	start := randomPartyTest{
+		name:        "start",
+		btime:       big.NewInt(10),
+		input:       func() []byte { return StartSignature },
+		suppliedGas: StartGasCost,
+		expectedRes: []byte{},
+	}
+	commit := randomPartyTest{
+		name:        "commit",
+		btime:       big.NewInt(11),
+		value:       big.NewInt(1000),
+		input:       func() []byte { return PackCommit(crypto.Keccak256Hash(common.Hash{0x1}.Bytes())) },
+		suppliedGas: CommitGasCost,
+		expectedRes: HBigBytes(common.Big0),
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		s := newRandomPartyState()
+		s.AddBalance(committer, big.NewInt(1000))
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			start,
+			commit,
+			{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost,
+				expectedErr: ErrNoReveals.Error(),
+			},
+			{
+				name:        "start before reveal deadline",
+				btime:       big.NewInt(15),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedErr: ErrRandomPartyUnderway.Error(),
+			},
+			{
+				// The round can never be computed, so it is replaced (and the
+				// unrevealed stake is forfeited)
+				name:        "start after reveal deadline",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost + DeleteGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					assert.Equal(t, state.GetBalance(committer).Sign(), 0)
+				},
+			},
+			{
+				name:        "next",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return NextSignature },
+				suppliedGas: NextCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+		})
+	})
+
+	t.Run("block hash", func(t *testing.T) {
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+		s.AddBalance(committer, big.NewInt(1000))
+		runRandomPartyTests(t, s, committer, []randomPartyTest{
+			start,
+			commit,
+			{
+				name:        "compute",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "result",
+				btime:       big.NewInt(17),
+				input:       func() []byte { return PackResult(common.Big0) },
+				suppliedGas: ResultCost,
+				expectedRes: expectedBlockHashResult(16),
+			},
+			{
+				name:        "start next",
+				btime:       big.NewInt(17),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost + DeleteGasCost,
+				expectedRes: []byte{},
+			},
+		})
+	})
+
+	t.Run("block hash keeps pool", func(t *testing.T) {
+		sponsor := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		revealer := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+		preimage := common.Hash{0x1}
+		s := newRandomPartyState()
+		SetNoRevealsBehavior(s, NoRevealsBlockHash)
+		s.AddBalance(sponsor, big.NewInt(500))
+		s.AddBalance(revealer, big.NewInt(1000))
+
+		runRandomPartyTests(t, s, sponsor, []randomPartyTest{
+			start,
+			{
+				name:        "sponsor",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(500),
+				input:       func() []byte { return SponsorSignature },
+				suppliedGas: SponsorGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				// No one reveals, so the result is taken from the block hash
+				name:        "compute without reveals",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(500))
+				},
+			},
+			{
+				name:        "start next",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				// The pool rolled over to the next round
+				name:        "pool rolled over",
+				btime:       big.NewInt(16),
+				input:       func() []byte { return RewardSignature },
+				suppliedGas: RewardGasCost,
+				readOnly:    true,
+				expectedRes: HBigBytes(big.NewInt(500)),
+			},
+			{
+				name:        "commit",
+				caller:      revealer,
+				btime:       big.NewInt(17),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big0),
+			},
+			{
+				name:        "reveal",
+				caller:      revealer,
+				btime:       big.NewInt(20),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "compute with reveal",
+				btime:       big.NewInt(22),
+				input:       func() []byte { return ComputeSignature },
+				suppliedGas: ComputeGasCost + ComputeItemCost + ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+				expectedRes: []byte{},
+				assertState: func(t *testing.T, state *countingStateDB) {
+					// The revealer gets their stake back and the rolled over pool
+					assert.Equal(t, state.GetBalance(revealer).Int64(), int64(1500))
+					assert.Equal(t, state.GetBalance(RandomPartyAddress).Sign(), 0)
+				},
+			},
+		})
+	})
+}
+
+func TestRandomPartyReplacedRoundCleanup(t *testing.T) {
+	committers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	s := newRandomPartyState()
+	SetMaxCommitsPerAddress(s, 1)
+	for _, committer := range committers {
+		s.AddBalance(committer, big.NewInt(2000))
+	}
+
+	commit := func(name string, caller common.Address, btime int64, preimage common.Hash, idx int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			caller:      caller,
+			btime:       big.NewInt(btime),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(idx)),
+		}
+	}
+	participants := func(name string, btime int64, expected ...common.Address) randomPartyTest {
+		res := append(HBigBytes(big.NewInt(common.HashLength)), HBigBytes(big.NewInt(int64(len(expected))))...)
+		for _, addr := range expected {
+			res = append(res, addr.Hash().Bytes()...)
+		}
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackParticipants(common.Big0, big.NewInt(10)) },
+			suppliedGas: ParticipantsGasCost + uint64(len(expected))*ParticipantsItemCost,
+			expectedRes: res,
+		}
+	}
+	runRandomPartyTests(t, s, committers[0], []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		commit("commit first", committers[0], 11, common.Hash{0x1}, 0),
+		commit("commit second", committers[1], 11, common.Hash{0x2}, 1),
+		{
+			// No one revealed, so the round is replaced by a new Random Party
+			// that stores its entries under the same round
+			name:        "replace round",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + 2*DeleteGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				for _, list := range []RandomPartyList{RandomPartyCommitments, RandomPartyCommitOwners} {
+					lengthKey, err := RandomPartyLengthKey(list, common.Big0)
+					assert.NilError(t, err)
+					assert.Equal(t, state.GetState(RandomPartyAddress, lengthKey), common.Hash{})
+					for i := int64(0); i < 2; i++ {
+						key, err := RandomPartyStorageKey(list, common.Big0, big.NewInt(i))
+						assert.NilError(t, err)
+						assert.Equal(t, state.GetState(RandomPartyAddress, key), common.Hash{})
+					}
+				}
+			},
+		},
+		participants("no stale participants", 16),
+		{
+			name:        "no stale locked stake",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return CommitFeeCollectedSignature },
+			suppliedGas: CommitFeeCollectedGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		// The commitment counts of the replaced round are cleared, so the
+		// committers can commit again
+		commit("commit again second", committers[1], 17, common.Hash{0x3}, 0),
+		commit("commit again first", committers[0], 17, common.Hash{0x4}, 1),
+		participants("new participants", 17, committers[1], committers[0]),
+		{
+			// The stale preimage at index 0 cannot be revealed
+			name:        "reveal stale preimage",
+			btime:       big.NewInt(20),
+			input:       func() []byte { return PackReveal(common.Big0, common.Hash{0x1}) },
+			suppliedGas: RevealGasCost,
+			expectedErr: "expected",
+		},
+		{
+			name:        "reveal new preimage",
+			btime:       big.NewInt(20),
+			caller:      committers[1],
+			input:       func() []byte { return PackReveal(common.Big0, common.Hash{0x3}) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+	})
+}
+
+func TestRandomPartyDisableBlockTimestamp(t *testing.T) {
+	participant := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	preimage := common.Hash{0x1}
+	s := newRandomPartyState()
+	SetDisableBlockTimestamp(s, big.NewInt(12))
+	s.AddBalance(participant, big.NewInt(2000))
+
+	commit := func(name string, btime int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		}
+	}
+	disabledCommit := commit("commit after disable", 12)
+	disabledCommit.expectedErr = ErrPrecompileDisabled.Error()
+
+	runRandomPartyTests(t, s, participant, []randomPartyTest{
+		{
+			name:        "start before disable",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		commit("commit before disable", 11),
+		disabledCommit,
+		{
+			name:        "sponsor after disable",
+			btime:       big.NewInt(12),
+			value:       big.NewInt(100),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedErr: ErrPrecompileDisabled.Error(),
+		},
+		{
+			// Locked stakes can still be recovered
+			name:        "reveal after disable",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimage) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(participant).Int64(), int64(2000))
+			},
+		},
+		{
+			name:        "compute after disable",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "result after disable",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return PackResult(common.Big0) },
+			suppliedGas: ResultCost,
+			expectedRes: crypto.Keccak256(preimage.Bytes()),
+		},
+		{
+			name:        "start after disable",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + DeleteGasCost,
+			expectedErr: ErrPrecompileDisabled.Error(),
+		},
+	})
+}
+
+func TestRandomPartyUnexpectedValue(t *testing.T) {
+	participant := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	preimage := common.Hash{0x1}
+	s := newRandomPartyState()
+	s.AddBalance(participant, big.NewInt(2000))
+
+	withValue := func(name string, btime int64, input func() []byte, suppliedGas uint64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			value:       common.Big1,
+			input:       input,
+			suppliedGas: suppliedGas,
+			expectedErr: ErrUnexpectedValue.Error(),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(participant).Int64(), int64(1000))
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(1000))
+			},
+		}
+	}
+
+	runRandomPartyTests(t, s, participant, []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "commit",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		withValue("reward with value", 12, func() []byte { return RewardSignature }, RewardGasCost),
+		withValue("next with value", 12, func() []byte { return NextSignature }, NextCost),
+		withValue("reveal with value", 14, func() []byte { return PackReveal(common.Big0, preimage) }, RevealGasCost),
+		{
+			name:        "reveal",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimage) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "compute with value",
+			btime:       big.NewInt(16),
+			value:       common.Big1,
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+			expectedErr: ErrUnexpectedValue.Error(),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(participant).Int64(), int64(2000))
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Sign(), 0)
+			},
+		},
+		{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "result with value",
+			btime:       big.NewInt(16),
+			value:       common.Big1,
+			input:       func() []byte { return PackResult(common.Big0) },
+			suppliedGas: ResultCost,
+			expectedErr: ErrUnexpectedValue.Error(),
+		},
+		{
+			name:        "start with value",
+			btime:       big.NewInt(16),
+			value:       common.Big1,
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + DeleteGasCost,
+			expectedErr: ErrUnexpectedValue.Error(),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(participant).Int64(), int64(2000))
+			},
+		},
+	})
+}
+
+func TestRandomPartyParticipants(t *testing.T) {
+	committers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x2Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	s := newRandomPartyState()
+	SetNoRevealsBehavior(s, NoRevealsBlockHash)
+
+	participants := func(name string, btime int64, offset int64, limit int64, expected ...common.Address) randomPartyTest {
+		res := append(HBigBytes(big.NewInt(common.HashLength)), HBigBytes(big.NewInt(int64(len(expected))))...)
+		for _, addr := range expected {
+			res = append(res, addr.Hash().Bytes()...)
+		}
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackParticipants(big.NewInt(offset), big.NewInt(limit)) },
+			suppliedGas: ParticipantsGasCost + uint64(len(expected))*ParticipantsItemCost,
+			expectedRes: res,
+		}
+	}
+
+	tests := []randomPartyTest{
+		participants("no party", 5, 0, 10),
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		participants("no commitments", 10, 0, 10),
+	}
+	// The first committer commits twice but is only listed once
+	for i, committer := range append(committers, committers[0]) {
+		s.AddBalance(committer, big.NewInt(1000))
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("commit %d", i),
+			caller:      committer,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(common.Hash{byte(i + 1)}) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+		})
+	}
+	tests = append(tests, []randomPartyTest{
+		participants("all", 12, 0, 10, committers...),
+		participants("exact", 12, 0, 3, committers...),
+		participants("first page", 12, 0, 2, committers[0], committers[1]),
+		participants("second page", 12, 2, 2, committers[2]),
+		participants("middle", 12, 1, 1, committers[1]),
+		participants("zero limit", 12, 1, 0),
+		participants("past end", 12, 3, 2),
+		{
+			name:        "insufficient gas",
+			btime:       big.NewInt(12),
+			input:       func() []byte { return PackParticipants(common.Big0, big.NewInt(10)) },
+			suppliedGas: ParticipantsGasCost + 2*ParticipantsItemCost,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		{
+			name:        "invalid input",
+			btime:       big.NewInt(12),
+			input:       func() []byte { return append(ParticipantsSignature[:4:4], 0x1) },
+			suppliedGas: ParticipantsGasCost,
+			expectedErr: "invalid input length for participants",
+		},
+		{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			// Each round has its own participants
+			name:        "start next",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + 4*DeleteGasCost,
+			expectedRes: []byte{},
+		},
+		participants("next round", 16, 0, 10),
+	}...)
+	runRandomPartyTests(t, s, committers[0], tests)
+}
+
+func TestRandomPartyPrecompileBalance(t *testing.T) {
+	t.Run("pre-existing balance", func(t *testing.T) {
+		participant := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		preimages := []common.Hash{{0x1}, {0x2}}
+		s := newRandomPartyState()
+		// Funds held by the precompile before the party are never paid out
+		s.AddBalance(RandomPartyAddress, big.NewInt(50))
+		s.AddBalance(participant, big.NewInt(2100))
+
+		precompileBalance := func(expected int64) func(t *testing.T, state *countingStateDB) {
+			return func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(expected))
+			}
+		}
+		tests := []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "sponsor",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(100),
+				input:       func() []byte { return SponsorSignature },
+				suppliedGas: SponsorGasCost,
+				expectedRes: []byte{},
+			},
+		}
+		for i, preimage := range preimages {
+			preimage := preimage
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("commit %d", i),
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(big.NewInt(int64(i))),
+				assertState: precompileBalance(150 + 1000*int64(i+1)),
+			})
+		}
+		for i, preimage := range preimages {
+			idx, preimage := big.NewInt(int64(i)), preimage
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("reveal %d", i),
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(idx, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedRes: []byte{},
+				assertState: precompileBalance(150 + 1000*int64(1-i)),
+			})
+		}
+		tests = append(tests, randomPartyTest{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + 2*ComputeItemCost + 2*ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(50))
+				assert.Equal(t, state.GetBalance(participant).Int64(), int64(2100))
+			},
+		})
+		runRandomPartyTests(t, s, participant, tests)
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		participant := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+		preimage := common.Hash{0x1}
+		s := newRandomPartyState()
+		s.AddBalance(participant, big.NewInt(1000))
+
+		runRandomPartyTests(t, s, participant, []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(10),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: StartGasCost,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "commit",
+				btime:       big.NewInt(11),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(common.Big0),
+				assertState: func(t *testing.T, state *countingStateDB) {
+					// Simulate the precompile losing track of a locked stake
+					state.SubBalance(RandomPartyAddress, common.Big1)
+				},
+			},
+			{
+				name:        "reveal",
+				btime:       big.NewInt(14),
+				input:       func() []byte { return PackReveal(common.Big0, preimage) },
+				suppliedGas: RevealGasCost,
+				expectedErr: ErrInsufficientBalance.Error(),
+			},
+		})
+	})
+}
+
+func TestRandomPartyCommitFeeCollected(t *testing.T) {
+	committer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	preimages := []common.Hash{{0x1}, {0x2}, {0x3}}
+	s := newRandomPartyState()
+	s.AddBalance(committer, big.NewInt(3000))
+
+	commitFeeCollected := func(name string, btime int64, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return CommitFeeCollectedSignature },
+			suppliedGas: CommitFeeCollectedGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+	tests := []randomPartyTest{
+		commitFeeCollected("commit fee collected before start", 5, 0),
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+	}
+	for i, preimage := range preimages {
+		preimage := preimage
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("commit %d", i),
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+		})
+	}
+	tests = append(tests,
+		commitFeeCollected("commit fee collected after commits", 12, 3000),
+		randomPartyTest{
+			name:        "reveal",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big1, preimages[1]) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		commitFeeCollected("commit fee collected after reveal", 14, 2000),
+		randomPartyTest{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		},
+		// Unrevealed stakes stay locked until they are forfeited
+		commitFeeCollected("commit fee collected after compute", 16, 2000),
+		randomPartyTest{
+			name:        "start next",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + 4*DeleteGasCost + ComputeRewardCost,
+			expectedRes: []byte{},
+		},
+		commitFeeCollected("commit fee collected after forfeit", 16, 0),
+	)
+	runRandomPartyTests(t, s, committer, tests)
+}
+
+func TestRandomPartySnapshot(t *testing.T) {
+	participant := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	preimages := []common.Hash{{0x1}, {0x2}}
+	s := newRandomPartyState()
+	s.AddBalance(participant, big.NewInt(2100))
+
+	snapshot := func(name string, btime int64, expected RandomPartySnapshot) randomPartyTest {
+		words := []*big.Int{
+			expected.Commits, expected.Reveals, expected.CommitDeadline, expected.RevealDeadline, expected.Reward, expected.Round,
+		}
+		res := HBigBytes(new(big.Int).SetUint64(uint64(expected.Phase)))
+		for _, word := range words {
+			res = append(res, HBigBytes(word)...)
+		}
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return SnapshotSignature },
+			suppliedGas: SnapshotGasCost,
+			readOnly:    true,
+			expectedRes: res,
+			assertState: func(t *testing.T, state *countingStateDB) {
+				decoded, err := UnpackSnapshot(res)
+				assert.NilError(t, err)
+				assert.Equal(t, decoded.Phase, expected.Phase)
+				for i, word := range []*big.Int{
+					decoded.Commits, decoded.Reveals, decoded.CommitDeadline, decoded.RevealDeadline, decoded.Reward, decoded.Round,
+				} {
+					assert.Equal(t, words[i].Cmp(word), 0)
+				}
+			},
+		}
+	}
+	commit := func(name string, preimage common.Hash, idx int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(idx)),
+		}
+	}
+	runRandomPartyTests(t, s, participant, []randomPartyTest{
+		snapshot("no party", 5, RandomPartySnapshot{
+			Phase: RandomPartyPhaseNone, Commits: common.Big0, Reveals: common.Big0,
+			CommitDeadline: common.Big0, RevealDeadline: common.Big0, Reward: common.Big0, Round: common.Big0,
+		}),
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "sponsor",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(100),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedRes: []byte{},
+		},
+		commit("commit first", preimages[0], 0),
+		commit("commit second", preimages[1], 1),
+		snapshot("commit phase", 12, RandomPartySnapshot{
+			Phase: RandomPartyPhaseCommit, Commits: big.NewInt(2), Reveals: common.Big0,
+			CommitDeadline: big.NewInt(13), RevealDeadline: big.NewInt(16), Reward: big.NewInt(100), Round: common.Big0,
+		}),
+		{
+			name:        "reveal",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimages[0]) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		snapshot("reveal phase", 14, RandomPartySnapshot{
+			Phase: RandomPartyPhaseReveal, Commits: big.NewInt(2), Reveals: common.Big1,
+			CommitDeadline: big.NewInt(13), RevealDeadline: big.NewInt(16), Reward: big.NewInt(100), Round: common.Big0,
+		}),
+		snapshot("computable", 16, RandomPartySnapshot{
+			Phase: RandomPartyPhaseComputable, Commits: big.NewInt(2), Reveals: common.Big1,
+			CommitDeadline: big.NewInt(13), RevealDeadline: big.NewInt(16), Reward: big.NewInt(100), Round: common.Big0,
+		}),
+		{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+		},
+		snapshot("computed", 16, RandomPartySnapshot{
+			Phase: RandomPartyPhaseNone, Commits: big.NewInt(2), Reveals: common.Big1,
+			CommitDeadline: common.Big0, RevealDeadline: common.Big0, Reward: common.Big0, Round: common.Big0,
+		}),
+		{
+			name:        "invalid input",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return append(SnapshotSignature[:4:4], 0x1) },
+			suppliedGas: SnapshotGasCost,
+			expectedErr: "invalid input length for snapshot",
+		},
+	})
+
+	_, err := UnpackSnapshot(make([]byte, common.HashLength))
+	assert.Assert(t, err != nil)
+}
+
+func TestRandomPartyComputeBounty(t *testing.T) {
+	sponsor := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	revealer := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	computer := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+	preimage := common.Hash{0x1}
+	for _, tt := range []struct {
+		name           string
+		computeTime    int64
+		expectedBounty int64
+	}{
+		{
+			name:           "at reveal deadline",
+			computeTime:    16,
+			expectedBounty: 100,
+		},
+		{
+			name:           "after reveal deadline",
+			computeTime:    21,
+			expectedBounty: 50,
+		},
+		{
+			name:           "long after reveal deadline",
+			computeTime:    100,
+			expectedBounty: 0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRandomPartyState()
+			SetComputeBounty(s, big.NewInt(100), big.NewInt(10))
+			s.AddBalance(sponsor, big.NewInt(300))
+			s.AddBalance(revealer, big.NewInt(1000))
+
+			computeGas := ComputeGasCost + ComputeItemCost + ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost
+			if tt.expectedBounty > 0 {
+				computeGas += ComputeRewardCost
+			}
+			runRandomPartyTests(t, s, revealer, []randomPartyTest{
+				{
+					name:        "start",
+					btime:       big.NewInt(10),
+					input:       func() []byte { return StartSignature },
+					suppliedGas: StartGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "sponsor",
+					caller:      sponsor,
+					btime:       big.NewInt(11),
+					value:       big.NewInt(300),
+					input:       func() []byte { return SponsorSignature },
+					suppliedGas: SponsorGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "commit",
+					btime:       big.NewInt(11),
+					value:       big.NewInt(1000),
+					input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+					suppliedGas: CommitGasCost,
+					expectedRes: HBigBytes(common.Big0),
+				},
+				{
+					name:        "reveal",
+					btime:       big.NewInt(14),
+					input:       func() []byte { return PackReveal(common.Big0, preimage) },
+					suppliedGas: RevealGasCost,
+					expectedRes: []byte{},
+				},
+				{
+					name:        "compute",
+					caller:      computer,
+					btime:       big.NewInt(tt.computeTime),
+					input:       func() []byte { return ComputeSignature },
+					suppliedGas: computeGas,
+					expectedRes: []byte{},
+					assertState: func(t *testing.T, state *countingStateDB) {
+						// The rest of the incentive pool is paid to the revealer
+						assert.Equal(t, state.GetBalance(computer).Int64(), int64(tt.expectedBounty))
+						assert.Equal(t, state.GetBalance(revealer).Int64(), int64(1000+300-tt.expectedBounty))
+						assert.Equal(t, state.GetBalance(RandomPartyAddress).Sign(), 0)
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestRandomPartyIsFinalized(t *testing.T) {
+	participant := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	preimage := common.Hash{0x1}
+	s := newRandomPartyState()
+	s.AddBalance(participant, big.NewInt(1000))
+
+	isFinalized := func(name string, btime int64, round int64, expected bool) randomPartyTest {
+		res := HBigBytes(common.Big0)
+		if expected {
+			res = HBigBytes(common.Big1)
+		}
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackIsFinalized(big.NewInt(round)) },
+			suppliedGas: IsFinalizedGasCost,
+			readOnly:    true,
+			expectedRes: res,
+		}
+	}
+	runRandomPartyTests(t, s, participant, []randomPartyTest{
+		isFinalized("no rounds", 5, 0, false),
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "commit",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		{
+			name:        "reveal",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, preimage) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		},
+		isFinalized("current round", 16, 0, false),
+		{
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+		},
+		isFinalized("finalized round", 16, 0, true),
+		isFinalized("next round", 16, 1, false),
+		isFinalized("future round", 16, 100, false),
+		{
+			name:        "invalid input",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return IsFinalizedSignature },
+			suppliedGas: IsFinalizedGasCost,
+			expectedErr: "invalid input length for isFinalized",
+		},
+	})
+}
+
+func TestRandomPartyAbort(t *testing.T) {
+	adminAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	committers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	s := newRandomPartyState()
+	(&RandomPartyConfig{
+		PhaseSeconds:    big.NewInt(3),
+		CommitStake:     big.NewInt(1000),
+		AllowListAdmins: []common.Address{adminAddr},
+	}).Configure(s)
+
+	refundLogGasCost := LogGasCost(3, common.HashLength)
+	abort := randomPartyTest{
+		name:        "abort",
+		btime:       big.NewInt(12),
+		input:       func() []byte { return AbortSignature },
+		suppliedGas: AbortGasCost + 2*(DeleteGasCost+ComputeRewardCost+refundLogGasCost),
+		expectedRes: []byte{},
+		assertState: func(t *testing.T, state *countingStateDB) {
+			logs := state.Logs()
+			assert.Equal(t, len(logs), 2)
+			for i, committer := range committers {
+				// Both committers recover their stakes
+				assert.Equal(t, state.GetBalance(committer).Int64(), int64(1000))
+				assert.Equal(t, logs[i].Address, RandomPartyAddress)
+				assert.DeepEqual(t, logs[i].Topics, []common.Hash{StakeRefundedTopic, {}, committer.Hash()})
+				assert.DeepEqual(t, logs[i].Data, HBigBytes(big.NewInt(1000)))
+			}
+			assert.Equal(t, state.GetBalance(RandomPartyAddress).Sign(), 0)
+		},
+	}
+	tests := []randomPartyTest{
+		{
+			name:        "abort without party",
+			btime:       big.NewInt(5),
+			input:       func() []byte { return AbortSignature },
+			suppliedGas: AbortGasCost,
+			expectedErr: ErrNoRandomPartyStarted.Error(),
+		},
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+	}
+	for i, committer := range committers {
+		s.AddBalance(committer, big.NewInt(1000))
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("commit %d", i),
+			caller:      committer,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(common.Hash{0x1}.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+		})
+	}
+	tests = append(tests,
+		randomPartyTest{
+			name:        "abort from non-admin",
+			caller:      committers[0],
+			btime:       big.NewInt(12),
+			input:       func() []byte { return AbortSignature },
+			suppliedGas: AbortGasCost,
+			expectedErr: ErrCannotAbort.Error(),
+		},
+		randomPartyTest{
+			name:        "abort read only",
+			btime:       big.NewInt(12),
+			input:       func() []byte { return AbortSignature },
+			suppliedGas: AbortGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		abort,
+		randomPartyTest{
+			name:        "reveal after abort",
+			caller:      committers[0],
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, common.Hash{0x1}) },
+			suppliedGas: RevealGasCost,
+			expectedErr: ErrNoRandomPartyStarted.Error(),
+		},
+		randomPartyTest{
+			name:        "compute after abort",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost,
+			expectedErr: ErrNoRandomPartyStarted.Error(),
+		},
+		randomPartyTest{
+			// Nothing is forfeited and the aborted round is reused
+			name:        "start after abort",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + 2*DeleteGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				for _, committer := range committers {
+					assert.Equal(t, state.GetBalance(committer).Int64(), int64(1000))
+				}
+			},
+		},
+		randomPartyTest{
+			name:        "reward after abort",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return RewardSignature },
+			suppliedGas: RewardGasCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+		randomPartyTest{
+			name:        "next after abort",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return NextSignature },
+			suppliedGas: NextCost,
+			expectedRes: HBigBytes(common.Big0),
+		},
+	)
+	runRandomPartyTests(t, s, adminAddr, tests)
+}
+
+func TestRandomPartyRevealAndClaim(t *testing.T) {
+	sponsor := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	revealers := []common.Address{
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+		common.HexToAddress("0x1Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	s := newRandomPartyState()
+	SetMaxPayoutsPerCompute(s, 1)
+	s.AddBalance(sponsor, big.NewInt(400))
+	for _, revealer := range revealers {
+		s.AddBalance(revealer, big.NewInt(1000))
+	}
+
+	// playRound starts a round at [btime] with a pool of 200 that both
+	// revealers commit to, leaving the reveals to [reveals]
+	playRound := func(btime int64, startGas uint64, reveals []randomPartyTest) []randomPartyTest {
+		tests := []randomPartyTest{
+			{
+				name:        "start",
+				btime:       big.NewInt(btime),
+				input:       func() []byte { return StartSignature },
+				suppliedGas: startGas,
+				expectedRes: []byte{},
+			},
+			{
+				name:        "sponsor",
+				btime:       big.NewInt(btime + 1),
+				value:       big.NewInt(200),
+				input:       func() []byte { return SponsorSignature },
+				suppliedGas: SponsorGasCost,
+				expectedRes: []byte{},
+			},
+		}
+		for i, revealer := range revealers {
+			preimage := common.Hash{byte(btime), byte(i + 1)}
+			tests = append(tests, randomPartyTest{
+				name:        fmt.Sprintf("commit %d", i),
+				caller:      revealer,
+				btime:       big.NewInt(btime + 1),
+				value:       big.NewInt(1000),
+				input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+				suppliedGas: CommitGasCost,
+				expectedRes: HBigBytes(big.NewInt(int64(i))),
+			})
+		}
+		tests = append(tests, reveals...)
+		return append(tests, randomPartyTest{
+			// The first reveal is paid and the second is credited
+			name:        "compute",
+			btime:       big.NewInt(btime + 6),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + 2*ComputeItemCost + ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+		})
+	}
+	reveal := func(name string, btime int64, i int, claim bool, claimed int64) randomPartyTest {
+		idx, preimage := big.NewInt(int64(i)), common.Hash{byte(btime - 4), byte(i + 1)}
+		test := randomPartyTest{
+			name:        name,
+			caller:      revealers[i],
+			btime:       big.NewInt(btime),
+			input:       func() []byte { return PackReveal(idx, preimage) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+		}
+		if claim {
+			test.input = func() []byte { return PackRevealAndClaim(idx, preimage) }
+			test.suppliedGas = RevealAndClaimGasCost
+			test.expectedRes = HBigBytes(big.NewInt(claimed))
+		}
+		return test
+	}
+	claimable := func(name string, account common.Address, expected int64) randomPartyTest {
+		return randomPartyTest{
+			name:        name,
+			btime:       big.NewInt(30),
+			input:       func() []byte { return PackClaimable(account) },
+			suppliedGas: ClaimableGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(expected)),
+		}
+	}
+
+	// In the first round, revealer 1 is credited 100
+	tests := playRound(10, StartGasCost, []randomPartyTest{
+		reveal("reveal 0", 14, 0, false, 0),
+		reveal("reveal 1", 14, 1, false, 0),
+	})
+	tests = append(tests, claimable("credited after first round", revealers[1], 100))
+
+	// In the second round, revealer 1 withdraws its credit when it reveals
+	// (and is then paid for the second round by compute), while revealer 0
+	// has nothing to withdraw and is credited by compute
+	tests = append(tests, playRound(20, StartGasCost+DeleteGasCost*4, []randomPartyTest{
+		func() randomPartyTest {
+			test := reveal("reveal and claim read only", 24, 1, true, 0)
+			test.readOnly = true
+			test.expectedRes = nil
+			test.expectedErr = vmerrs.ErrWriteProtection.Error()
+			return test
+		}(),
+		func() randomPartyTest {
+			test := reveal("reveal and claim 1", 24, 1, true, 100)
+			test.assertState = func(t *testing.T, state *countingStateDB) {
+				// The returned stake and the credit of the first round
+				assert.Equal(t, state.GetBalance(revealers[1]).Int64(), int64(1100))
+			}
+			return test
+		}(),
+		reveal("reveal and claim 0", 24, 0, true, 0),
+	})...)
+	tests = append(tests,
+		claimable("credit withdrawn", revealers[1], 0),
+		claimable("credited after second round", revealers[0], 100),
+		randomPartyTest{
+			name:        "claim withdrawn credit",
+			caller:      revealers[1],
+			btime:       big.NewInt(30),
+			input:       func() []byte { return ClaimSignature },
+			suppliedGas: ClaimGasCost,
+			expectedErr: ErrNothingToClaim.Error(),
+		},
+		randomPartyTest{
+			name:        "claim",
+			caller:      revealers[0],
+			btime:       big.NewInt(30),
+			input:       func() []byte { return ClaimSignature },
+			suppliedGas: ClaimGasCost,
+			expectedRes: HBigBytes(big.NewInt(100)),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				// Each revealer is paid its share of both pools exactly once
+				for _, revealer := range revealers {
+					assert.Equal(t, state.GetBalance(revealer).Int64(), int64(1200))
+				}
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Sign(), 0)
+			},
+		},
+	)
+	runRandomPartyTests(t, s, sponsor, tests)
+}
+
+func TestRandomPartyRewardsDisabled(t *testing.T) {
+	caller := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	s := newRandomPartyState()
+	SetComputeBounty(s, big.NewInt(10), common.Big0)
+	SetRevealBonus(s, big.NewInt(100))
+	SetFirstRevealBonus(s, big.NewInt(50))
+	s.AddBalance(caller, big.NewInt(100000))
+
+	// Fund the incentive pool before rewards are disabled
+	runRandomPartyTests(t, s, caller, []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "sponsor",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(100),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedRes: []byte{},
+		},
+	})
+
+	SetRewardsDisabled(s, true)
+	tests := []randomPartyTest{
+		{
+			name:        "sponsor",
+			btime:       big.NewInt(11),
+			value:       big.NewInt(100),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedErr: ErrRewardsDisabled.Error(),
+		},
+	}
+	for i := 0; i < 2; i++ {
+		preimage := common.Hash{byte(i + 1)}
+		tests = append(tests, randomPartyTest{
+			name:        fmt.Sprintf("commit %d", i),
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(crypto.Keccak256Hash(preimage.Bytes())) },
+			suppliedGas: CommitGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+		})
+	}
+	runRandomPartyTests(t, s, caller, append(tests, []randomPartyTest{
+		{
+			// Only the stake is returned (no [FirstRevealBonus])
+			name:        "reveal",
+			btime:       big.NewInt(14),
+			input:       func() []byte { return PackReveal(common.Big0, common.Hash{0x1}) },
+			suppliedGas: RevealGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(caller).Int64(), int64(98900))
+			},
+		},
+		{
+			// No bounty, rewards, or bonuses are charged for or paid
+			name:        "compute",
+			btime:       big.NewInt(16),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + ComputeItemCost + resultComputedLogGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(caller).Int64(), int64(98900))
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Int64(), int64(1100))
+			},
+		},
+		{
+			name:        "start again",
+			btime:       big.NewInt(20),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + DeleteGasCost*3,
+			expectedRes: []byte{},
+		},
+		{
+			// The incentive pool is carried over to the next round along with
+			// the forfeited stake (rather than paying it to the revealer)
+			name:        "reward",
+			btime:       big.NewInt(21),
+			input:       func() []byte { return RewardSignature },
+			suppliedGas: RewardGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(1100)),
+		},
+	}...))
+}
+
+func TestRandomPartyNoRevealMode(t *testing.T) {
+	committers := []common.Address{
+		common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a"),
+		common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123"),
+	}
+	commitments := []common.Hash{{0x1}, {0x2}}
+	s := newRandomPartyState()
+	SetNoRevealMode(s, true)
+	for _, committer := range committers {
+		s.AddBalance(committer, big.NewInt(1000))
+	}
+
+	tests := []randomPartyTest{
+		{
+			name:        "start",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			// There is no "reveal" phase
+			name:        "reveal deadline",
+			btime:       big.NewInt(10),
+			input:       func() []byte { return GetRevealDeadlineRemainingSignature },
+			suppliedGas: DeadlineRemainingGasCost,
+			readOnly:    true,
+			expectedRes: HBigBytes(big.NewInt(3)),
+		},
+		{
+			name:        "sponsor",
+			caller:      committers[0],
+			btime:       big.NewInt(11),
+			value:       big.NewInt(100),
+			input:       func() []byte { return SponsorSignature },
+			suppliedGas: SponsorGasCost,
+			expectedRes: []byte{},
+		},
+		{
+			name:        "commit insufficient gas",
+			caller:      committers[0],
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(commitments[0]) },
+			suppliedGas: CommitGasCost + RevealGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	}
+	for i, committer := range committers {
+		i, committer := i, committer
+		tests = append(tests, randomPartyTest{
+			// The stake is returned as soon as the commitment is made
+			name:        fmt.Sprintf("commit %d", i),
+			caller:      committer,
+			btime:       big.NewInt(11),
+			value:       big.NewInt(1000),
+			input:       func() []byte { return PackCommit(commitments[i]) },
+			suppliedGas: CommitGasCost + RevealGasCost,
+			expectedRes: HBigBytes(big.NewInt(int64(i))),
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(committer).Int64(), int64(900+100*i))
+			},
+		})
+	}
+	expectedResult := crypto.Keccak256(commitments[0].Bytes(), commitments[1].Bytes())
+	runRandomPartyTests(t, s, committers[0], append(tests, []randomPartyTest{
+		{
+			name:        "reveal",
+			caller:      committers[0],
+			btime:       big.NewInt(13),
+			input:       func() []byte { return PackReveal(common.Big0, common.Hash{}) },
+			suppliedGas: RevealGasCost,
+			expectedErr: ErrTooLate.Error(),
+		},
+		{
+			name:        "compute too early",
+			btime:       big.NewInt(12),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost,
+			expectedErr: ErrTooEarly.Error(),
+		},
+		{
+			// The pool is split amongst the committers
+			name:        "compute at commit deadline",
+			btime:       big.NewInt(13),
+			input:       func() []byte { return ComputeSignature },
+			suppliedGas: ComputeGasCost + 2*ComputeItemCost + 2*ComputeRewardCost + resultComputedLogGasCost + rewardPerRevealerLogGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(committers[0]).Int64(), int64(950))
+				assert.Equal(t, state.GetBalance(committers[1]).Int64(), int64(1050))
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Sign(), 0)
+			},
+		},
+		{
+			name:        "result",
+			btime:       big.NewInt(13),
+			input:       func() []byte { return PackResult(common.Big0) },
+			suppliedGas: ResultCost,
+			expectedRes: expectedResult,
+		},
+		{
+			// Nothing was forfeited
+			name:        "start next",
+			btime:       big.NewInt(13),
+			input:       func() []byte { return StartSignature },
+			suppliedGas: StartGasCost + DeleteGasCost*4,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *countingStateDB) {
+				assert.Equal(t, state.GetBalance(RandomPartyAddress).Sign(), 0)
+			},
+		},
+	}...))
+}
@@ -0,0 +1,107 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package testutil provides helpers for setting up precompile state in tests
+// and tools.
+package testutil
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// accessibleState implements [precompile.PrecompileAccessibleState] for a
+// single block.
+type accessibleState struct {
+	state     precompile.StateDB
+	blockTime *big.Int
+}
+
+func (a *accessibleState) GetStateDB() precompile.StateDB { return a.state }
+func (a *accessibleState) BlockTime() *big.Int            { return a.blockTime }
+func (a *accessibleState) BlockNumber() *big.Int          { return common.Big0 }
+func (a *accessibleState) BlockHash(uint64) common.Hash   { return common.Hash{} }
+func (a *accessibleState) CallFromPrecompile(_, _ common.Address, _ []byte, gas uint64) ([]byte, uint64, error) {
+	return nil, gas, nil
+}
+
+// call runs [input] on the Random Party precompile from [caller] at
+// [blockTime] (transferring [value] to the precompile first, like the EVM).
+func call(state precompile.StateDB, caller common.Address, blockTime *big.Int, input []byte, value *big.Int) ([]byte, error) {
+	if value.Sign() > 0 {
+		if state.GetBalance(caller).Cmp(value) < 0 {
+			return nil, fmt.Errorf("%s cannot pay %d", caller, value)
+		}
+		state.SubBalance(caller, value)
+		state.AddBalance(precompile.RandomPartyAddress, value)
+	}
+	ret, _, err := precompile.RandomPartyPrecompile.Run(&accessibleState{state: state, blockTime: blockTime}, caller, precompile.RandomPartyAddress, input, math.MaxUint64, value, false)
+	return ret, err
+}
+
+// RunRandomParty drives a complete Random Party on [state] (start, commit,
+// reveal, and compute) starting at [startTime], with [participant] committing
+// to and revealing each of [preimages], and returns the result of the round.
+//
+// [state] must already be configured (see [precompile.RandomPartyConfig]) and
+// [participant] must be able to pay the [CommitStake] of every preimage (each
+// stake is returned when the preimage is revealed). Any Random Party that is
+// underway at [startTime] causes an error.
+//
+// Note: the party is not run inside of an EVM, so state changes made by a
+// step that fails are not reverted.
+func RunRandomParty(state precompile.StateDB, participant common.Address, startTime *big.Int, preimages []common.Hash) (common.Hash, error) {
+	if _, err := call(state, participant, startTime, precompile.StartSignature, common.Big0); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to start: %w", err)
+	}
+
+	// Derive the phase deadlines and stake from the precompile itself, so the
+	// helper works with any configuration
+	ret, err := call(state, participant, startTime, precompile.TimeRemainingSignature, common.Big0)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to read commit phase: %w", err)
+	}
+	revealTime := new(big.Int).Add(startTime, new(big.Int).SetBytes(ret))
+	ret, err = call(state, participant, revealTime, precompile.TimeRemainingSignature, common.Big0)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to read reveal phase: %w", err)
+	}
+	computeTime := new(big.Int).Add(revealTime, new(big.Int).SetBytes(ret))
+	ret, err = call(state, participant, startTime, precompile.CommitFeeSignature, common.Big0)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to read commit fee: %w", err)
+	}
+	stake := new(big.Int).SetBytes(ret)
+
+	algo := precompile.GetCommitHashAlgo(state)
+	indices := make([]*big.Int, len(preimages))
+	for i, preimage := range preimages {
+		h, err := algo.Hash(preimage.Bytes())
+		if err != nil {
+			return common.Hash{}, err
+		}
+		ret, err := call(state, participant, startTime, precompile.PackCommit(h), stake)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to commit preimage %d: %w", i, err)
+		}
+		indices[i] = new(big.Int).SetBytes(ret)
+	}
+	for i, preimage := range preimages {
+		if _, err := call(state, participant, revealTime, precompile.PackReveal(indices[i], preimage), common.Big0); err != nil {
+			return common.Hash{}, fmt.Errorf("failed to reveal preimage %d: %w", i, err)
+		}
+	}
+
+	if _, err := call(state, participant, computeTime, precompile.ComputeSignature, common.Big0); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to compute: %w", err)
+	}
+	ret, err = call(state, participant, computeTime, precompile.LatestResultSignature, common.Big0)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to read result: %w", err)
+	}
+	return common.BytesToHash(ret), nil
+}
@@ -0,0 +1,45 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package testutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRandomParty(t *testing.T) {
+	participant := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	s, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.NoError(t, err)
+	(&precompile.RandomPartyConfig{
+		PhaseSeconds: big.NewInt(3),
+		CommitStake:  big.NewInt(1000),
+	}).Configure(s)
+	s.AddBalance(participant, big.NewInt(1000))
+
+	// A single preimage
+	preimage := common.Hash{0x1}
+	result, err := RunRandomParty(s, participant, big.NewInt(10), []common.Hash{preimage})
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(preimage.Bytes()), result)
+	assert.Equal(t, big.NewInt(1000), s.GetBalance(participant))
+
+	// Each party starts where the last one left off (a party without
+	// preimages cannot be computed by default)
+	_, err = RunRandomParty(s, participant, big.NewInt(16), nil)
+	assert.ErrorIs(t, err, precompile.ErrNoReveals)
+
+	// The participant must be able to pay each stake (the party without
+	// preimages is replaced)
+	_, err = RunRandomParty(s, participant, big.NewInt(100), []common.Hash{{0x1}, {0x2}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to commit preimage 1")
+}
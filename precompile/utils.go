@@ -4,14 +4,22 @@
 package precompile
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"regexp"
 
 	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
-var functionSignatureRegex = regexp.MustCompile(`[\w]+\(((([\w]+)?)|((([\w]+),)+([\w]+)))\)`)
+// ErrInvalidInputLength is returned when the arguments passed to a stateful
+// precompile method (excluding its selector) are not the length they are
+// encoded with.
+var ErrInvalidInputLength = errors.New("invalid input length")
+
+var functionSignatureRegex = regexp.MustCompile(`[\w]+\(((([\w]+(\[\])*)?)|((([\w]+(\[\])*),)+([\w]+(\[\])*)))\)`)
 
 // CalculateFunctionSelector returns the 4 byte function selector that results from [functionSignature]
 // Ex. the function setBalance(addr address, balance uint256) should be passed in as the string:
@@ -21,7 +29,69 @@ func CalculateFunctionSelector(functionSignature string) []byte {
 		panic(fmt.Errorf("invalid function signature: %q", functionSignature))
 	}
 	hash := crypto.Keccak256([]byte(functionSignature))
-	return hash[:4]
+	// Limit the capacity so appending to a selector always copies it
+	return hash[:4:4]
+}
+
+// CalculateEventTopic returns the topic that identifies the event with [eventSignature]
+// Ex. the event Transfer(address indexed from, address indexed to, uint256 value) should be
+// passed in as the string: "Transfer(address,address,uint256)"
+func CalculateEventTopic(eventSignature string) common.Hash {
+	if !functionSignatureRegex.MatchString(eventSignature) {
+		panic(fmt.Errorf("invalid event signature: %q", eventSignature))
+	}
+	return crypto.Keccak256Hash([]byte(eventSignature))
+}
+
+// LogGasCost returns the gas required to emit a log with [numTopics] topics and [dataLen]
+// bytes of data.
+func LogGasCost(numTopics int, dataLen int) uint64 {
+	return LogGas + LogTopicGas*uint64(numTopics) + LogDataGas*uint64(dataLen)
+}
+
+// addLog deducts the gas required to emit a log with [topics] and [data] from [suppliedGas]
+// and adds the log (emitted by [addr]) to the state of [accessibleState].
+func addLog(accessibleState PrecompileAccessibleState, addr common.Address, topics []common.Hash, data []byte, suppliedGas uint64) (uint64, error) {
+	remainingGas, err := deductGas(suppliedGas, LogGasCost(len(topics), len(data)))
+	if err != nil {
+		return 0, err
+	}
+	emitLog(accessibleState, addr, topics, data)
+	return remainingGas, nil
+}
+
+// emitLog adds the log (emitted by [addr]) with [topics] and [data] to the state of
+// [accessibleState] without charging any gas.
+func emitLog(accessibleState PrecompileAccessibleState, addr common.Address, topics []common.Hash, data []byte) {
+	accessibleState.GetStateDB().AddPrecompileLog(addr, topics, data, accessibleState.BlockNumber().Uint64())
+}
+
+// packAddressArray returns the ABI encoding of [addrs] as the only return
+// value of type address[].
+func packAddressArray(addrs []common.Address) []byte {
+	hashes := make([]common.Hash, len(addrs))
+	for i, addr := range addrs {
+		hashes[i] = addr.Hash()
+	}
+	return packHashArray(hashes)
+}
+
+// packHashArray returns the ABI encoding of [hashes] as the only return value
+// of type bytes32[].
+func packHashArray(hashes []common.Hash) []byte {
+	packed := make([]byte, 0, common.HashLength*(2+len(hashes)))
+	packed = append(packed, common.BigToHash(big.NewInt(common.HashLength)).Bytes()...)
+	packed = append(packed, common.BigToHash(big.NewInt(int64(len(hashes)))).Bytes()...)
+	for _, h := range hashes {
+		packed = append(packed, h.Bytes()...)
+	}
+	return packed
+}
+
+// invalidInputLength returns [ErrInvalidInputLength] annotated with [method]
+// and the [expected] and [actual] length of its arguments.
+func invalidInputLength(method string, expected int, actual int) error {
+	return fmt.Errorf("%w for %s: expected %d, got %d", ErrInvalidInputLength, method, expected, actual)
 }
 
 // deductGas checks if [suppliedGas] is sufficient against [requiredGas] and deducts [requiredGas] from [suppliedGas].
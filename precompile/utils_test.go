@@ -1,8 +1,12 @@
 package precompile
 
 import (
+	"errors"
+	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"gotest.tools/assert"
 )
 
@@ -33,10 +37,22 @@ func TestFunctionSignatureRegex(t *testing.T) {
 			str:  "getBalance(address,address,address,uint256)",
 			pass: true,
 		},
+		{
+			str:  "getBalance(address[])",
+			pass: true,
+		},
+		{
+			str:  "getBalance(uint256[],bytes32[][])",
+			pass: true,
+		},
 		{
 			str:  "getBalance(address,)",
 			pass: false,
 		},
+		{
+			str:  "getBalance([])",
+			pass: false,
+		},
 		{
 			str:  "getBalance(address,address,)",
 			pass: false,
@@ -61,3 +77,81 @@ func TestFunctionSignatureRegex(t *testing.T) {
 		assert.Equal(t, test.pass, functionSignatureRegex.MatchString(test.str), "unexpected result for %q", test.str)
 	}
 }
+
+func TestFunctionSelectorCapacity(t *testing.T) {
+	selector := CalculateFunctionSelector("getBalance(address)")
+	original := append([]byte{}, selector...)
+
+	// Appending to a selector must never write to (or share) its backing array
+	a := append(selector, 0x1)
+	b := append(selector, 0x2)
+	assert.Equal(t, a[len(a)-1], byte(0x1))
+	assert.Equal(t, b[len(b)-1], byte(0x2))
+	assert.DeepEqual(t, selector, original)
+}
+
+func TestInvalidInputLength(t *testing.T) {
+	admin := common.Address{0x1}
+	state := newCountingStateDB()
+	(&RandomPartyConfig{
+		PhaseSeconds:    big.NewInt(3),
+		CommitStake:     big.NewInt(1000),
+		AllowListAdmins: []common.Address{admin},
+	}).Configure(state)
+	(&ContractNativeMinterConfig{AllowListConfig: AllowListConfig{AllowListAdmins: []common.Address{admin}}}).Configure(state)
+	(&ContractDeployerAllowListConfig{AllowListConfig: AllowListConfig{AllowListAdmins: []common.Address{admin}}}).Configure(state)
+	_, _, err := RandomPartyPrecompile.Run(&countingAccessibleState{state: state, blockTime: big.NewInt(10)}, admin, RandomPartyAddress, StartSignature, StartGasCost, common.Big0, false)
+	assert.NilError(t, err)
+
+	// Methods that check the phase of the Random Party before their input are
+	// called in that phase (all others are called during the "commit" phase)
+	btimes := map[string]int64{
+		"reveal":         14,
+		"revealBatch":    14,
+		"revealAndClaim": 14,
+		"compute":        16,
+	}
+	for _, precompile := range []struct {
+		addr       common.Address
+		contract   StatefulPrecompiledContract
+		signatures []string
+	}{
+		{RandomPartyAddress, RandomPartyPrecompile, []string{
+			"start()", "sponsor()", "reward()", "commit(bytes32)", "reveal(uint256,bytes32)", "compute()",
+			"result(uint256)", "next()", "startTime()", "computableAt(uint256)", "commitFee()",
+			"latestResult()", "revealBatch(uint256[],bytes32[])", "lockedStake(address)",
+			"commitSigned(bytes32,uint8,bytes32,bytes32)", "setCommitFee(uint256)", "timeRemaining()",
+			"sponsoredTotal(uint256)", "nextDeadline()", "claim()", "claimable(address)", "wasRevealed(bytes32)",
+			"version()", "participants(uint256,uint256)", "commitFeeCollected()", "snapshot()", "phaseDuration()",
+			"isFinalized(uint256)", "abort()", "commitFeeOf(uint256)", "resultFraction(uint256,uint256)",
+			"getCommitDeadlineRemaining()", "getRevealDeadlineRemaining()", "schemaVersion()",
+			"revealAndClaim(uint256,bytes32)", "canCommit()", "lastForfeitCount()",
+			"rewardPerRevealer(uint256)", "recentResults(uint256)", "sponsorCount()", "isSolvent()",
+			"setPaused(uint256)", "paused()", "participationParams()",
+			"setAdmin(address)", "setEnabled(address)", "setNone(address)", "readAllowList(address)",
+			"enabledAddresses(uint256,uint256)",
+		}},
+		{ContractNativeMinterAddress, ContractNativeMinterPrecompile, []string{
+			"setAdmin(address)", "setEnabled(address)", "setNone(address)", "readAllowList(address)",
+			"enabledAddresses(uint256,uint256)", "transferAdmin(address)", "mintNativeCoin(address,uint256)",
+		}},
+		{ContractDeployerAllowListAddress, ContractDeployerAllowListPrecompile, []string{
+			"setAdmin(address)", "setEnabled(address)", "setNone(address)", "readAllowList(address)",
+			"enabledAddresses(uint256,uint256)", "blockBytecode(bytes32)", "unblockBytecode(bytes32)",
+		}},
+	} {
+		for _, signature := range precompile.signatures {
+			method := signature[:strings.Index(signature, "(")]
+			btime, ok := btimes[method]
+			if !ok {
+				btime = 11
+			}
+			input := append(CalculateFunctionSelector(signature), 0x1)
+			accessibleState := &countingAccessibleState{state: state, blockTime: big.NewInt(btime)}
+			_, _, err := precompile.contract.Run(accessibleState, admin, precompile.addr, input, 1_000_000, common.Big0, false)
+			assert.Assert(t, errors.Is(err, ErrInvalidInputLength), "%s: %v", signature, err)
+			assert.Assert(t, strings.Contains(err.Error(), "invalid input length for "+method+": expected "), "%s: %v", signature, err)
+			assert.Assert(t, strings.HasSuffix(err.Error(), ", got 1"), "%s: %v", signature, err)
+		}
+	}
+}